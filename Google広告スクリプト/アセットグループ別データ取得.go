@@ -0,0 +1,80 @@
+/**
+ * 【アセットグループ別データ取得】
+ * P-MAX キャンペーンのアセットグループごとの実績（表示回数・クリック数・費用・コンバージョン）を、日付・デバイス別に取得し、
+ * シート全体を日付順に並べ替えます。
+ * ★「コンバージョンデータ取得.go」（CV内訳データ）のP-MAXの行と、日付・デバイス・キャンペーンID・グループ名で突き合わせられます。
+ *   そのため、見出しは「グループ名」、デバイス・広告チャネルタイプは既定でEnum値のまま記録します（CV内訳データと同じ表記）。
+ * ★「Google広告用レポート」で、アセットグループ別のCPAを表示するのに使います。
+ * ★「共通/同期処理.go」を同じスクリプトに貼り付けて実行してください。
+ */
+
+// ▼▼【要設定】▼▼ 記録したいスプレッドシートのURLを貼り付けてください
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼設定▼ 記録先のシート名を指定してください
+const SHEET_NAME = 'アセットグループデータ';
+
+// ▼▼【要設定】▼▼ 取得方法を選んでください
+//   'daily' … 未取得の期間を追記（毎日のトリガー実行用）
+//   'range' … START_DATE から END_DATE までを取得
+//   'year'  … TARGET_YEAR の1年分を取得
+//   'all'   … アカウントの配信開始日から取得
+// ※'daily' 以外は1か月ずつ取得し、途中で止まった場合は次回の実行で続きから再開します。
+// ※記録先スプレッドシートの「設定」シートに値がある場合は、そちらが優先されます（共通/README.md 参照）。
+const MODE = 'daily';
+const START_DATE = '2024-01-01'; // 'range' のときの開始日
+const END_DATE = '';             // 'range' のときの終了日（空欄なら取得できる最新日まで）
+const TARGET_YEAR = 2025;        // 'year' のときに取得する年
+
+// ▼設定▼ コンバージョンの計上遅れに備えて、毎回取り直す直近の日数（7 / 14 / 30 など。0 で無効）
+const LOOKBACK_DAYS = 7;
+
+// --- データセット定義 ---
+const ASSET_GROUP_COLUMNS = [
+  { key: 'segments.date', label: '日付', type: 'date' },
+  { key: 'segments.device', label: 'デバイス', type: 'text', enum: 'device' },
+  { key: 'campaign.name', label: 'キャンペーン名', type: 'text' },
+  { key: 'campaign.id', label: 'キャンペーンID', type: 'id' },
+  { key: 'asset_group.name', label: 'グループ名', type: 'text' },
+  { key: 'asset_group.id', label: 'アセットグループID', type: 'id' },
+  { key: 'asset_group.status', label: 'アセットグループステータス', type: 'text', enum: 'status' },
+  { key: 'campaign.advertising_channel_type', label: '広告チャネルタイプ', type: 'text', enum: 'channelType' },
+  { key: 'metrics.impressions', label: '表示回数', type: 'number' },
+  { key: 'metrics.clicks', label: 'クリック数', type: 'number' },
+  { key: 'metrics.cost_micros', label: '費用', type: 'number' },
+  { key: 'metrics.conversions', label: 'コンバージョン数', type: 'number' },
+  { key: 'metrics.conversions_value', label: 'コンバージョン価値', type: 'number', previousLabels: ['コンバージョン値'] }
+];
+const ASSET_GROUP_API_FIELDS = ASSET_GROUP_COLUMNS.map(column => column.key);
+
+const ASSET_GROUP_DATASET = {
+  // 「CV内訳データ」とデバイスで突き合わせるため、区分値は既定でEnum値のまま記録する
+  defaults: { enumOutput: 'code' },
+  columns: ASSET_GROUP_COLUMNS,
+  keyHeaders: ['日付', 'デバイス', 'キャンペーンID', 'アセットグループID'],
+  fetchRows: function (range) {
+    const query =
+      'SELECT ' + ASSET_GROUP_API_FIELDS.join(', ') + ' ' +
+      'FROM asset_group ' +
+      `WHERE segments.date BETWEEN '${range.startDate}' AND '${range.endDate}' ` +
+      "AND campaign.advertising_channel_type = 'PERFORMANCE_MAX' " +
+      'AND metrics.impressions > 0 ' +
+      'ORDER BY segments.date ASC';
+
+    return reportRowsToValues(reportRows(query), ASSET_GROUP_API_FIELDS, {
+      'metrics.cost_micros': microsToYen
+    });
+  }
+};
+
+function main() {
+  runSync(ASSET_GROUP_DATASET, {
+    spreadsheetUrl: SPREADSHEET_URL,
+    sheetName: SHEET_NAME,
+    mode: MODE,
+    startDate: START_DATE,
+    endDate: END_DATE,
+    targetYear: TARGET_YEAR,
+    lookbackDays: LOOKBACK_DAYS
+  });
+}
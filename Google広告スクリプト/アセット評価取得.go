@@ -0,0 +1,171 @@
+/**
+ * 【アセット評価取得】
+ * レスポンシブ検索広告（ad_group_ad_asset_view）と P-MAX（asset_group_asset）のアセットごとに、
+ * Google広告の評価（最良・良・低・学習中）と直近の実績を取得し、「アセット評価」シートを最新の内容に置き換えます。
+ * ★評価は日ごとの値ではなく取得した時点の値のため、日付順に追記せず、実行のたびにシート全体を書き直します。
+ * ★P-MAX のアセットは実績を取得できないため、評価のみを記録します（表示回数などの列は空欄です）。
+ * ★「Google広告用レポート」のHTMLレポートで、評価が「最良」「低」のアセットの一覧に使います。
+ * ★「共通/」フォルダのファイルを同じスクリプトに貼り付けて実行してください。
+ */
+
+// ▼▼【要設定】▼▼ 記録したいスプレッドシートのURLを貼り付けてください
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼設定▼ 記録先のシート名を指定してください
+const SHEET_NAME = 'アセット評価';
+
+// ▼設定▼ 実績を集計する期間（昨日から遡る日数）
+const METRICS_DAYS = 30;
+
+// ▼設定▼ P-MAX キャンペーンのアセットも記録する場合は true
+const INCLUDE_PMAX_ASSETS = true;
+
+// 種類の列に記録する値
+const ASSET_SOURCES = {
+  searchAd: 'レスポンシブ検索広告',
+  pmax: 'P-MAX'
+};
+
+// --- データセット定義（区分値の変換と列数の確認に使う） ---
+const ASSET_DATASET = {
+  columns: [
+    { key: 'recordedDate', label: '記録日', type: 'date' },
+    { key: 'source', label: '種類', type: 'text' },
+    { key: 'campaign.name', label: 'キャンペーン名', type: 'text' },
+    { key: 'group.id', label: '広告グループ・アセットグループID', type: 'id' },
+    { key: 'group.name', label: '広告グループ・アセットグループ名', type: 'text' },
+    { key: 'ad_group_ad.ad.id', label: '広告ID', type: 'id' },
+    { key: 'asset.id', label: 'アセットID', type: 'id' },
+    { key: 'field_type', label: 'アセットの種類', type: 'text', enum: 'assetFieldType' },
+    { key: 'asset.text', label: 'テキスト・アセット名', type: 'text', format: '@' },
+    { key: 'pinned_field', label: '固定表示', type: 'text' },
+    { key: 'performance_label', label: '評価', type: 'text', enum: 'assetPerformance' },
+    { key: 'metrics.impressions', label: '表示回数', type: 'number' },
+    { key: 'metrics.clicks', label: 'クリック数', type: 'number' },
+    { key: 'metrics.cost_micros', label: '費用', type: 'number' },
+    { key: 'metrics.conversions', label: 'コンバージョン数', type: 'number' }
+  ]
+};
+
+function main() {
+  try {
+    registerSchema(ASSET_DATASET);
+    applyEnumOutput(ASSET_DATASET, 'label');
+
+    const spreadsheet = openSpreadsheet(SPREADSHEET_URL);
+    const timezone = AdsApp.currentAccount().getTimeZone();
+    const endDate = addDays(todayString(timezone), -1);
+    const startDate = addDays(endDate, -(METRICS_DAYS - 1));
+    console.log(`実績の集計期間: ${startDate} から ${endDate}`);
+
+    let rows = fetchSearchAdAssetRows(startDate, endDate, todayString(timezone));
+    console.log(`レスポンシブ検索広告のアセット: ${rows.length}件`);
+    if (INCLUDE_PMAX_ASSETS) {
+      const pmaxRows = fetchPmaxAssetRows(todayString(timezone));
+      console.log(`P-MAX のアセット: ${pmaxRows.length}件`);
+      rows = rows.concat(pmaxRows);
+    }
+    rows = translateEnumRows(ASSET_DATASET, rows);
+    reportUnmappedEnums(spreadsheet, SHEET_NAME);
+    assertRowsMatchSchema(ASSET_DATASET, rows);
+
+    const sheet = getOrCreateSheet(spreadsheet, SHEET_NAME);
+    sheet.clearContents();
+    const values = [ASSET_DATASET.headers].concat(rows);
+    sheet.getRange(1, 1, values.length, ASSET_DATASET.headers.length).setValues(values);
+    sheet.getRange(1, 1, 1, ASSET_DATASET.headers.length).setFontWeight('bold');
+    applyColumnFormats(sheet, ASSET_DATASET);
+    console.log(`${rows.length}件のアセットの評価を書き込みました。`);
+  } catch (e) {
+    console.error('スクリプトの実行中にエラーが発生しました: ' + e.toString());
+    console.error('エラー詳細: ' + e.stack);
+  }
+}
+
+/**
+ * レスポンシブ検索広告のアセット（広告見出し・説明文）の評価と、期間内の実績を取得する
+ */
+function fetchSearchAdAssetRows(startDate, endDate, recordedDate) {
+  const query = `
+    SELECT
+      campaign.name,
+      ad_group.id,
+      ad_group.name,
+      ad_group_ad.ad.id,
+      asset.id,
+      asset.text_asset.text,
+      ad_group_ad_asset_view.field_type,
+      ad_group_ad_asset_view.pinned_field,
+      ad_group_ad_asset_view.performance_label,
+      metrics.impressions,
+      metrics.clicks,
+      metrics.cost_micros,
+      metrics.conversions
+    FROM ad_group_ad_asset_view
+    WHERE segments.date BETWEEN '${startDate}' AND '${endDate}'
+      AND ad_group_ad_asset_view.enabled = TRUE
+      AND ad_group_ad.ad.type = 'RESPONSIVE_SEARCH_AD'
+      AND ad_group_ad.status = 'ENABLED'
+      AND campaign.status = 'ENABLED'
+  `;
+  return reportRows(query).map(row => [
+    recordedDate,
+    ASSET_SOURCES.searchAd,
+    row['campaign.name'],
+    row['ad_group.id'],
+    row['ad_group.name'],
+    row['ad_group_ad.ad.id'],
+    row['asset.id'],
+    row['ad_group_ad_asset_view.field_type'],
+    row['asset.text_asset.text'],
+    formatPinnedField(row['ad_group_ad_asset_view.pinned_field']),
+    row['ad_group_ad_asset_view.performance_label'],
+    parseFloat(row['metrics.impressions']) || 0,
+    parseFloat(row['metrics.clicks']) || 0,
+    microsToYen(row['metrics.cost_micros']),
+    parseFloat(row['metrics.conversions']) || 0
+  ]);
+}
+
+/**
+ * P-MAX のアセットグループに含まれるアセットの評価を取得する
+ */
+function fetchPmaxAssetRows(recordedDate) {
+  const query = `
+    SELECT
+      campaign.name,
+      asset_group.id,
+      asset_group.name,
+      asset.id,
+      asset.name,
+      asset.text_asset.text,
+      asset_group_asset.field_type,
+      asset_group_asset.performance_label
+    FROM asset_group_asset
+    WHERE asset_group_asset.status = 'ENABLED'
+      AND asset_group.status = 'ENABLED'
+      AND campaign.status = 'ENABLED'
+  `;
+  return reportRows(query).map(row => [
+    recordedDate,
+    ASSET_SOURCES.pmax,
+    row['campaign.name'],
+    row['asset_group.id'],
+    row['asset_group.name'],
+    '',
+    row['asset.id'],
+    row['asset_group_asset.field_type'],
+    row['asset.text_asset.text'] || row['asset.name'] || '',
+    '',
+    row['asset_group_asset.performance_label'],
+    '', '', '', ''
+  ]);
+}
+
+/**
+ * 固定表示の位置（HEADLINE_1 / DESCRIPTION_2 など）を「1」「2」の形にする（固定していなければ空欄）
+ */
+function formatPinnedField(value) {
+  const position = /_(\d+)$/.exec(value || '');
+  return position ? position[1] : '';
+}
@@ -0,0 +1,119 @@
+/**
+ * 【キーワード別CVアクションレポート版】
+ * キーワード・コンバージョンアクション別データを取得し、シート全体を日付順に並べ替えます。
+ * デバイス・マッチタイプは「共通/列挙値.go」で日本語に変換（ENUM_OUTPUT で変更できます）。
+ * ★コンバージョン数を整数に丸める処理を追加。
+ * ★GAQL（keyword_view）で取得します。移行前のAWQLと数値が変わっていないかは、checkGaqlMigration() で確認できます。
+ * ★「共通/同期処理.go」を同じスクリプトに貼り付けて実行してください。
+ */
+
+// ▼▼【要設定】▼▼ 記録したいスプレッドシートのURLを貼り付けてください
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼設定▼ 記録先のシート名を指定してください
+const SHEET_NAME = 'キーワードCVアクションデータ'; // シート名を変更
+
+// ▼▼【要設定】▼▼ 取得方法を選んでください
+//   'daily' … 未取得の期間を追記（毎日のトリガー実行用）
+//   'range' … START_DATE から END_DATE までを取得
+//   'year'  … TARGET_YEAR の1年分を取得
+//   'all'   … アカウントの配信開始日から取得
+// ※'daily' 以外は1か月ずつ取得し、途中で止まった場合は次回の実行で続きから再開します。
+// ※記録先スプレッドシートの「設定」シートに値がある場合は、そちらが優先されます（共通/README.md 参照）。
+const MODE = 'daily';
+const START_DATE = '2024-01-01'; // 'range' のときの開始日
+const END_DATE = '';             // 'range' のときの終了日（空欄なら取得できる最新日まで）
+const TARGET_YEAR = 2025;        // 'year' のときに取得する年
+
+// ▼設定▼ コンバージョンの計上遅れに備えて、毎回取り直す直近の日数（7 / 14 / 30 など。0 で無効）
+const LOOKBACK_DAYS = 7;
+
+// ▼設定▼ コンバージョンが発生した日で数えたコンバージョン数・価値も記録する場合は true
+// ※「コンバージョン価値」の右隣に2列を追加します。false に戻すときは、先にシートから2列を削除してください（共通/README.md 参照）。
+const INCLUDE_CONVERSION_DATE = false;
+
+// ▼設定▼ 移行チェック（checkGaqlMigration）で旧AWQLと新GAQLの結果を比べる日数（直近の確定日から遡る）
+const MIGRATION_CHECK_DAYS = 7;
+
+// --- データセット定義 ---
+// GAQLへ移行済み（AWQLにはCV発生日の指標がないため）。legacyKey は移行前のAWQLのフィールド名
+const KEYWORD_CV_COLUMNS = [
+  { key: 'segments.date', legacyKey: 'Date', label: '日付', type: 'date' },
+  { key: 'segments.device', legacyKey: 'Device', label: 'デバイス', type: 'text', enum: 'device' },
+  { key: 'campaign.name', legacyKey: 'CampaignName', label: 'キャンペーン名', type: 'text' },
+  { key: 'ad_group.name', legacyKey: 'AdGroupName', label: '広告グループ名', type: 'text' },
+  { key: 'ad_group_criterion.keyword.text', legacyKey: 'Criteria', label: 'キーワード', type: 'text' },
+  { key: 'ad_group_criterion.keyword.match_type', legacyKey: 'KeywordMatchType', label: 'マッチタイプ', type: 'text', enum: 'matchType' },
+  { key: 'segments.conversion_action_name', legacyKey: 'ConversionTypeName', label: 'コンバージョンアクション名', type: 'text' },
+  { key: 'metrics.conversions', legacyKey: 'Conversions', label: 'コンバージョン数', type: 'number' },
+  { key: 'metrics.conversions_value', legacyKey: 'ConversionValue', label: 'コンバージョン価値', type: 'number' }
+];
+
+// コンバージョン数に小数点がある場合、整数に丸める
+const KEYWORD_CV_TRANSFORMS = {
+  'metrics.conversions': value => typeof value === 'number' ? Math.round(value) : Math.round(parseFloat(value) || 0)
+};
+
+const KEYWORD_CV_DATASET = {
+  columns: KEYWORD_CV_COLUMNS,
+  conversionDateAfter: 'コンバージョン価値', // CV発生日の2列を追加する位置（INCLUDE_CONVERSION_DATE）
+  keyHeaders: ['日付', 'デバイス', 'キャンペーン名', '広告グループ名', 'キーワード', 'マッチタイプ', 'コンバージョンアクション名'],
+  fetchRows: function (range) {
+    // CVが発生したデータのみ取得（CV発生日の指標も記録する場合は hasConversions() で絞り込む）
+    const fields = KEYWORD_CV_COLUMNS.map(column => column.key);
+    const query =
+      'SELECT ' + fields.join(', ') + conversionDateSelectClause(KEYWORD_CV_DATASET) + ' ' +
+      'FROM keyword_view ' +
+      `WHERE segments.date BETWEEN '${range.startDate}' AND '${range.endDate}' ` +
+      conversionFilterClause(KEYWORD_CV_DATASET) + ' ' +
+      'ORDER BY segments.date ASC';
+
+    const rows = reportRows(query).filter(row => hasConversions(KEYWORD_CV_DATASET, row));
+    // CV発生日の列は最後の列（コンバージョン価値）の右隣に並べる
+    return reportRowsToValues(rows, fields, KEYWORD_CV_TRANSFORMS)
+      .map((values, index) => values.concat(conversionDateValues(KEYWORD_CV_DATASET, rows[index])));
+  }
+};
+
+/**
+ * 【移行チェック】旧AWQLと新GAQLで同じ期間を取得し、差分をログに出力する（シートには書き込みません）
+ * 実行する関数に「checkGaqlMigration」を選んで実行してください。
+ */
+function checkGaqlMigration() {
+  try {
+    registerSchema(KEYWORD_CV_DATASET);
+    applyConversionDate(KEYWORD_CV_DATASET, false);
+    applyEnumOutput(KEYWORD_CV_DATASET, 'code');
+    const timezone = AdsApp.currentAccount().getTimeZone();
+    const endDate = addDays(todayString(timezone), -2);
+    const range = buildRange(addDays(endDate, -(MIGRATION_CHECK_DAYS - 1)), endDate, timezone);
+
+    const legacyFields = KEYWORD_CV_COLUMNS.map(column => column.legacyKey);
+    const legacyQuery =
+      'SELECT ' + legacyFields.join(', ') + ' ' +
+      'FROM KEYWORDS_PERFORMANCE_REPORT ' +
+      'WHERE Conversions > 0 ' +
+      'DURING ' + range.during;
+    const legacyRows = translateEnumRows(KEYWORD_CV_DATASET, reportRowsToValues(reportRows(legacyQuery), legacyFields, {
+      Conversions: value => typeof value === 'number' ? Math.round(value) : value
+    }));
+    const newRows = translateEnumRows(KEYWORD_CV_DATASET, KEYWORD_CV_DATASET.fetchRows(range));
+
+    compareMigrationRows(KEYWORD_CV_DATASET, range, legacyRows, newRows);
+  } catch (e) {
+    console.error('スクリプトの実行中にエラーが発生しました: ' + e.message);
+  }
+}
+
+function main() {
+  runSync(KEYWORD_CV_DATASET, {
+    spreadsheetUrl: SPREADSHEET_URL,
+    sheetName: SHEET_NAME,
+    mode: MODE,
+    startDate: START_DATE,
+    endDate: END_DATE,
+    targetYear: TARGET_YEAR,
+    lookbackDays: LOOKBACK_DAYS,
+    includeConversionDate: INCLUDE_CONVERSION_DATE
+  });
+}
@@ -0,0 +1,70 @@
+/**
+ * 【キーワード別レポート】
+ * キーワード別データを取得し、シート全体を日付順に並べ替えます。
+ * デバイス・マッチタイプは「共通/列挙値.go」で日本語に変換（ENUM_OUTPUT で変更できます）。
+ * ★「共通/同期処理.go」を同じスクリプトに貼り付けて実行してください。
+ */
+
+// ▼▼【要設定】▼▼ 記録したいスプレッドシートのURLを貼り付けてください
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼設定▼ 記録先のシート名を指定してください
+const SHEET_NAME = 'キーワードデータ';
+
+// ▼▼【要設定】▼▼ 取得方法を選んでください
+//   'daily' … 未取得の期間を追記（毎日のトリガー実行用）
+//   'range' … START_DATE から END_DATE までを取得
+//   'year'  … TARGET_YEAR の1年分を取得
+//   'all'   … アカウントの配信開始日から取得
+// ※'daily' 以外は1か月ずつ取得し、途中で止まった場合は次回の実行で続きから再開します。
+// ※記録先スプレッドシートの「設定」シートに値がある場合は、そちらが優先されます（共通/README.md 参照）。
+const MODE = 'daily';
+const START_DATE = '2024-01-01'; // 'range' のときの開始日
+const END_DATE = '';             // 'range' のときの終了日（空欄なら取得できる最新日まで）
+const TARGET_YEAR = 2025;        // 'year' のときに取得する年
+
+// ▼設定▼ コンバージョンの計上遅れに備えて、毎回取り直す直近の日数（7 / 14 / 30 など。0 で無効）
+const LOOKBACK_DAYS = 7;
+
+// --- データセット定義 ---
+const KEYWORD_COLUMNS = [
+  { key: 'Date', label: '日付', type: 'date' },
+  { key: 'Device', label: 'デバイス', type: 'text', enum: 'device' },
+  { key: 'CampaignName', label: 'キャンペーン名', type: 'text' },
+  { key: 'AdGroupName', label: '広告グループ名', type: 'text' },
+  { key: 'Criteria', label: 'キーワード', type: 'text' },
+  { key: 'KeywordMatchType', label: 'マッチタイプ', type: 'text', enum: 'matchType' },
+  { key: 'Impressions', label: '表示回数', type: 'number' },
+  { key: 'Clicks', label: 'クリック数', type: 'number' },
+  { key: 'Cost', label: 'ご利用額', type: 'number' },
+  { key: 'Conversions', label: 'コンバージョン数', type: 'number' },
+  { key: 'ConversionValue', label: 'コンバージョン価値', type: 'number' },
+  { key: 'AllConversionValue', label: 'すべてのコンバージョン価値', type: 'number' }
+];
+const KEYWORD_API_FIELDS = KEYWORD_COLUMNS.map(column => column.key);
+
+const KEYWORD_DATASET = {
+  columns: KEYWORD_COLUMNS,
+  keyHeaders: ['日付', 'デバイス', 'キャンペーン名', '広告グループ名', 'キーワード', 'マッチタイプ'],
+  fetchRows: function (range) {
+    const query =
+      'SELECT ' + KEYWORD_API_FIELDS.join(', ') + ' ' +
+      'FROM KEYWORDS_PERFORMANCE_REPORT ' +
+      'DURING ' + range.during + ' ' +
+      'ORDER BY Date ASC';
+
+    return reportRowsToValues(reportRows(query), KEYWORD_API_FIELDS);
+  }
+};
+
+function main() {
+  runSync(KEYWORD_DATASET, {
+    spreadsheetUrl: SPREADSHEET_URL,
+    sheetName: SHEET_NAME,
+    mode: MODE,
+    startDate: START_DATE,
+    endDate: END_DATE,
+    targetYear: TARGET_YEAR,
+    lookbackDays: LOOKBACK_DAYS
+  });
+}
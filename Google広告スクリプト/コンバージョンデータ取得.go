@@ -0,0 +1,165 @@
+/**
+ * 【コンバージョン内訳】
+ * コンバージョン内訳データを取得し、シート全体を日付順に並べ替えます。
+ * ★P-MAXのアセットグループと、通常の広告グループを両方取得します。
+ * ★日次更新では直近 LOOKBACK_DAYS 日分を毎回取り直し、後から計上されたコンバージョンを反映します。
+ * ★デバイス・広告チャネルタイプは、レポート（Google広告用レポート）が「基本データ」と突き合わせるため、既定でEnum値のまま記録します。
+ * ★「コンバージョン数」はクリックした日に計上されるため、直近の日は後から増えていきます。
+ *   INCLUDE_CONVERSION_DATE を true にすると、コンバージョンが発生した日で数えた値も記録し、
+ *   レポートで「今月発生したCV」と「今月のクリックから生まれたCV」を並べて確認できます。
+ * ★「共通/同期処理.go」を同じスクリプトに貼り付けて実行してください。
+ */
+
+// ▼▼【要設定】▼▼ 記録したいスプレッドシートのURLを貼り付けてください
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼設定▼ 記録先のシート名を指定してください
+const SHEET_NAME = 'CV内訳データ';
+
+// ▼▼【要設定】▼▼ 取得方法を選んでください
+//   'daily' … 未取得の期間を追記（毎日のトリガー実行用）
+//   'range' … START_DATE から END_DATE までを取得
+//   'year'  … TARGET_YEAR の1年分を取得
+//   'all'   … アカウントの配信開始日から取得
+// ※'daily' 以外は1か月ずつ取得し、途中で止まった場合は次回の実行で続きから再開します。
+// ※記録先スプレッドシートの「設定」シートに値がある場合は、そちらが優先されます（共通/README.md 参照）。
+const MODE = 'daily';
+const START_DATE = '2024-01-01'; // 'range' のときの開始日
+const END_DATE = '';             // 'range' のときの終了日（空欄なら取得できる最新日まで）
+const TARGET_YEAR = 2025;        // 'year' のときに取得する年
+
+// ▼設定▼ コンバージョンの計上遅れに備えて、毎回取り直す直近の日数（7 / 14 / 30 など。0 で無効）
+const LOOKBACK_DAYS = 7;
+
+// ▼設定▼ コンバージョンが発生した日で数えたコンバージョン数・価値も記録する場合は true
+// ※true にすると「コンバージョン数（CV発生日）」「コンバージョン価値（CV発生日）」の列を追加します（共通/CV発生日.go）。
+//   列を追加したシートで false に戻すと見出し行が列定義と合わなくなるため、その場合は2列を削除してから実行してください。
+const INCLUDE_CONVERSION_DATE = false;
+
+// --- データセット定義 ---
+const CV_DATASET = {
+  // 区分値は ENUM_OUTPUT を指定しない限りEnum値（MOBILE・SEARCH など）で記録する（「基本データ」とデバイスで突き合わせるため）
+  defaults: { enumOutput: 'code' },
+  // 「広告グループ名」を「グループ名」に変更し、アセットグループ名も含むようにします
+  columns: [
+    { key: 'segments.date', label: '日付', type: 'date' },
+    { key: 'segments.device', label: 'デバイス', type: 'text', enum: 'device' },
+    { key: 'campaign.name', label: 'キャンペーン名', type: 'text' },
+    { key: 'campaign.id', label: 'キャンペーンID', type: 'id' },
+    { key: 'group.name', label: 'グループ名', type: 'text', previousLabels: ['広告グループ名'] },
+    { key: 'group.id', label: 'グループID', type: 'id' },
+    { key: 'group.status', label: 'グループステータス', type: 'text', enum: 'status' },
+    { key: 'group.type', label: 'グループタイプ', type: 'text', enum: 'adGroupType' },
+    { key: 'segments.conversion_action_name', label: 'コンバージョンアクション名', type: 'text' },
+    { key: 'metrics.conversions', label: 'コンバージョン数', type: 'number' },
+    { key: 'metrics.conversions_value', label: 'コンバージョン価値', type: 'number' },
+    { key: 'metrics.all_conversions_value', label: 'すべてのコンバージョン価値', type: 'number' },
+    { key: 'campaign.advertising_channel_type', label: '広告チャネルタイプ', type: 'text', enum: 'channelType' }
+  ],
+  // INCLUDE_CONVERSION_DATE が true のときは、この列の右隣にCV発生日の2列を追加する
+  conversionDateAfter: 'すべてのコンバージョン価値',
+  keyHeaders: ['日付', 'デバイス', 'キャンペーンID', 'グループ名', 'グループID', 'コンバージョンアクション名'],
+  fetchRows: function (range) {
+    const dataToWrite = [];
+
+    // --- Query 1: P-MAX以外の広告グループデータを取得 ---
+    const adGroupQuery = `
+      SELECT
+        segments.date,
+        segments.device,
+        campaign.name,
+        campaign.id,
+        ad_group.name,
+        ad_group.id,
+        ad_group.status,
+        ad_group.type,
+        segments.conversion_action_name,
+        metrics.conversions,
+        metrics.conversions_value,
+        metrics.all_conversions_value${conversionDateSelectClause(CV_DATASET)},
+        campaign.advertising_channel_type
+      FROM ad_group
+      WHERE
+        segments.date >= '${range.startDate}' AND segments.date <= '${range.endDate}'
+        ${conversionFilterClause(CV_DATASET)}
+        AND campaign.advertising_channel_type != 'PERFORMANCE_MAX'
+    `;
+    console.log('P-MAX以外のキャンペーンの広告グループデータを取得しています...');
+    reportRows(adGroupQuery).filter(row => hasConversions(CV_DATASET, row)).forEach(row => {
+      dataToWrite.push([
+        row['segments.date'],
+        row['segments.device'],
+        row['campaign.name'],
+        row['campaign.id'],
+        row['ad_group.name'], // 広告グループ名
+        row['ad_group.id'],
+        row['ad_group.status'],
+        row['ad_group.type'],
+        row['segments.conversion_action_name'],
+        row['metrics.conversions'],
+        row['metrics.conversions_value'],
+        row['metrics.all_conversions_value']
+      ].concat(conversionDateValues(CV_DATASET, row), [
+        row['campaign.advertising_channel_type']
+      ]));
+    });
+    console.log(`${dataToWrite.length}件の広告グループデータを処理しました。`);
+
+    // --- Query 2: P-MAXのアセットグループデータを取得 ---
+    const pmaxQuery = `
+      SELECT
+        segments.date,
+        segments.device,
+        campaign.name,
+        campaign.id,
+        asset_group.name,
+        segments.conversion_action_name,
+        metrics.conversions,
+        metrics.conversions_value,
+        metrics.all_conversions_value${conversionDateSelectClause(CV_DATASET)},
+        campaign.advertising_channel_type
+      FROM asset_group
+      WHERE
+        segments.date >= '${range.startDate}' AND segments.date <= '${range.endDate}'
+        ${conversionFilterClause(CV_DATASET)}
+        AND campaign.advertising_channel_type = 'PERFORMANCE_MAX'
+    `;
+    console.log('P-MAXキャンペーンのアセットグループデータを取得しています...');
+    const pmaxDataCount = dataToWrite.length;
+    reportRows(pmaxQuery).filter(row => hasConversions(CV_DATASET, row)).forEach(row => {
+      dataToWrite.push([
+        row['segments.date'],
+        row['segments.device'],
+        row['campaign.name'],
+        row['campaign.id'],
+        row['asset_group.name'], // アセットグループ名
+        '(P-MAX)', // グループID
+        '(P-MAX)', // グループステータス
+        '(P-MAX)', // グループタイプ
+        row['segments.conversion_action_name'],
+        row['metrics.conversions'],
+        row['metrics.conversions_value'],
+        row['metrics.all_conversions_value']
+      ].concat(conversionDateValues(CV_DATASET, row), [
+        row['campaign.advertising_channel_type']
+      ]));
+    });
+    console.log(`${dataToWrite.length - pmaxDataCount}件のアセットグループデータを処理しました。`);
+
+    return dataToWrite;
+  }
+};
+
+function main() {
+  runSync(CV_DATASET, {
+    spreadsheetUrl: SPREADSHEET_URL,
+    sheetName: SHEET_NAME,
+    mode: MODE,
+    startDate: START_DATE,
+    endDate: END_DATE,
+    targetYear: TARGET_YEAR,
+    lookbackDays: LOOKBACK_DAYS, // コンバージョンは確定までに時間がかかるため、直近の日は毎回取り直す
+    includeConversionDate: INCLUDE_CONVERSION_DATE,
+    initialDays: 30   // シートが空の場合は30日前から取得（環境に合わせて調整してください）
+  });
+}
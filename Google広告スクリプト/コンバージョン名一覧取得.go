@@ -1,94 +1,135 @@
-// ▼▼▼▼▼ ユーザー設定項目 ▼▼▼▼▼
-// 1. スプレッドシートのID（URLからコピーします）
-var SPREADSHEET_ID = "ここにスプレッドシートのIDを入力してください";
-// 2. 書き込みたいシート名（例: "シート1"）
-var SHEET_NAME = "ここに書き込みたいシート名を入力してください";
-// ▲▲▲▲▲▲▲▲▲▲▲▲▲▲▲▲▲▲▲▲▲▲
+/**
+ * 【コンバージョンアクション一覧取得】
+ * アカウントのコンバージョンアクションを、設定（種類・カテゴリ・目標の主要アクションかどうか・カウント方法・アトリビューション モデル・値の設定）とあわせて
+ * 「コンバージョンアクション一覧」シートに記録します。
+ * 「役割」の列には、レポートでそのアクションをどう数えるかを記入します（主要 / 中間 / マイクロ）。
+ *   主要     … レポートのCV・CPA・CVRに数える
+ *   中間     … カート追加・フォーム到達など、最終的な成果の手前の行動（CVには数えず、役割別のCVとして表示）
+ *   マイクロ … ページ閲覧・スクロールなどの参考指標（CVには数えず、役割別のCVとして表示）
+ * ★「役割」はシートで書き換えた値を残し、次回以降の実行では設定の列だけを最新の値に更新します。
+ *   新しく見つかったアクションは、名前に「中間」を含むものを「中間」、目標の主要アクションを「主要」、それ以外を「マイクロ」として追加します。
+ * ★削除したアクションも、過去のコンバージョンデータに名前が残るため、行は消さずにステータスを「削除済み」にして残します。
+ * ★「Google広告用レポート」は、このシートがあれば「役割」でCVを集計します（なければ名前に「中間」を含むかどうかで判定します）。
+ * ★「共通/」フォルダのファイルを同じスクリプトに貼り付けて実行してください。
+ */
 
-function main() {
+// ▼▼【要設定】▼▼ 記録したいスプレッドシートのURLを貼り付けてください
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
 
-  // --- 必須設定のチェック ---
-  if (SPREADSHEET_ID === "ここにスプレッドシートのIDを入力してください" || SHEET_NAME === "ここに書き込みたいシート名を入力してください") {
-    Logger.log("エラー: スクリプトの3行目または5行目の SPREADSHEET_ID と SHEET_NAME を設定してください。");
-    return;
-  }
+// ▼設定▼ 記録先のシート名を指定してください（レポートの SHEET_NAME_CONVERSION_ACTIONS と揃えてください）
+const SHEET_NAME = 'コンバージョンアクション一覧';
 
-  // 1. WHERE句を削除し、すべてのコンバージョン アクションを取得
-  var query = `
-    SELECT conversion_action.name, conversion_action.type
-    FROM conversion_action
-    ORDER BY conversion_action.name
-  `;
+// 「役割」の列に記入できる値
+const CONVERSION_ROLES = {
+  primary: '主要',
+  intermediate: '中間',
+  micro: 'マイクロ'
+};
 
-  var iterator = AdsApp.search(query);
+// --- データセット定義 ---
+const CONVERSION_ACTION_DATASET = {
+  columns: [
+    { key: 'conversion_action.id', label: 'コンバージョンアクションID', type: 'id' },
+    { key: 'conversion_action.name', label: 'コンバージョンアクション名', type: 'text' },
+    { key: 'conversion_action.status', label: 'ステータス', type: 'text', enum: 'status' },
+    { key: 'conversion_action.type', label: '種類', type: 'text' },
+    { key: 'conversion_action.category', label: 'カテゴリ', type: 'text', enum: 'conversionCategory' },
+    { key: 'conversion_action.primary_for_goal', label: '目標の主要アクション', type: 'text' },
+    { key: 'conversion_action.counting_type', label: 'カウント方法', type: 'text', enum: 'conversionCountingType' },
+    { key: 'conversion_action.attribution_model_settings.attribution_model', label: 'アトリビューション モデル', type: 'text', enum: 'attributionModel' },
+    { key: 'conversion_action.value_settings.default_value', label: '既定の値', type: 'number' },
+    { key: 'conversion_action.value_settings.always_use_default_value', label: '常に既定の値を使用', type: 'text' },
+    { key: 'role', label: '役割', type: 'text' }
+  ],
+  keyHeaders: ['コンバージョンアクションID']
+};
 
-  Logger.log("--- コンバージョン名一覧 絞り込み処理 ---");
+function main() {
+  try {
+    registerSchema(CONVERSION_ACTION_DATASET);
+    applyEnumOutput(CONVERSION_ACTION_DATASET, 'label');
 
-  if (!iterator.hasNext()) {
-    Logger.log("コンバージョン アクションが見つかりませんでした。");
-    return;
-  }
+    const spreadsheet = openSpreadsheet(SPREADSHEET_URL);
+    const sheet = getOrCreateSheet(spreadsheet, SHEET_NAME);
+    migrateHeaders(sheet, CONVERSION_ACTION_DATASET);
+    const assignedRoles = loadAssignedRoles(sheet);
 
-  // 2. スプレッドシートに書き出すためのデータ配列を準備
-  var sheetData = [
-    ["Conversion Action Name", "Conversion Type"]
-  ];
+    const roleIndex = CONVERSION_ACTION_DATASET.headers.indexOf('役割');
+    const newActions = [];
+    let rows = fetchConversionActionRows().map(values => {
+      const id = String(values[0]);
+      const role = assignedRoles[id] || defaultConversionRole(values[1], values[5]);
+      if (!assignedRoles[id]) {
+        newActions.push(`${values[1]}（${role}）`);
+      }
+      return values.slice(0, roleIndex).concat([role]);
+    });
+    rows = translateEnumRows(CONVERSION_ACTION_DATASET, rows);
+    assertRowsMatchSchema(CONVERSION_ACTION_DATASET, rows);
 
-  while (iterator.hasNext()) {
-    var row = iterator.next();
-    var name = row.conversionAction.name;
-    var type = row.conversionAction.type;
+    upsertRows(sheet, CONVERSION_ACTION_DATASET, rows);
+    applyColumnFormats(sheet, CONVERSION_ACTION_DATASET);
+    reportUnmappedEnums(spreadsheet, SHEET_NAME);
 
-    // 3. ★★★ 修正点 ★★★
-    // タイプが 'GOOGLE_ANALYTICS' で「始まらない」ものだけを絞り込む
-    // ( ! は「ではない」(NOT) の意味)
-    if ( !type.startsWith('GOOGLE_ANALYTICS') ) {
-      // "GOOGLE_ANALYTICS..." で始まらなければ、書き込みデータに追加
-      Logger.log("追加: " + name + " (Type: " + type + ")");
-      sheetData.push([name, type]);
-    } else {
-      // "GOOGLE_ANALYTICS..." で始まる場合はスキップ
-      Logger.log("スキップ (GA): " + name + " (Type: " + type + ")");
+    console.log(`${rows.length}件のコンバージョンアクションを記録しました。`);
+    if (newActions.length > 0) {
+      console.log(`新しいアクションを${newActions.length}件追加しました。「役割」が正しいか確認してください: ${newActions.join(', ')}`);
     }
+  } catch (e) {
+    console.error('スクリプトの実行中にエラーが発生しました: ' + e.toString());
+    console.error('エラー詳細: ' + e.stack);
   }
+}
 
-  // 4. 既存のスプレッドシートに書き込む
-  if (sheetData.length > 1) { // ヘッダー以外にデータがある場合
-    try {
-      var spreadsheet = SpreadsheetApp.openById(SPREADSHEET_ID);
-      var sheet = spreadsheet.getSheetByName(SHEET_NAME);
+/**
+ * すべてのコンバージョンアクションを取得する（GA4から読み込んだアクション・削除済みのアクションを含む）
+ */
+function fetchConversionActionRows() {
+  const fields = CONVERSION_ACTION_DATASET.declaredColumns.map(column => column.key).filter(key => key !== 'role');
+  const query =
+    'SELECT ' + fields.join(', ') + ' ' +
+    'FROM conversion_action ' +
+    'ORDER BY conversion_action.name';
 
-      if (sheet == null) {
-        Logger.log("エラー: 指定されたシート名 '" + SHEET_NAME + "' が見つかりません。");
-        return;
-      }
+  const toYesNo = value => String(value).toUpperCase() === 'TRUE' ? 'はい' : 'いいえ';
+  return reportRowsToValues(reportRows(query), fields, {
+    'conversion_action.primary_for_goal': toYesNo,
+    'conversion_action.value_settings.default_value': value => parseFloat(value) || 0,
+    'conversion_action.value_settings.always_use_default_value': toYesNo
+  });
+}
 
-      sheet.clear();
-      sheet.getRange(1, 1, sheetData.length, sheetData[0].length).setValues(sheetData);
-      sheet.getRange(1, 1, sheetData.length, sheetData[0].length).setBorder(true, true, true, true, true, true);
-      sheet.getRange(1, 1, 1, sheetData[0].length).setFontWeight("bold");
-
-      Logger.log("---------------------------------");
-      Logger.log("スプレッドシート '" + spreadsheet.getName() + "' のシート '" + SHEET_NAME + "' に書き込みました。");
-      Logger.log("URL: " + spreadsheet.getUrl());
-
-    } catch (e) {
-      Logger.log("スプレッドシートへの書き込み中にエラーが発生しました: " + e);
+/**
+ * シートに記入済みの「役割」を、コンバージョンアクションIDごとに読み込む
+ * 記入できない値（空欄・誤字）は、新しいアクションと同じく既定の役割に戻します。
+ */
+function loadAssignedRoles(sheet) {
+  const roles = {};
+  const lastRow = sheet.getLastRow();
+  if (lastRow <= 1) {
+    return roles;
+  }
+  const headers = CONVERSION_ACTION_DATASET.headers;
+  const idIndex = headers.indexOf('コンバージョンアクションID');
+  const roleIndex = headers.indexOf('役割');
+  const validRoles = Object.keys(CONVERSION_ROLES).map(key => CONVERSION_ROLES[key]);
+  sheet.getRange(2, 1, lastRow - 1, headers.length).getValues().forEach(row => {
+    const role = String(row[roleIndex]).trim();
+    if (validRoles.indexOf(role) !== -1) {
+      roles[String(row[idIndex])] = role;
+    } else if (role) {
+      console.warn(`「役割」の「${role}」は記入できない値のため、既定の役割に戻します（コンバージョンアクションID: ${row[idIndex]}）。`);
     }
+  });
+  return roles;
+}
 
-  } else {
-    Logger.log("---------------------------------");
-    Logger.log("スプレッドシートは更新されませんでした（該当するデータがありません）。");
-    // もしデータが0件だった場合、シートをクリアする
-    try {
-      var spreadsheet = SpreadsheetApp.openById(SPREADSHEET_ID);
-      var sheet = spreadsheet.getSheetByName(SHEET_NAME);
-      if (sheet != null) {
-        sheet.clear();
-        sheet.getRange(1, 1, 1, 2).setValues([["(該当データなし)", "(該当データなし)"]]);
-      }
-    } catch (e) {
-      // エラー処理は省略
-    }
+/**
+ * 新しく見つかったアクションの役割を決める（以前のレポートと同じく、名前に「中間」を含むアクションはCVに数えない）
+ */
+function defaultConversionRole(name, primaryForGoal) {
+  if (String(name).indexOf('中間') !== -1) {
+    return CONVERSION_ROLES.intermediate;
   }
-}
\ No newline at end of file
+  return primaryForGoal === 'はい' ? CONVERSION_ROLES.primary : CONVERSION_ROLES.micro;
+}
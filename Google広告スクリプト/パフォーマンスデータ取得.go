@@ -0,0 +1,106 @@
+/**
+ * 【パフォーマンスデータ】
+ * 広告グループレポートを取得し、スプレッドシートに書き込みます。
+ * 日付順に並べ替え、キャンペーンタイプ・デバイスなどの区分値は「共通/列挙値.go」で日本語に変換して出力します。
+ * ★「共通/同期処理.go」を同じスクリプトに貼り付けて実行してください。
+ */
+
+// ▼▼【要設定】▼▼ 記録したいスプレッドシートのURLを貼り付けてください
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼設定▼ 記録先のシート名を指定してください
+const SHEET_NAME = 'パフォーマンスデータ';
+
+// ▼▼【要設定】▼▼ 取得方法を選んでください
+//   'daily' … 未取得の期間を追記（毎日のトリガー実行用）
+//   'range' … START_DATE から END_DATE までを取得
+//   'year'  … TARGET_YEAR の1年分を取得
+//   'all'   … アカウントの配信開始日から取得
+// ※'daily' 以外は1か月ずつ取得し、途中で止まった場合は次回の実行で続きから再開します。
+// ※記録先スプレッドシートの「設定」シートに値がある場合は、そちらが優先されます（共通/README.md 参照）。
+const MODE = 'year';
+const START_DATE = '2024-01-01'; // 'range' のときの開始日
+const END_DATE = '';             // 'range' のときの終了日（空欄なら取得できる最新日まで）
+const TARGET_YEAR = 2025;        // 'year' のときに取得する年
+
+// ▼設定▼ コンバージョンの計上遅れに備えて、毎回取り直す直近の日数（7 / 14 / 30 など。0 で無効）
+const LOOKBACK_DAYS = 7;
+
+const DAY_OF_WEEK_LABELS = ['日', '月', '火', '水', '木', '金', '土'];
+
+// --- データセット定義 ---
+const PERFORMANCE_DATASET = {
+  columns: [
+    { key: 'segments.date', label: '日付', type: 'date' },
+    { key: 'dayOfWeek', label: '曜日', type: 'text' },
+    { key: 'campaign.name', label: 'キャンペーン名', type: 'text' },
+    { key: 'campaign.advertising_channel_type', label: 'キャンペーンタイプ', type: 'text', enum: 'channelType' },
+    { key: 'ad_group.name', label: '広告グループ名', type: 'text' },
+    { key: 'ad_group.type', label: '広告グループの種類', type: 'text', enum: 'adGroupType' },
+    { key: 'segments.device', label: 'デバイス', type: 'text', enum: 'device' },
+    { key: 'metrics.cost_micros', label: '費用', type: 'number' },
+    { key: 'metrics.impressions', label: '表示回数', type: 'number' },
+    { key: 'metrics.clicks', label: 'クリック数', type: 'number' },
+    { key: 'metrics.conversions', label: 'コンバージョン数', type: 'number' }
+  ],
+  keyHeaders: ['日付', 'キャンペーン名', '広告グループ名', 'デバイス'],
+  fetchRows: function (range) {
+    // impressions > 0 のフィルタは付けずに取得する
+    const query = `
+      SELECT
+        segments.date,
+        campaign.name,
+        campaign.advertising_channel_type,
+        ad_group.name,
+        ad_group.type,
+        segments.device,
+        metrics.cost_micros,
+        metrics.impressions,
+        metrics.clicks,
+        metrics.conversions
+      FROM ad_group
+      WHERE segments.date BETWEEN '${range.startDate}' AND '${range.endDate}'`;
+
+    const dataToWrite = [];
+    for (const row of AdsApp.search(query)) {
+      const dateStr = row.segments.date;
+      if (!dateStr) continue;
+
+      // 曜日は日付文字列から求め、実行環境のタイムゾーンに影響されないようにする
+      const dayOfWeek = DAY_OF_WEEK_LABELS[new Date(dateStr + 'T00:00:00Z').getUTCDay()];
+
+      // オブジェクトが存在するかを必ず確認し、安全にデータを取得
+      const campaignName = row.campaign ? row.campaign.name : '（キャンペーン情報なし）';
+      const campaignType = row.campaign ? row.campaign.advertising_channel_type : 'UNKNOWN';
+      const adGroupName = row.ad_group ? row.ad_group.name : '（該当なし）'; // P-MAXなど広告グループがない場合
+      const adGroupType = row.ad_group ? row.ad_group.type : 'UNKNOWN';
+
+      dataToWrite.push([
+        dateStr,
+        dayOfWeek,
+        campaignName,
+        campaignType,
+        adGroupName,
+        adGroupType,
+        row.segments.device,
+        microsToYen(row.metrics.cost_micros), // 費用は「マイクロ円」で返されるため円に変換
+        row.metrics.impressions,
+        row.metrics.clicks,
+        row.metrics.conversions
+      ]);
+    }
+    return dataToWrite;
+  }
+};
+
+function main() {
+  runSync(PERFORMANCE_DATASET, {
+    spreadsheetUrl: SPREADSHEET_URL,
+    sheetName: SHEET_NAME,
+    mode: MODE,
+    startDate: START_DATE,
+    endDate: END_DATE,
+    targetYear: TARGET_YEAR,
+    lookbackDays: LOOKBACK_DAYS
+  });
+}
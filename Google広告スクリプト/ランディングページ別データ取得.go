@@ -0,0 +1,112 @@
+/**
+ * 【ランディングページ別データ取得】
+ * 最終ページURL（ランディングページ）ごとの実績（表示回数・クリック数・費用・コンバージョン）を、日付・デバイス・キャンペーン別に取得し、
+ * シート全体を日付順に並べ替えます。
+ * URLは「共通/URL正規化.go」で計測用のパラメータなどを取り除いてから、同じページの行を1行にまとめて記録します。
+ * ★Yahoo広告の「検索広告取得」の「ランディングページ」列と同じ表記になるため、媒体をまたいでページ別に集計できます。
+ * ★「共通/同期処理.go」を同じスクリプトに貼り付けて実行してください。
+ */
+
+// ▼▼【要設定】▼▼ 記録したいスプレッドシートのURLを貼り付けてください
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼設定▼ 記録先のシート名を指定してください
+const SHEET_NAME = 'ランディングページデータ';
+
+// ▼▼【要設定】▼▼ 取得方法を選んでください
+//   'daily' … 未取得の期間を追記（毎日のトリガー実行用）
+//   'range' … START_DATE から END_DATE までを取得
+//   'year'  … TARGET_YEAR の1年分を取得
+//   'all'   … アカウントの配信開始日から取得
+// ※'daily' 以外は1か月ずつ取得し、途中で止まった場合は次回の実行で続きから再開します。
+// ※記録先スプレッドシートの「設定」シートに値がある場合は、そちらが優先されます（共通/README.md 参照）。
+const MODE = 'daily';
+const START_DATE = '2024-01-01'; // 'range' のときの開始日
+const END_DATE = '';             // 'range' のときの終了日（空欄なら取得できる最新日まで）
+const TARGET_YEAR = 2025;        // 'year' のときに取得する年
+
+// ▼設定▼ コンバージョンの計上遅れに備えて、毎回取り直す直近の日数（7 / 14 / 30 など。0 で無効）
+const LOOKBACK_DAYS = 7;
+
+// ▼設定▼ どのURLで集計するか
+//   true  … 実際に表示されたURL（トラッキングテンプレート・ValueTrack パラメータを展開した後。expanded_landing_page_view）
+//   false … 広告に設定した最終ページURL（展開前。landing_page_view）
+const USE_EXPANDED_URL = true;
+
+// --- データセット定義 ---
+const LANDING_PAGE_DATASET = {
+  columns: [
+    { key: 'segments.date', label: '日付', type: 'date' },
+    { key: 'segments.device', label: 'デバイス', type: 'text', enum: 'device' },
+    { key: 'campaign.id', label: 'キャンペーンID', type: 'id' },
+    { key: 'campaign.name', label: 'キャンペーン名', type: 'text' },
+    { key: 'landingPage', label: 'ランディングページ', type: 'text' },
+    { key: 'metrics.impressions', label: '表示回数', type: 'number' },
+    { key: 'metrics.clicks', label: 'クリック数', type: 'number' },
+    { key: 'metrics.cost_micros', label: '費用', type: 'number' },
+    { key: 'metrics.conversions', label: 'コンバージョン数', type: 'number' },
+    { key: 'metrics.conversions_value', label: 'コンバージョン価値', type: 'number', previousLabels: ['コンバージョン値'] }
+  ],
+  keyHeaders: ['日付', 'デバイス', 'キャンペーンID', 'ランディングページ'],
+  fetchRows: function (range) {
+    const view = USE_EXPANDED_URL ? 'expanded_landing_page_view' : 'landing_page_view';
+    const urlField = USE_EXPANDED_URL ? 'expanded_landing_page_view.expanded_final_url' : 'landing_page_view.unexpanded_final_url';
+    const query = `
+      SELECT
+        segments.date,
+        segments.device,
+        campaign.id,
+        campaign.name,
+        ${urlField},
+        metrics.impressions,
+        metrics.clicks,
+        metrics.cost_micros,
+        metrics.conversions,
+        metrics.conversions_value
+      FROM ${view}
+      WHERE segments.date BETWEEN '${range.startDate}' AND '${range.endDate}'
+        AND metrics.impressions > 0
+      ORDER BY segments.date ASC
+    `;
+    return mergeLandingPageRows(reportRows(query), urlField);
+  }
+};
+
+/**
+ * URLを正規化し、日付・デバイス・キャンペーン・ランディングページが同じ行の実績を合算する
+ * （gclid などのパラメータだけが違うURLは、同じページとして1行にまとめる）
+ */
+function mergeLandingPageRows(rows, urlField) {
+  const merged = {};
+  const order = [];
+  rows.forEach(row => {
+    const landingPage = normalizeLandingPageUrl(row[urlField]);
+    const key = [row['segments.date'], row['segments.device'], row['campaign.id'], landingPage].join('|');
+    if (!merged[key]) {
+      merged[key] = [row['segments.date'], row['segments.device'], row['campaign.id'], row['campaign.name'], landingPage, 0, 0, 0, 0, 0];
+      order.push(key);
+    }
+    const values = merged[key];
+    values[5] += parseFloat(row['metrics.impressions']) || 0;
+    values[6] += parseFloat(row['metrics.clicks']) || 0;
+    values[7] += microsToYen(row['metrics.cost_micros']);
+    values[8] += parseFloat(row['metrics.conversions']) || 0;
+    values[9] += parseFloat(row['metrics.conversions_value']) || 0;
+  });
+  if (rows.length > order.length) {
+    console.log(`URLの正規化で ${rows.length}件 を ${order.length}件 にまとめました。`);
+  }
+  return order.map(key => merged[key]);
+}
+
+function main() {
+  runSync(LANDING_PAGE_DATASET, {
+    spreadsheetUrl: SPREADSHEET_URL,
+    sheetName: SHEET_NAME,
+    mode: MODE,
+    startDate: START_DATE,
+    endDate: END_DATE,
+    targetYear: TARGET_YEAR,
+    lookbackDays: LOOKBACK_DAYS
+  });
+}
@@ -0,0 +1,404 @@
+/**
+ * 【予算ペース監視】
+ * 「予算」シートに記入した月予算（アカウント全体・キャンペーン別）と、「基本データ」シートの当月の費用を比べ、
+ * 曜日ごとの費用の傾向と「祝日データ」シートの休日から月末の費用を予測して、「予算ペース」シートに記録します。
+ * 予測が予算から PACE_TOLERANCE 以上ずれている行（超過ペース・消化不足）と、すでに予算を超えた行は、NOTIFY_EMAIL にメールで知らせます。
+ * ★「基本データ」シートは「基本データ取得」で記録したものを使います（このスクリプトは広告アカウントから費用を取得しません）。
+ *   先に「基本データ取得」を毎日のトリガーで実行し、このスクリプトはその後の時間帯に実行してください。
+ * ★昨日までの費用で判定します（月初の1日に実行した場合は、前月の最終結果を記録します）。
+ * ★同じ日に2回以上実行した場合は、その日の記録を置き換えます。
+ * ★「共通/」フォルダのファイルを同じスクリプトに貼り付けて実行してください。
+ *
+ * 「予算」シートの形式（1行目は見出し・初回の実行で作成します）
+ *   月         … 予算の対象月（2025-07 など。空欄にすると毎月同じ予算として使い、月を指定した行があればそちらを優先）
+ *   アカウント名 … 「基本データ」のアカウント名（空欄ならシート内のすべてのアカウント）
+ *   キャンペーン名 … 空欄ならアカウント全体の予算
+ *   月予算      … 円
+ *   メモ        … 自由記入（読み込みません）
+ */
+
+// ▼▼【要設定】▼▼ 予算・基本データ・結果を記録するスプレッドシートのURLを貼り付けてください
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼設定▼ シート名
+const BUDGET_SHEET_NAME = '予算';
+const BASE_SHEET_NAME = '基本データ';
+const STATUS_SHEET_NAME = '予算ペース';
+
+// ▼設定▼ 休日の一覧（「Google広告用祝日対応」のシート。URLが空欄なら SPREADSHEET_URL と同じスプレッドシートから読み込みます）
+const HOLIDAY_SPREADSHEET_URL = '';
+const HOLIDAY_SHEET_NAME = '祝日データ';
+const HOLIDAY_DATE_COLUMN = 1; // 休日の日付が入力されている列番号 (A列なら1)
+
+// ▼設定▼ 休日に広告を止めている場合は true（休日の費用を0円と見込み、残りの配信日数から除きます）
+//   false にすると、休日は日曜日と同じ費用を見込みます。
+const HOLIDAYS_PAUSED = true;
+
+// ▼設定▼ 曜日ごとの費用の傾向を計算する日数（昨日から遡る。28日なら各曜日4日分）
+const WEEKDAY_WEIGHT_DAYS = 28;
+
+// ▼設定▼ 月末の予測が予算から何割ずれたら知らせるか（0.1 なら予算の90%未満・110%超）
+const PACE_TOLERANCE = 0.1;
+
+// ▼設定▼ 通知先のメールアドレス（カンマ区切りで複数指定可。空欄ならメールを送りません）
+const NOTIFY_EMAIL = '';
+
+// 「予算」シートの見出し
+const BUDGET_HEADERS = ['月', 'アカウント名', 'キャンペーン名', '月予算', 'メモ'];
+
+// アカウント名・キャンペーン名が空欄の予算を、「予算ペース」シートに記録するときの表記
+const ALL_ACCOUNTS_LABEL = '（すべて）';
+const ACCOUNT_TOTAL_LABEL = '（アカウント全体）';
+
+// 「判定」の列に記録する値
+const PACE_STATUS = {
+  exceeded: '予算超過',
+  over: '超過ペース',
+  under: '消化不足',
+  onTrack: '順調'
+};
+
+// --- データセット定義 ---
+const PACING_DATASET = {
+  columns: [
+    { key: 'asOfDate', label: '基準日', type: 'date' },
+    { key: 'month', label: '月', type: 'text', format: '@' },
+    { key: 'account', label: 'アカウント名', type: 'text' },
+    { key: 'campaign', label: 'キャンペーン名', type: 'text' },
+    { key: 'budget', label: '月予算', type: 'number', format: '#,##0' },
+    { key: 'spent', label: '当月の費用', type: 'number', format: '#,##0' },
+    { key: 'spentRate', label: '消化率', type: 'percent' },
+    { key: 'forecast', label: '月末の予測', type: 'number', format: '#,##0' },
+    { key: 'forecastRate', label: '予測の消化率', type: 'percent' },
+    { key: 'remainingDays', label: '残りの日数', type: 'number', format: '0' },
+    { key: 'remainingBusinessDays', label: '残りの配信日数', type: 'number', format: '0' },
+    { key: 'dailyBudgetLeft', label: '1日あたりの残り予算', type: 'number', format: '#,##0' },
+    { key: 'status', label: '判定', type: 'text' }
+  ],
+  keyHeaders: ['基準日', 'アカウント名', 'キャンペーン名']
+};
+
+function main() {
+  try {
+    registerSchema(PACING_DATASET);
+
+    const spreadsheet = openSpreadsheet(SPREADSHEET_URL);
+    const budgetSheet = spreadsheet.getSheetByName(BUDGET_SHEET_NAME);
+    if (!budgetSheet) {
+      createBudgetSheet(spreadsheet);
+      console.log(`「${BUDGET_SHEET_NAME}」シートを作成しました。月予算を入力してから再実行してください。`);
+      return;
+    }
+
+    const yesterday = addDays(todayString(AdsApp.currentAccount().getTimeZone()), -1);
+    const costs = readBaseCosts(spreadsheet, yesterday);
+    if (!costs.latestDate) {
+      throw new Error(`「${BASE_SHEET_NAME}」シートに費用のデータがありません。先に「基本データ取得」を実行してください。`);
+    }
+    let asOfDate = yesterday;
+    if (costs.latestDate < yesterday) {
+      console.warn(`「${BASE_SHEET_NAME}」シートのデータが ${costs.latestDate} までのため、${costs.latestDate} 時点で判定します（「基本データ取得」が実行されているか確認してください）。`);
+      asOfDate = costs.latestDate;
+    }
+    const month = asOfDate.slice(0, 7);
+
+    const budgets = readBudgets(budgetSheet, month);
+    if (budgets.length === 0) {
+      console.log(`${month} の予算がありません。「${BUDGET_SHEET_NAME}」シートに月予算を入力してください。`);
+      return;
+    }
+    const holidays = loadHolidays(month);
+
+    const results = budgets.map(budget => evaluatePacing(budget, costs.rows, asOfDate, holidays));
+    const rows = results.map(result => PACING_DATASET.columns.map(column => result[column.key]));
+    assertRowsMatchSchema(PACING_DATASET, rows);
+
+    const sheet = getOrCreateSheet(spreadsheet, STATUS_SHEET_NAME);
+    migrateHeaders(sheet, PACING_DATASET);
+    upsertRows(sheet, PACING_DATASET, rows, { startDate: asOfDate, endDate: asOfDate });
+    applyColumnFormats(sheet, PACING_DATASET);
+
+    const counts = {};
+    results.forEach(result => {
+      counts[result.status] = (counts[result.status] || 0) + 1;
+    });
+    console.log(`${asOfDate} 時点の予算ペース: ` + Object.keys(counts).map(status => `${status} ${counts[status]}件`).join(' / '));
+
+    const flagged = results.filter(result => result.status !== PACE_STATUS.onTrack);
+    if (flagged.length > 0 && NOTIFY_EMAIL) {
+      sendPacingMail(flagged, asOfDate, spreadsheet.getUrl());
+    }
+  } catch (e) {
+    console.error('スクリプトの実行中にエラーが発生しました: ' + e.toString());
+    console.error('エラー詳細: ' + e.stack);
+  }
+}
+
+// --------------------------------------------------------------------------------
+// シートの読み込み
+// --------------------------------------------------------------------------------
+
+/**
+ * 「予算」シートを作成し、見出しを書き込む
+ */
+function createBudgetSheet(spreadsheet) {
+  const sheet = spreadsheet.insertSheet(BUDGET_SHEET_NAME);
+  sheet.getRange(1, 1, 1, BUDGET_HEADERS.length).setValues([BUDGET_HEADERS]).setFontWeight('bold');
+  sheet.getRange(2, 1, sheet.getMaxRows() - 1, 1).setNumberFormat('@');
+  sheet.setFrozenRows(1);
+}
+
+/**
+ * 「予算」シートから、対象月に使う予算を読み込む
+ * 月を指定した行は、同じアカウント・キャンペーンの月が空欄の行より優先します。
+ * 誤りのある行が1つでもあれば、すべての誤りをまとめてエラーにします。
+ * @returns {Array<Object>} 予算の一覧（account, campaign, budget）
+ */
+function readBudgets(sheet, month) {
+  const values = sheet.getDataRange().getValues();
+  const headers = values[0].map(value => String(value).trim());
+  const missing = BUDGET_HEADERS.filter(header => header !== 'メモ' && headers.indexOf(header) === -1);
+  if (missing.length > 0) {
+    throw new Error(`「${sheet.getName()}」シートに列「${missing.join('、')}」がありません。`);
+  }
+  const cell = (row, header) => row[headers.indexOf(header)];
+  const timezone = sheet.getParent().getSpreadsheetTimeZone();
+
+  const errors = [];
+  const monthly = {};
+  const everyMonth = {};
+  values.slice(1).forEach((row, i) => {
+    const rowNumber = i + 2;
+    if (row.every(value => value === '' || value === null)) {
+      return;
+    }
+    const budgetMonth = toBudgetMonth(cell(row, '月'), timezone);
+    const budget = toNumber(cell(row, '月予算'));
+    if (budgetMonth === null) {
+      errors.push(`${rowNumber}行目: 月「${cell(row, '月')}」は 2025-07 の形式で入力してください。`);
+      return;
+    }
+    if (!(budget > 0)) {
+      errors.push(`${rowNumber}行目: 月予算「${cell(row, '月予算')}」は0より大きい数値で入力してください。`);
+      return;
+    }
+    if (budgetMonth !== '' && budgetMonth !== month) {
+      return;
+    }
+    const entry = { account: String(cell(row, 'アカウント名')).trim(), campaign: String(cell(row, 'キャンペーン名')).trim(), budget: budget };
+    const key = entry.account + '|' + entry.campaign;
+    const target = budgetMonth === '' ? everyMonth : monthly;
+    if (target[key]) {
+      errors.push(`${rowNumber}行目: 月「${budgetMonth || '（空欄）'}」・アカウント名「${entry.account}」・キャンペーン名「${entry.campaign}」の予算が重複しています。`);
+      return;
+    }
+    target[key] = entry;
+  });
+  if (errors.length > 0) {
+    throw new Error(`「${sheet.getName()}」シートに誤りがあるため、処理を中止しました。\n- ` + errors.join('\n- '));
+  }
+
+  const merged = Object.assign({}, everyMonth, monthly);
+  return Object.keys(merged).map(key => merged[key]);
+}
+
+/**
+ * 「月」のセルの値を yyyy-MM 形式に変換する（空欄は ''・解釈できない値は null）
+ */
+function toBudgetMonth(value, timezone) {
+  if (value instanceof Date) {
+    return isNaN(value.getTime()) ? null : Utilities.formatDate(value, timezone, 'yyyy-MM');
+  }
+  const text = String(value).trim();
+  if (!text) {
+    return '';
+  }
+  const match = text.match(/^(\d{4})\D+(\d{1,2})(?:\D+\d{1,2})?\D*$/);
+  if (!match || Number(match[2]) < 1 || Number(match[2]) > 12) {
+    return null;
+  }
+  return `${match[1]}-${('0' + match[2]).slice(-2)}`;
+}
+
+/**
+ * 「基本データ」シートから、日付・アカウント名・キャンペーン名ごとの費用を読み込む
+ * 曜日ごとの傾向と当月の費用の計算に使う期間（前月の初めから）の行だけを残します。
+ * @returns {Object} { rows: Array<{date, account, campaign, cost}>, latestDate: 費用のある最も新しい日付（昨日まで） }
+ */
+function readBaseCosts(spreadsheet, yesterday) {
+  const sheet = spreadsheet.getSheetByName(BASE_SHEET_NAME);
+  if (!sheet || sheet.getLastRow() <= 1) {
+    return { rows: [], latestDate: null };
+  }
+  const values = sheet.getDataRange().getValues();
+  const headers = values[0].map(value => String(value).trim());
+  const missing = ['日付', 'キャンペーン名', 'ご利用額'].filter(header => headers.indexOf(header) === -1);
+  if (missing.length > 0) {
+    throw new Error(`「${BASE_SHEET_NAME}」シートに列「${missing.join('、')}」がありません。`);
+  }
+  const dateIndex = headers.indexOf('日付');
+  const accountIndex = headers.indexOf('アカウント名');
+  const campaignIndex = headers.indexOf('キャンペーン名');
+  const costIndex = headers.indexOf('ご利用額');
+  const timezone = spreadsheet.getSpreadsheetTimeZone();
+  const previousMonthStart = addMonths(yesterday.slice(0, 7) + '-01', -1);
+  const weightStart = addDays(yesterday, -WEEKDAY_WEIGHT_DAYS);
+  const earliestDate = previousMonthStart < weightStart ? previousMonthStart : weightStart;
+
+  const rows = [];
+  let latestDate = null;
+  values.slice(1).forEach(row => {
+    const date = toDateString(row[dateIndex], timezone);
+    if (!date || date < earliestDate || date > yesterday) {
+      return;
+    }
+    const cost = toNumber(row[costIndex]);
+    rows.push({ date: date, account: accountIndex === -1 ? '' : String(row[accountIndex]), campaign: String(row[campaignIndex]), cost: cost });
+    if (cost > 0 && (!latestDate || date > latestDate)) {
+      latestDate = date;
+    }
+  });
+  return { rows: rows, latestDate: latestDate };
+}
+
+/**
+ * 休日の一覧を読み込む（シートがない場合は、休日なしとして続行します）
+ * @returns {Set<string>} yyyy-MM-dd 形式の日付
+ */
+function loadHolidays(month) {
+  const spreadsheet = openSpreadsheet(HOLIDAY_SPREADSHEET_URL || SPREADSHEET_URL);
+  const sheet = spreadsheet.getSheetByName(HOLIDAY_SHEET_NAME);
+  if (!sheet) {
+    console.warn(`「${HOLIDAY_SHEET_NAME}」シートが見つからないため、休日なしとして予測します。`);
+    return new Set();
+  }
+  const lastRow = sheet.getLastRow();
+  if (lastRow < 2) {
+    return new Set();
+  }
+  const timezone = spreadsheet.getSpreadsheetTimeZone();
+  const holidays = sheet.getRange(2, HOLIDAY_DATE_COLUMN, lastRow - 1, 1).getValues()
+    .map(row => toDateString(row[0], timezone))
+    .filter(date => !!date);
+  console.log(`休日を${holidays.length}件読み込みました（${month} の休日: ${holidays.filter(date => date.indexOf(month) === 0).length}日）。`);
+  return new Set(holidays);
+}
+
+// --------------------------------------------------------------------------------
+// 予測と判定
+// --------------------------------------------------------------------------------
+
+/**
+ * 1件の予算について、当月の費用・月末の予測・判定を計算する
+ * 月末の予測 = 当月の費用 + 残りの各日の曜日の平均費用（休日は HOLIDAYS_PAUSED に従う）
+ */
+function evaluatePacing(budget, costRows, asOfDate, holidays) {
+  const daily = {};
+  costRows.forEach(row => {
+    if ((budget.account === '' || row.account === budget.account) && (budget.campaign === '' || row.campaign === budget.campaign)) {
+      daily[row.date] = (daily[row.date] || 0) + row.cost;
+    }
+  });
+
+  const monthStart = asOfDate.slice(0, 7) + '-01';
+  const monthEnd = addDays(addMonths(monthStart, 1), -1);
+  let spent = 0;
+  for (let date = monthStart; date <= asOfDate; date = addDays(date, 1)) {
+    spent += daily[date] || 0;
+  }
+
+  const dailyRate = weekdayCostRates(daily, asOfDate, holidays);
+  let remainingDays = 0;
+  let remainingBusinessDays = 0;
+  let remainingForecast = 0;
+  for (let date = addDays(asOfDate, 1); date <= monthEnd; date = addDays(date, 1)) {
+    remainingDays++;
+    if (holidays.has(date)) {
+      remainingForecast += HOLIDAYS_PAUSED ? 0 : dailyRate(0);
+      continue;
+    }
+    remainingBusinessDays++;
+    remainingForecast += dailyRate(dayOfWeek(date));
+  }
+
+  const forecast = spent + remainingForecast;
+  const forecastRate = forecast / budget.budget;
+  let status = PACE_STATUS.onTrack;
+  if (spent > budget.budget) {
+    status = PACE_STATUS.exceeded;
+  } else if (forecastRate > 1 + PACE_TOLERANCE) {
+    status = PACE_STATUS.over;
+  } else if (forecastRate < 1 - PACE_TOLERANCE) {
+    status = PACE_STATUS.under;
+  }
+
+  return {
+    asOfDate: asOfDate,
+    month: asOfDate.slice(0, 7),
+    account: budget.account || ALL_ACCOUNTS_LABEL,
+    campaign: budget.campaign || ACCOUNT_TOTAL_LABEL,
+    budget: budget.budget,
+    spent: Math.round(spent),
+    spentRate: spent / budget.budget,
+    forecast: Math.round(forecast),
+    forecastRate: forecastRate,
+    remainingDays: remainingDays,
+    remainingBusinessDays: remainingBusinessDays,
+    dailyBudgetLeft: remainingBusinessDays > 0 ? Math.round(Math.max(budget.budget - spent, 0) / remainingBusinessDays) : 0,
+    status: status
+  };
+}
+
+/**
+ * 直近 WEEKDAY_WEIGHT_DAYS 日の費用から、曜日ごとの1日あたりの平均費用を返す関数を作る
+ * 休日は平日と費用の傾向が違うため平均から除き、費用が出始める前の日（新しいキャンペーンなど）も数えません。
+ * 平均を出せない曜日は、全体の1日あたりの平均費用を使います。
+ * @returns {function(number): number} 曜日（0=日曜日）を受け取り、見込みの費用を返す関数
+ */
+function weekdayCostRates(daily, asOfDate, holidays) {
+  const firstCostDate = Object.keys(daily).filter(date => daily[date] > 0).sort()[0];
+  const windowStart = addDays(asOfDate, -(WEEKDAY_WEIGHT_DAYS - 1));
+  const sums = [0, 0, 0, 0, 0, 0, 0];
+  const counts = [0, 0, 0, 0, 0, 0, 0];
+  if (firstCostDate) {
+    for (let date = firstCostDate > windowStart ? firstCostDate : windowStart; date <= asOfDate; date = addDays(date, 1)) {
+      if (holidays.has(date)) {
+        continue;
+      }
+      sums[dayOfWeek(date)] += daily[date] || 0;
+      counts[dayOfWeek(date)]++;
+    }
+  }
+  const totalCount = counts.reduce((total, count) => total + count, 0);
+  const overall = totalCount > 0 ? sums.reduce((total, sum) => total + sum, 0) / totalCount : 0;
+  return weekday => counts[weekday] > 0 ? sums[weekday] / counts[weekday] : overall;
+}
+
+/**
+ * yyyy-MM-dd 形式の日付の曜日を返す（0=日曜日。実行環境のタイムゾーンに左右されないようUTCで計算）
+ */
+function dayOfWeek(dateString) {
+  const parts = dateString.split('-').map(Number);
+  return new Date(Date.UTC(parts[0], parts[1] - 1, parts[2])).getUTCDay();
+}
+
+// --------------------------------------------------------------------------------
+// 通知
+// --------------------------------------------------------------------------------
+
+/**
+ * 予算どおりに進んでいない行を、メールで知らせる
+ */
+function sendPacingMail(flagged, asOfDate, spreadsheetUrl) {
+  const yen = value => '¥' + Math.round(value).toLocaleString();
+  const percent = value => (value * 100).toFixed(0) + '%';
+  const lines = flagged.map(result =>
+    `【${result.status}】${result.account} / ${result.campaign}\n` +
+    `  月予算 ${yen(result.budget)} ・当月の費用 ${yen(result.spent)}（${percent(result.spentRate)}）・月末の予測 ${yen(result.forecast)}（${percent(result.forecastRate)}）\n` +
+    `  残りの配信日数 ${result.remainingBusinessDays}日・1日あたりの残り予算 ${yen(result.dailyBudgetLeft)}`);
+  const subject = `【予算ペース】${asOfDate} 時点で予算どおりに進んでいない予算が${flagged.length}件あります`;
+  const body = `${asOfDate} までの費用で予算を超えた予算と、月末の予測が予算から${percent(PACE_TOLERANCE)}以上ずれている予算です。\n\n` +
+    lines.join('\n\n') + `\n\n詳細: ${spreadsheetUrl}`;
+  MailApp.sendEmail(NOTIFY_EMAIL, subject, body);
+  console.log(`${NOTIFY_EMAIL} に${flagged.length}件の通知を送信しました。`);
+}
@@ -0,0 +1,76 @@
+/**
+ * 【共通ライブラリ・CV発生日】
+ * コンバージョンアクション別のデータに、コンバージョンが発生した日で数えたコンバージョン数・価値の列を追加するための処理です。
+ * 「コンバージョン数」はクリックした日に計上されるため直近の日は後から増えていきますが、
+ * CV発生日の指標は「その日に発生したコンバージョン」なので、月の途中でも当月の実績として確認できます。
+ * 各スクリプトの INCLUDE_CONVERSION_DATE（または「設定」シートの INCLUDE_CONVERSION_DATE）を true にすると、
+ * データセットの conversionDateAfter で指定した列の右隣に2列を追加します。
+ * 取得するクエリには conversionDateSelectClause() と conversionFilterClause() を書き足し、行の値は conversionDateValues() で取り出します。
+ * ★「同期処理.go」「スキーマ.go」「設定.go」と一緒に貼り付けてください。
+ */
+
+// コンバージョンが発生した日で数えた指標（INCLUDE_CONVERSION_DATE が true のときに記録）
+const CONVERSION_DATE_COLUMNS = [
+  { key: 'metrics.conversions_by_conversion_date', label: 'コンバージョン数（CV発生日）', type: 'number' },
+  { key: 'metrics.conversions_value_by_conversion_date', label: 'コンバージョン価値（CV発生日）', type: 'number' }
+];
+
+/**
+ * CV発生日の列を列定義に追加する（false のときは取り除く）
+ * 分割（セグメント.go）の列を追加した後に呼び出してください。何度呼び出しても列は重複しません。
+ * @param {Object} dataset - データセット定義（conversionDateAfter に、列を追加する位置の見出しを宣言していること）
+ * @param {boolean} include - CV発生日の列を記録するかどうか
+ */
+function applyConversionDate(dataset, include) {
+  // 区分値の表記で展開する前の列定義（declaredColumns）から組み立て直す
+  const columns = (dataset.declaredColumns || dataset.columns).filter(column => !CONVERSION_DATE_COLUMNS.some(added => added.key === column.key));
+  dataset.includeConversionDate = !!include;
+  if (include) {
+    const position = columns.findIndex(column => column.label === dataset.conversionDateAfter) + 1;
+    dataset.columns = columns.slice(0, position).concat(CONVERSION_DATE_COLUMNS, columns.slice(position));
+  } else {
+    dataset.columns = columns;
+  }
+  // 区分値の表記（列挙値.go）は、列を追加した列定義から展開し直す
+  delete dataset.declaredColumns;
+  registerSchema(dataset);
+}
+
+/**
+ * クエリの SELECT に書き足せる形（先頭にカンマ付き）で、CV発生日のフィールドを返す（記録しない設定のときは空）
+ */
+function conversionDateSelectClause(dataset) {
+  if (!dataset.includeConversionDate) {
+    return '';
+  }
+  return CONVERSION_DATE_COLUMNS.map(column => `,\n        ${column.key}`).join('');
+}
+
+/**
+ * コンバージョンのある行だけに絞る条件を返す
+ * GAQL の WHERE では OR を使えないため、CV発生日の指標も記録する場合は条件を付けずに取得し、hasConversions() で絞り込みます
+ * （クリックした日のコンバージョンが0でも、その日に発生したコンバージョンがある行を残すため）。
+ */
+function conversionFilterClause(dataset) {
+  return dataset.includeConversionDate ? '' : 'AND metrics.conversions > 0';
+}
+
+/**
+ * クリックした日・コンバージョンが発生した日のどちらかでコンバージョンがある行かどうか
+ */
+function hasConversions(dataset, row) {
+  if ((parseFloat(row['metrics.conversions']) || 0) > 0) {
+    return true;
+  }
+  return !!dataset.includeConversionDate && (parseFloat(row['metrics.conversions_by_conversion_date']) || 0) > 0;
+}
+
+/**
+ * レポートの行から、CV発生日の列に書き込む値を取り出す（記録しない設定のときは空の配列）
+ */
+function conversionDateValues(dataset, row) {
+  if (!dataset.includeConversionDate) {
+    return [];
+  }
+  return CONVERSION_DATE_COLUMNS.map(column => row[column.key]);
+}
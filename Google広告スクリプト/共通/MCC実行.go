@@ -0,0 +1,222 @@
+/**
+ * 【共通ライブラリ・MCC実行】
+ * クライアントセンター（MCC）アカウントでスクリプトを実行したときに、
+ * 「アカウント一覧」シートに登録された各アカウントへデータ取得を振り分けます。
+ * ★通常のアカウントで実行した場合は何もしません（これまでどおり、そのアカウントのデータを取得します）。
+ *
+ * MCCで実行する場合は、データ取得スクリプトの SPREADSHEET_URL に「管理用スプレッドシート」のURLを設定し、
+ * そのスプレッドシートに次の列を持つ「アカウント一覧」シートを用意してください。
+ *   A列: アカウントID（123-456-7890）
+ *   B列: アカウント名（「MCC実行結果」シートに記録する名前。空欄ならGoogle広告のアカウント名）
+ *   C列: データを記録するスプレッドシートのURL
+ *   D列: 取得するデータ（シート名をカンマ区切りで記入。例：「基本データ, 性別データ」。「すべて」で全データ）
+ */
+
+// アカウントの振り分け先を記入するシート名（管理用スプレッドシート内）
+const MCC_ACCOUNT_SHEET_NAME = 'アカウント一覧';
+
+// 各アカウントの実行結果をまとめるシート名（管理用スプレッドシート内・自動作成されます）
+const MCC_SUMMARY_SHEET_NAME = 'MCC実行結果';
+
+const MCC_SUMMARY_HEADERS = ['記録日時', 'アカウントID', 'アカウント名', 'データ', 'ステータス', '件数', '開始日', '終了日', 'メッセージ'];
+
+// executeInParallel() で一度に処理できるアカウント数の上限
+const MCC_MAX_ACCOUNTS = 50;
+
+// 子アカウントとして実行中のときに、親スクリプトから受け取った設定（通常の実行では null）
+let mccChildInput = null;
+
+/**
+ * MCCアカウントで実行された親スクリプトかどうかを判定する
+ */
+function isMccParentExecution() {
+  return mccChildInput === null && typeof AdsManagerApp !== 'undefined';
+}
+
+/**
+ * 子アカウントとして実行中の場合は、記録先をそのアカウント用のスプレッドシートに切り替える
+ * @param {Object} settings - 実行設定（spreadsheetUrl を上書きします）
+ */
+function applyMccAccountSettings(settings) {
+  if (mccChildInput === null) {
+    return;
+  }
+  const customerId = AdsApp.currentAccount().getCustomerId();
+  settings.spreadsheetUrl = mccChildInput.spreadsheetUrls[customerId];
+  console.log(`アカウント ${customerId} のデータを ${settings.spreadsheetUrl} に記録します。`);
+}
+
+/**
+ * アカウント一覧のうち、このデータ（シート名）が有効なアカウントに処理を振り分ける
+ * @param {Object} settings - 実行設定（spreadsheetUrl は管理用スプレッドシート）
+ * @returns {number} 振り分けたアカウント数
+ */
+function runSyncForAllAccounts(settings) {
+  const controlSpreadsheet = openSpreadsheet(settings.spreadsheetUrl);
+  const targets = readMccAccountList(controlSpreadsheet, settings.sheetName);
+  if (targets.length === 0) {
+    console.log(`「${MCC_ACCOUNT_SHEET_NAME}」シートに「${settings.sheetName}」を取得するアカウントがありません。`);
+    return 0;
+  }
+  if (targets.length > MCC_MAX_ACCOUNTS) {
+    console.warn(`一度に処理できるのは${MCC_MAX_ACCOUNTS}アカウントまでです。${MCC_MAX_ACCOUNTS + 1}件目以降は、別のスクリプトに分けて実行してください。`);
+    targets.length = MCC_MAX_ACCOUNTS;
+  }
+
+  const spreadsheetUrls = {};
+  const accountNames = {};
+  targets.forEach(target => {
+    spreadsheetUrls[target.customerId] = target.spreadsheetUrl;
+    accountNames[target.customerId] = target.accountName;
+  });
+  const input = {
+    controlSpreadsheetUrl: settings.spreadsheetUrl,
+    datasetName: settings.sheetName,
+    spreadsheetUrls: spreadsheetUrls,
+    accountNames: accountNames
+  };
+
+  console.log(`${targets.length}件のアカウントで「${settings.sheetName}」の取得を開始します。`);
+  AdsManagerApp.accounts()
+    .withIds(targets.map(target => target.customerId))
+    .executeInParallel('runSyncForAccount', 'summarizeMccResults', JSON.stringify(input));
+  return targets.length;
+}
+
+/**
+ * アカウント一覧シートを読み込み、指定したデータを取得するアカウントを返す
+ * @param {GoogleAppsScript.Spreadsheet.Spreadsheet} controlSpreadsheet - 管理用スプレッドシート
+ * @param {string} datasetName - データ名（記録先のシート名）
+ * @returns {Array<Object>} customerId, accountName, spreadsheetUrl の一覧
+ */
+function readMccAccountList(controlSpreadsheet, datasetName) {
+  const accountSheet = controlSpreadsheet.getSheetByName(MCC_ACCOUNT_SHEET_NAME);
+  if (!accountSheet) {
+    throw new Error(`管理用スプレッドシートに「${MCC_ACCOUNT_SHEET_NAME}」シートがありません。`);
+  }
+  if (accountSheet.getLastRow() <= 1) {
+    return [];
+  }
+
+  const values = accountSheet.getRange(2, 1, accountSheet.getLastRow() - 1, 4).getValues();
+  const targets = [];
+  values.forEach((row, index) => {
+    const customerId = normalizeCustomerId(row[0]);
+    if (!customerId) {
+      return;
+    }
+    const datasets = String(row[3]).split(/[,、，]/).map(name => name.trim()).filter(Boolean);
+    if (datasets.indexOf('すべて') === -1 && datasets.indexOf(datasetName) === -1) {
+      return;
+    }
+    const spreadsheetUrl = String(row[2]).trim();
+    if (spreadsheetUrl.indexOf('https://docs.google.com/spreadsheets/d/') === -1) {
+      console.warn(`${index + 2}行目（${customerId}）のスプレッドシートURLが正しくないため、スキップします。`);
+      return;
+    }
+    targets.push({ customerId: customerId, accountName: String(row[1]).trim(), spreadsheetUrl: spreadsheetUrl });
+  });
+  return targets;
+}
+
+/**
+ * アカウントIDを「123-456-7890」の形式に揃える
+ * @returns {string|null} 10桁の数字でない場合は null
+ */
+function normalizeCustomerId(value) {
+  const digits = String(value).replace(/[^0-9]/g, '');
+  if (digits.length !== 10) {
+    return null;
+  }
+  return `${digits.slice(0, 3)}-${digits.slice(3, 6)}-${digits.slice(6)}`;
+}
+
+/**
+ * 【子アカウントで実行】executeInParallel() から呼び出され、そのアカウントで main() を実行する
+ * @param {string} input - 親スクリプトから渡された設定（JSON）
+ * @returns {string} 実行結果（JSON）
+ */
+function runSyncForAccount(input) {
+  mccChildInput = JSON.parse(input);
+  let result;
+  try {
+    main();
+    result = lastSyncResult || { status: RUN_STATUS.ERROR, message: '実行結果を取得できませんでした。' };
+  } catch (e) {
+    console.error('スクリプトの実行中にエラーが発生しました: ' + e.toString());
+    result = { status: RUN_STATUS.ERROR, message: e.toString() };
+  }
+  result.controlSpreadsheetUrl = mccChildInput.controlSpreadsheetUrl;
+  result.datasetName = mccChildInput.datasetName;
+  result.accountNames = mccChildInput.accountNames;
+  return JSON.stringify(result);
+}
+
+/**
+ * 【MCCで実行】全アカウントの処理が終わった後に呼び出され、アカウントごとの結果をまとめる
+ * @param {Array<AdsManagerApp.ExecutionResult>} results - 各アカウントの実行結果
+ */
+function summarizeMccResults(results) {
+  const rows = [];
+  let controlSpreadsheetUrl = null;
+  let datasetName = '';
+  let accountNames = {};
+  let failedCount = 0;
+
+  results.forEach(executionResult => {
+    const customerId = executionResult.getCustomerId();
+    let returned = {};
+    if (executionResult.getReturnValue()) {
+      returned = JSON.parse(executionResult.getReturnValue());
+      controlSpreadsheetUrl = controlSpreadsheetUrl || returned.controlSpreadsheetUrl;
+      datasetName = datasetName || returned.datasetName;
+      accountNames = returned.accountNames || accountNames;
+    }
+
+    let status = returned.status || RUN_STATUS.ERROR;
+    let message = returned.message || '';
+    if (executionResult.getStatus() !== 'OK') {
+      // タイムアウトやスクリプトエラーで、子アカウントの処理が最後まで終わらなかった場合
+      status = RUN_STATUS.ERROR;
+      message = `${executionResult.getStatus()}: ${executionResult.getError() || ''}`;
+    }
+    if (status === RUN_STATUS.ERROR) {
+      failedCount++;
+    }
+    console.log(`${customerId}: ${status} ${returned.rowCount || 0}件 ${message}`);
+    rows.push([
+      new Date(), customerId, '', datasetName, status,
+      returned.rowCount || 0, returned.startDate || '', returned.endDate || '', message
+    ]);
+  });
+  console.log(`${results.length}アカウント中、${results.length - failedCount}アカウントが正常に終了しました。`);
+
+  if (!controlSpreadsheetUrl) {
+    console.warn('管理用スプレッドシートのURLを取得できなかったため、実行結果はログのみに出力しました。');
+    return;
+  }
+  const controlSpreadsheet = SpreadsheetApp.openByUrl(controlSpreadsheetUrl);
+  let summarySheet = controlSpreadsheet.getSheetByName(MCC_SUMMARY_SHEET_NAME);
+  if (!summarySheet) {
+    summarySheet = controlSpreadsheet.insertSheet(MCC_SUMMARY_SHEET_NAME);
+    summarySheet.getRange(1, 1, 1, MCC_SUMMARY_HEADERS.length).setValues([MCC_SUMMARY_HEADERS]).setFontWeight('bold');
+  }
+  rows.forEach(row => {
+    row[2] = getMccAccountName(row[1], accountNames);
+    row[3] = row[3] || datasetName;
+    summarySheet.appendRow(row);
+  });
+}
+
+/**
+ * 「アカウント一覧」シートのアカウント名を返す（B列が空欄のアカウントだけ、Google広告から取得する。取得できない場合は空文字）
+ * @param {string} customerId - アカウントID
+ * @param {Object} accountNames - アカウントIDごとの「アカウント一覧」シートのアカウント名
+ */
+function getMccAccountName(customerId, accountNames) {
+  if (accountNames[customerId]) {
+    return accountNames[customerId];
+  }
+  const accounts = AdsManagerApp.accounts().withIds([customerId]).get();
+  return accounts.hasNext() ? accounts.next().getName() : '';
+}
@@ -0,0 +1,39 @@
+/**
+ * 【共通ライブラリ・URLの正規化】
+ * 最終ページURL（ランディングページ）を、媒体をまたいで同じページとして集計できる表記に揃えます。
+ * - 計測用のパラメータ（gclid・yclid・utm_〜 など）とページ内リンク（#以降）を取り除く
+ * - スキーム（https など）とホスト名を小文字にする
+ * - 末尾のスラッシュを取り除く（https://example.com/a/ → https://example.com/a）
+ * ★Yahoo広告スクリプト（検索広告取得・検索広告取得_定期実行用）にも、このファイルをそのまま貼り付けて使います。
+ *   Google広告スクリプト専用の処理（AdsApp など）は使わないでください。
+ */
+
+// 取り除くパラメータ（名前は小文字で比べます）。utm_ で始まるパラメータはすべて取り除きます
+const TRACKING_PARAMETERS = ['gclid', 'gbraid', 'wbraid', 'dclid', 'gclsrc', 'yclid', 'ycl_id', 'fbclid', 'msclkid', '_ga', '_gl'];
+const TRACKING_PARAMETER_PREFIXES = ['utm_'];
+
+/**
+ * 最終ページURLを正規化する（URLの形式でない値は前後の空白だけを取り除いて返す）
+ * @param {string} url - 最終ページURL
+ * @return {string} 正規化したURL
+ */
+function normalizeLandingPageUrl(url) {
+  const text = String(url === null || url === undefined ? '' : url).trim();
+  const match = text.match(/^([a-zA-Z][a-zA-Z0-9+.-]*):\/\/([^\/?#]*)([^?#]*)(\?[^#]*)?/);
+  if (!match) {
+    return text;
+  }
+  const origin = match[1].toLowerCase() + '://' + match[2].toLowerCase();
+  const path = match[3].replace(/\/+$/, '');
+  const params = (match[4] || '').slice(1).split('&').filter(param => param && !isTrackingParameter(param.split('=')[0]));
+  return origin + path + (params.length > 0 ? '?' + params.join('&') : '');
+}
+
+/**
+ * 計測用のパラメータかどうかを判定する
+ */
+function isTrackingParameter(name) {
+  const lowerName = name.toLowerCase();
+  return TRACKING_PARAMETERS.indexOf(lowerName) !== -1 ||
+    TRACKING_PARAMETER_PREFIXES.some(prefix => lowerName.indexOf(prefix) === 0);
+}
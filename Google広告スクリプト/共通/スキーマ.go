@@ -0,0 +1,183 @@
+/**
+ * 【共通ライブラリ・スキーマ】
+ * 各データセットの列定義（列キー・見出し・型・表示形式）を検証し、記録先シートの見出し行を定義に合わせます。
+ * 取得する項目を増やしたときは、データセットの columns に列を1つ追加するだけで、
+ * 既存のシートにも正しい位置に列が挿入されます（過去の行の新しい列は空欄のままになります）。
+ * 見出し行が定義と食い違っていて自動で直せない場合は、列がずれたまま書き込まないよう処理を中止します。
+ */
+
+// 列の型と、シートに設定する表示形式（null の場合は設定しない）
+const COLUMN_TYPES = {
+  date: 'yyyy-mm-dd', // 日付
+  text: null,         // 名前・ステータスなどの文字列
+  id: null,           // キャンペーンIDなどの識別子
+  number: null,       // 表示回数・費用などの数値
+  percent: '0.00%'    // クリック率・インプレッションシェアなどの割合（0.0123 を 1.23% と表示）
+};
+
+/**
+ * データセットの列定義を検証し、見出しの一覧（headers）を組み立てる
+ * 列定義は { key, label, type, format, previousLabels, legacyKey, enum, addedKey } の配列です。
+ *   key            … 列を識別するキー（APIのフィールド名など）
+ *   label          … シートの見出し
+ *   type           … COLUMN_TYPES のいずれか
+ *   format         … 表示形式（省略時は型の既定値）
+ *   previousLabels … 以前の見出し（見出しを変更したときに、既存シートの見出しを書き換えるため）
+ *   legacyKey      … 移行前のAWQLのフィールド名（移行チェック.go で新旧の結果を比べるときに使用・任意）
+ *   enum           … 区分値の種類（列挙値.go の ENUM_DEFINITIONS のキー。指定すると書き込む前に表記を揃えます・任意）
+ *   addedKey       … 後から追加したキー列（この列が空欄の既存行は、ほかのキー列だけで突き合わせて置き換えます・任意）
+ * @param {Object} dataset - データセット定義（columns を持つこと。headers を追加します）
+ */
+function registerSchema(dataset) {
+  const errors = [];
+  const keys = new Set();
+  const labels = new Set();
+  dataset.columns.forEach((column, index) => {
+    const name = column.label || `${index + 1}列目`;
+    if (!column.key || !column.label) {
+      errors.push(`${name}: key と label を指定してください。`);
+    }
+    if (!Object.prototype.hasOwnProperty.call(COLUMN_TYPES, column.type)) {
+      errors.push(`${name}: 型「${column.type}」は定義されていません（${Object.keys(COLUMN_TYPES).join(' / ')}）。`);
+    }
+    if (column.enum && !Object.prototype.hasOwnProperty.call(ENUM_DEFINITIONS, column.enum)) {
+      errors.push(`${name}: 区分値の種類「${column.enum}」は定義されていません（${Object.keys(ENUM_DEFINITIONS).join(' / ')}）。`);
+    }
+    if (keys.has(column.key)) {
+      errors.push(`${name}: 列キー「${column.key}」が重複しています。`);
+    }
+    [column.label].concat(column.previousLabels || []).forEach(label => {
+      if (labels.has(label)) {
+        errors.push(`${name}: 見出し「${label}」が重複しています。`);
+      }
+      labels.add(label);
+    });
+    keys.add(column.key);
+  });
+  (dataset.keyHeaders || []).forEach(header => {
+    if (!dataset.columns.some(column => column.label === header)) {
+      errors.push(`キー列「${header}」が列定義にありません。`);
+    }
+  });
+  if (errors.length > 0) {
+    throw new Error('データセットの列定義に誤りがあります。\n- ' + errors.join('\n- '));
+  }
+  dataset.headers = dataset.columns.map(column => column.label);
+}
+
+/**
+ * 記録先シートの見出し行を列定義に合わせる
+ * 空のシートには見出し行を書き込み、既存のシートには足りない列を定義どおりの位置に挿入します。
+ * 定義にない列がある・列の順番が入れ替わっているなど、自動で直せない場合はエラーにします。
+ * @param {GoogleAppsScript.Spreadsheet.Sheet} sheet - 記録先のシート
+ * @param {Object} dataset - データセット定義（registerSchema() 済みのもの）
+ */
+function migrateHeaders(sheet, dataset) {
+  const columns = dataset.columns;
+  if (sheet.getLastRow() === 0) {
+    sheet.getRange(1, 1, 1, columns.length).setValues([dataset.headers]).setFontWeight('bold');
+    applyColumnFormats(sheet, dataset);
+    console.log('ヘッダー行を新規設定しました。');
+    return;
+  }
+
+  const current = readHeaderRow(sheet);
+  if (current.join('\t') === dataset.headers.join('\t')) {
+    applyColumnFormats(sheet, dataset);
+    return;
+  }
+
+  // 既存の見出しが、列定義の何番目の列にあたるかを調べる
+  const problems = [];
+  const positions = current.map((label, sheetIndex) => {
+    const position = columns.findIndex(column => column.label === label || (column.previousLabels || []).indexOf(label) !== -1);
+    if (position === -1) {
+      problems.push(`${sheetIndex + 1}列目「${label}」は列定義にありません。`);
+    }
+    return position;
+  });
+  positions.forEach((position, sheetIndex) => {
+    if (position !== -1 && positions.indexOf(position) !== sheetIndex) {
+      problems.push(`${sheetIndex + 1}列目「${current[sheetIndex]}」が重複しています。`);
+    } else if (sheetIndex > 0 && position !== -1 && position < Math.max.apply(null, positions.slice(0, sheetIndex))) {
+      problems.push(`${sheetIndex + 1}列目「${current[sheetIndex]}」の位置が列定義の順番と異なります。`);
+    }
+  });
+  if (problems.length > 0) {
+    throw new Error(`「${sheet.getName()}」シートの見出し行が列定義と一致しないため、書き込みを中止しました。` +
+      '見出し行を修正してから再実行してください。\n- ' + problems.join('\n- ') +
+      `\n（列定義: ${dataset.headers.join(', ')}）`);
+  }
+
+  const added = [];
+  const renamed = [];
+  let sheetColumn = 0; // シート上の列（0始まり）
+  columns.forEach((column, position) => {
+    if (positions[sheetColumn] === position) {
+      if (current[sheetColumn] !== column.label) {
+        sheet.getRange(1, sheetColumn + 1).setValue(column.label);
+        renamed.push(`${current[sheetColumn]} → ${column.label}`);
+      }
+      sheetColumn++;
+      return;
+    }
+    // 足りない列を挿入する（既存の行は空欄のまま）
+    if (sheetColumn === 0) {
+      sheet.insertColumnBefore(1);
+    } else {
+      sheet.insertColumnAfter(sheetColumn);
+    }
+    sheet.getRange(1, sheetColumn + 1).setValue(column.label).setFontWeight('bold');
+    positions.splice(sheetColumn, 0, position);
+    current.splice(sheetColumn, 0, column.label);
+    added.push(column.label);
+    sheetColumn++;
+  });
+
+  if (renamed.length > 0) {
+    console.log(`見出しを変更しました: ${renamed.join(', ')}`);
+  }
+  if (added.length > 0) {
+    console.log(`列を追加しました: ${added.join(', ')}（既存の行は空欄です。値が必要な場合は MODE を 'range' にして該当期間を再取得してください）`);
+  }
+
+  const migrated = readHeaderRow(sheet);
+  if (migrated.join('\t') !== dataset.headers.join('\t')) {
+    throw new Error(`「${sheet.getName()}」シートの見出し行を列定義に合わせられなかったため、書き込みを中止しました。`);
+  }
+  applyColumnFormats(sheet, dataset);
+}
+
+/**
+ * 取得した行の列数が列定義と一致するかを確認する（一致しない行があれば書き込まずにエラーにする）
+ */
+function assertRowsMatchSchema(dataset, rows) {
+  const invalid = rows.filter(row => row.length !== dataset.headers.length);
+  if (invalid.length > 0) {
+    throw new Error(`取得した${invalid.length}行の列数（${invalid[0].length}列）が列定義（${dataset.headers.length}列）と一致しないため、書き込みを中止しました。`);
+  }
+}
+
+/**
+ * シートの見出し行を読み込む（末尾の空欄は除く）
+ */
+function readHeaderRow(sheet) {
+  const values = sheet.getRange(1, 1, 1, Math.max(sheet.getLastColumn(), 1)).getValues()[0].map(value => String(value).trim());
+  while (values.length > 0 && values[values.length - 1] === '') {
+    values.pop();
+  }
+  return values;
+}
+
+/**
+ * 表示形式のある列に、列全体の表示形式を設定する
+ * 行を追記するとシートの行が増えて書式のない行ができるため、見出し行を確認するたびに設定し直します。
+ */
+function applyColumnFormats(sheet, dataset) {
+  dataset.columns.forEach((column, index) => {
+    const format = column.format || COLUMN_TYPES[column.type];
+    if (format) {
+      sheet.getRange(1, index + 1, sheet.getMaxRows(), 1).setNumberFormat(format);
+    }
+  });
+}
@@ -0,0 +1,64 @@
+/**
+ * 【共通ライブラリ・分割（セグメント）】
+ * 地域別・年齢別・性別のデータを、デバイス・時間帯・曜日でさらに分けて記録するための処理です。
+ * 各スクリプトの SEGMENTS（または「設定」シートの SEGMENTS）に分割名をカンマ区切りで指定すると、
+ * 日付の右隣に分割の列を追加し、重複の防止（upsert）のキー列にも加えます。
+ * 取得するクエリには selectedSegmentFields() のフィールドを追加し、行の値は segmentValues() で取り出します。
+ * ★「同期処理.go」「スキーマ.go」「列挙値.go」と一緒に貼り付けてください。
+ */
+
+// 指定できる分割と、追加する列・取得するフィールド
+const SEGMENT_DEFINITIONS = {
+  device: { field: 'segments.device', column: { key: 'segments.device', label: 'デバイス', type: 'text', enum: 'device' } },
+  hour: { field: 'segments.hour', column: { key: 'segments.hour', label: '時間帯', type: 'number', format: '0' } },
+  dayOfWeek: { field: 'segments.day_of_week', column: { key: 'segments.day_of_week', label: '曜日', type: 'text', enum: 'dayOfWeek' } }
+};
+
+/**
+ * 分割の列を列定義とキー列に追加する
+ * 元の列定義・キー列は dataset.sourceColumns / dataset.sourceKeyHeaders に残すため、何度呼び出しても列は重複しません。
+ * @param {Object} dataset - データセット定義（supportedSegments に指定できる分割を宣言していること）
+ * @param {Array<string>} segments - 追加する分割（SEGMENT_DEFINITIONS のキー）
+ */
+function applySegments(dataset, segments) {
+  if (!dataset.sourceColumns) {
+    dataset.sourceColumns = dataset.columns;
+    dataset.sourceKeyHeaders = dataset.keyHeaders;
+  }
+  const unsupported = segments.filter(name => (dataset.supportedSegments || []).indexOf(name) === -1);
+  if (unsupported.length > 0) {
+    throw new Error(`このデータでは分割「${unsupported.join(', ')}」を指定できません` +
+      `（指定できる分割: ${(dataset.supportedSegments || []).join(' / ') || 'なし'}）。`);
+  }
+
+  // 分割の並びは SEGMENT_DEFINITIONS の順に揃える（指定の順番で列の位置が変わらないように）
+  dataset.segments = Object.keys(SEGMENT_DEFINITIONS).filter(name => segments.indexOf(name) !== -1);
+  const segmentColumns = dataset.segments.map(name => SEGMENT_DEFINITIONS[name].column);
+  dataset.columns = dataset.sourceColumns.slice(0, 1).concat(segmentColumns, dataset.sourceColumns.slice(1));
+  dataset.keyHeaders = dataset.sourceKeyHeaders.slice(0, 1).concat(segmentColumns.map(column => column.label), dataset.sourceKeyHeaders.slice(1));
+  dataset.segmentHeaders = segmentColumns.map(column => column.label);
+  // 区分値の表記（列挙値.go）は、分割を追加した列定義から展開し直す
+  delete dataset.declaredColumns;
+  registerSchema(dataset);
+}
+
+/**
+ * クエリの SELECT に追加するフィールドの一覧を返す（分割を指定していなければ空）
+ */
+function selectedSegmentFields(dataset) {
+  return (dataset.segments || []).map(name => SEGMENT_DEFINITIONS[name].field);
+}
+
+/**
+ * レポートの行から、分割の列に書き込む値を取り出す（日付の右隣に並べる値）
+ */
+function segmentValues(dataset, row) {
+  return selectedSegmentFields(dataset).map(field => row[field]);
+}
+
+/**
+ * クエリの SELECT に書き足せる形（先頭にカンマ付き）で、分割のフィールドを返す
+ */
+function segmentSelectClause(dataset) {
+  return selectedSegmentFields(dataset).map(field => `,\n        ${field}`).join('');
+}
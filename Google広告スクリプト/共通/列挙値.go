@@ -0,0 +1,601 @@
+/**
+ * 【共通ライブラリ・区分値の変換】
+ * デバイス・マッチタイプ・年齢などの区分値（APIのEnum値）を、すべてのデータ取得スクリプトで同じ日本語の表記に変換します。
+ * 各データセットは取得した値（GAQLのEnum値、またはAWQLの表示名）をそのまま返し、列定義に enum: '種類' を書くだけで、
+ * 書き込む前にここで変換されます。表記はシートごとに「設定」シートの ENUM_OUTPUT で選べます。
+ *   'label' … 日本語の表記（例: スマートフォン）
+ *   'code'  … GAQLのEnum値（例: MOBILE）
+ *   'both'  … 日本語の表記の右隣に「（コード）」列を追加して、両方を記録
+ *   'legacy' … AWQLで取得していた頃にシートへ記録していた値（例: STREAMING_TV・enabled）。移行前のシートに書き足す基本データ取得.go の既定
+ * ENUM_OUTPUT を指定しない場合は、記録先シートの既存の行と同じ表記で記録します（シートが空なら 'label'）。
+ * 1つの列に日本語の表記とEnum値が混在している場合は、書き込んだ後にログで警告します。
+ * 変換表にない値はそのまま記録し、記録先スプレッドシートの「未登録の値」シートに一覧を残します。
+ * ★「同期処理.go」「スキーマ.go」と一緒に貼り付けてください。
+ */
+
+// 変換表にない値を記録するシート名（自動作成されます）
+const UNMAPPED_ENUM_SHEET_NAME = '未登録の値';
+
+const UNMAPPED_ENUM_HEADERS = ['記録日時', 'シート名', '種類', '値', '件数'];
+
+// 区分値の種類ごとの変換表
+//   labels  … GAQLのEnum値 → 日本語の表記
+//   aliases … AWQLの表示名や旧表記 → GAQLのEnum値（大文字・アンダースコアに直しても一致しないものだけ）
+//   legacy  … GAQLのEnum値 → AWQLで取得していた頃に記録していた値（Enum値と違うものだけ。ENUM_OUTPUT が 'legacy' のときに使う）
+const ENUM_DEFINITIONS = {
+  channelType: {
+    name: '広告チャネルタイプ',
+    labels: {
+      'SEARCH': '検索',
+      'DISPLAY': 'ディスプレイ',
+      'SHOPPING': 'ショッピング',
+      'VIDEO': '動画',
+      'MULTI_CHANNEL': 'アプリ',
+      'SMART': 'スマート',
+      'HOTEL': 'ホテル',
+      'LOCAL': 'ローカル',
+      'LOCAL_SERVICES': 'ローカル サービス',
+      'DEMAND_GEN': 'デマンド ジェネレーション',
+      'PERFORMANCE_MAX': 'P-MAX',
+      'TRAVEL': '旅行'
+    },
+    aliases: {}
+  },
+  adGroupType: {
+    name: '広告グループの種類',
+    labels: {
+      'SEARCH_STANDARD': '標準',
+      'SEARCH_DYNAMIC_ADS': '動的広告',
+      'DISPLAY_STANDARD': 'ディスプレイ',
+      'DISPLAY_ENGAGEMENT_AD': 'ディスプレイ エンゲージメント',
+      'SHOPPING_PRODUCT_ADS': 'ショッピング - 商品',
+      'SHOPPING_SHOWCASE_ADS': 'ショッピング - ショーケース',
+      'SHOPPING_SMART_ADS': 'ショッピング - スマート',
+      'SHOPPING_COMPARISON_LISTING_ADS': 'ショッピング - コレクション',
+      'VIDEO_TRUE_VIEW_IN_STREAM': 'インストリーム',
+      'VIDEO_RESPONSIVE': 'インストリーム',
+      'VIDEO_ACTION': 'インストリーム',
+      'VIDEO_NON_SKIPPABLE_IN_STREAM': 'インストリーム',
+      'VIDEO_BUMPER': 'インストリーム',
+      'VIDEO_OUTSTREAM': 'インストリーム',
+      'VIDEO_DISCOVERY': 'インフィード動画',
+      'VIDEO_TRUE_VIEW_IN_DISPLAY': 'インフィード動画',
+      'VIDEO_EFFICIENT_REACH': '効率的なリーチ',
+      'HOTEL_ADS': 'ホテル広告',
+      'SMART_CAMPAIGN_ADS': 'スマート'
+    },
+    aliases: {
+      'STANDARD': 'SEARCH_STANDARD', // 以下はAWQLの表示名
+      'DISPLAY': 'DISPLAY_STANDARD',
+      'SHOPPING_PRODUCT': 'SHOPPING_PRODUCT_ADS',
+      'SHOPPING_SHOWCASE': 'SHOPPING_SHOWCASE_ADS'
+    }
+  },
+  device: {
+    name: 'デバイス',
+    labels: {
+      'DESKTOP': 'コンピュータ',
+      'TABLET': 'タブレット',
+      'MOBILE': 'スマートフォン',
+      'CONNECTED_TV': 'テレビ画面',
+      'OTHER': 'その他'
+    },
+    aliases: {
+      'MOBILE_DEVICES_WITH_FULL_BROWSERS': 'MOBILE',
+      'COMPUTERS': 'DESKTOP',
+      'TABLETS_WITH_FULL_BROWSERS': 'TABLET',
+      'DEVICES_STREAMING_VIDEO_CONTENT_TO_TV_SCREENS': 'CONNECTED_TV',
+      'STREAMING_TV': 'CONNECTED_TV' // 以前の基本データ取得で記録していた表記
+    },
+    legacy: {
+      'CONNECTED_TV': 'STREAMING_TV'
+    }
+  },
+  matchType: {
+    name: 'マッチタイプ',
+    labels: {
+      'EXACT': '完全一致',
+      'PHRASE': 'フレーズ一致',
+      'BROAD': '部分一致'
+    },
+    aliases: {}
+  },
+  searchTermMatchType: {
+    name: '検索語句のマッチタイプ',
+    labels: {
+      'EXACT': '完全一致',
+      'PHRASE': 'フレーズ一致',
+      'BROAD': '部分一致',
+      'NEAR_EXACT': '完全一致（類似パターン）',
+      'NEAR_PHRASE': 'フレーズ一致（類似パターン）'
+    },
+    aliases: {}
+  },
+  searchTermStatus: {
+    name: '検索語句の追加・除外',
+    labels: {
+      'ADDED': '追加済み',
+      'EXCLUDED': '除外済み',
+      'ADDED_EXCLUDED': '追加済み・除外済み',
+      'NONE': 'なし'
+    },
+    aliases: {}
+  },
+  adType: {
+    name: '広告タイプ',
+    labels: {
+      'RESPONSIVE_SEARCH_AD': 'レスポンシブ検索広告',
+      'EXPANDED_TEXT_AD': '拡張テキスト広告',
+      'TEXT_AD': 'テキスト広告',
+      'EXPANDED_DYNAMIC_SEARCH_AD': '動的検索広告',
+      'CALL_AD': '電話専用広告',
+      'RESPONSIVE_DISPLAY_AD': 'レスポンシブ ディスプレイ広告',
+      'IMAGE_AD': 'イメージ広告',
+      'VIDEO_AD': '動画広告',
+      'VIDEO_RESPONSIVE_AD': '動画レスポンシブ広告',
+      'DEMAND_GEN_MULTI_ASSET_AD': 'デマンド ジェネレーション 画像広告',
+      'DEMAND_GEN_CAROUSEL_AD': 'デマンド ジェネレーション カルーセル広告',
+      'DEMAND_GEN_VIDEO_RESPONSIVE_AD': 'デマンド ジェネレーション 動画広告',
+      'APP_AD': 'アプリ広告',
+      'SHOPPING_PRODUCT_AD': 'ショッピング広告',
+      'SMART_CAMPAIGN_AD': 'スマート アシスト キャンペーン広告'
+    },
+    aliases: {}
+  },
+  adStrength: {
+    name: '広告の有効性',
+    labels: {
+      'EXCELLENT': '非常に良い',
+      'GOOD': '良',
+      'AVERAGE': '平均的',
+      'POOR': '低',
+      'PENDING': '保留中',
+      'NO_ADS': '広告なし'
+    },
+    aliases: {}
+  },
+  assetFieldType: {
+    name: 'アセットの種類',
+    labels: {
+      'HEADLINE': '広告見出し',
+      'LONG_HEADLINE': '長い広告見出し',
+      'DESCRIPTION': '説明文',
+      'BUSINESS_NAME': 'ビジネス名',
+      'MARKETING_IMAGE': '画像',
+      'SQUARE_MARKETING_IMAGE': '画像（スクエア）',
+      'PORTRAIT_MARKETING_IMAGE': '画像（縦長）',
+      'LOGO': 'ロゴ',
+      'LANDSCAPE_LOGO': 'ロゴ（横長）',
+      'YOUTUBE_VIDEO': '動画',
+      'CALL_TO_ACTION_SELECTION': '行動を促すフレーズ',
+      'SITELINK': 'サイトリンク',
+      'CALLOUT': 'コールアウト',
+      'STRUCTURED_SNIPPET': '構造化スニペット'
+    },
+    aliases: {}
+  },
+  assetPerformance: {
+    name: 'アセットの評価',
+    labels: {
+      'BEST': '最良',
+      'GOOD': '良',
+      'LOW': '低',
+      'LEARNING': '学習中',
+      'PENDING': '保留中'
+    },
+    aliases: {}
+  },
+  qualityScoreBucket: {
+    name: '品質スコアの評価',
+    labels: {
+      'ABOVE_AVERAGE': '平均より上',
+      'AVERAGE': '平均的',
+      'BELOW_AVERAGE': '平均より下'
+    },
+    aliases: {}
+  },
+  conversionCategory: {
+    name: 'コンバージョンのカテゴリ',
+    labels: {
+      'DEFAULT': 'その他',
+      'PAGE_VIEW': 'ページビュー',
+      'PURCHASE': '購入',
+      'SIGNUP': '登録',
+      'DOWNLOAD': 'ダウンロード',
+      'ADD_TO_CART': 'カートに追加',
+      'BEGIN_CHECKOUT': '購入手続き開始',
+      'SUBSCRIBE_PAID': '定期購入',
+      'PHONE_CALL_LEAD': '電話での問い合わせ',
+      'IMPORTED_LEAD': 'インポートした見込み顧客',
+      'SUBMIT_LEAD_FORM': '見込み顧客フォームの送信',
+      'BOOK_APPOINTMENT': '予約',
+      'REQUEST_QUOTE': '見積もり依頼',
+      'GET_DIRECTIONS': 'ルート検索',
+      'OUTBOUND_CLICK': 'アウトバウンド クリック',
+      'CONTACT': '問い合わせ',
+      'ENGAGEMENT': 'エンゲージメント',
+      'STORE_VISIT': '来店',
+      'STORE_SALE': '店舗販売',
+      'QUALIFIED_LEAD': '質の高い見込み顧客',
+      'CONVERTED_LEAD': '成約した見込み顧客'
+    },
+    aliases: {}
+  },
+  conversionCountingType: {
+    name: 'コンバージョンのカウント方法',
+    labels: {
+      'ONE_PER_CLICK': '1回',
+      'MANY_PER_CLICK': '毎回'
+    },
+    aliases: {}
+  },
+  attributionModel: {
+    name: 'アトリビューション モデル',
+    labels: {
+      'EXTERNAL': '外部',
+      'GOOGLE_ADS_LAST_CLICK': 'ラストクリック',
+      'GOOGLE_SEARCH_ATTRIBUTION_FIRST_CLICK': 'ファーストクリック',
+      'GOOGLE_SEARCH_ATTRIBUTION_LINEAR': '線形',
+      'GOOGLE_SEARCH_ATTRIBUTION_TIME_DECAY': '減衰',
+      'GOOGLE_SEARCH_ATTRIBUTION_POSITION_BASED': '接点ベース',
+      'GOOGLE_SEARCH_ATTRIBUTION_DATA_DRIVEN': 'データドリブン'
+    },
+    aliases: {}
+  },
+  ageRange: {
+    name: '年齢',
+    labels: {
+      'AGE_RANGE_18_24': '18歳～24歳',
+      'AGE_RANGE_25_34': '25歳～34歳',
+      'AGE_RANGE_35_44': '35歳～44歳',
+      'AGE_RANGE_45_54': '45歳～54歳',
+      'AGE_RANGE_55_64': '55歳～64歳',
+      'AGE_RANGE_65_UP': '65歳～',
+      'AGE_RANGE_UNDETERMINED': '不明'
+    },
+    aliases: {
+      'UNDETERMINED': 'AGE_RANGE_UNDETERMINED'
+    }
+  },
+  gender: {
+    name: '性別',
+    labels: {
+      'MALE': '男性',
+      'FEMALE': '女性',
+      'UNDETERMINED': '不明'
+    },
+    aliases: {}
+  },
+  dayOfWeek: {
+    name: '曜日',
+    labels: {
+      'MONDAY': '月',
+      'TUESDAY': '火',
+      'WEDNESDAY': '水',
+      'THURSDAY': '木',
+      'FRIDAY': '金',
+      'SATURDAY': '土',
+      'SUNDAY': '日'
+    },
+    aliases: {}
+  },
+  status: {
+    name: 'ステータス',
+    labels: {
+      'ENABLED': '有効',
+      'PAUSED': '一時停止',
+      'REMOVED': '削除済み',
+      'HIDDEN': '非表示'
+    },
+    aliases: {},
+    legacy: {
+      'ENABLED': 'enabled', // AWQLの CampaignStatus は小文字で返っていた
+      'PAUSED': 'paused',
+      'REMOVED': 'removed'
+    }
+  },
+  biddingStrategy: {
+    name: '入札戦略タイプ',
+    labels: {
+      'MANUAL_CPC': '個別クリック単価',
+      'MANUAL_CPM': '個別インプレッション単価',
+      'MANUAL_CPV': '広告視聴単価',
+      'ENHANCED_CPC': '拡張クリック単価',
+      'MAXIMIZE_CONVERSIONS': 'コンバージョン数の最大化',
+      'MAXIMIZE_CONVERSION_VALUE': 'コンバージョン値の最大化',
+      'TARGET_CPA': '目標コンバージョン単価',
+      'TARGET_ROAS': '目標広告費用対効果',
+      'TARGET_SPEND': 'クリック数の最大化',
+      'TARGET_IMPRESSION_SHARE': '目標インプレッション シェア',
+      'TARGET_CPM': '目標インプレッション単価',
+      'TARGET_CPV': '目標広告視聴単価',
+      'COMMISSION': 'コミッション',
+      'PERCENT_CPC': 'クリック単価（割合）',
+      'INVALID': '（無効）'
+    },
+    aliases: {
+      'CPC': 'MANUAL_CPC',
+      'CPM': 'MANUAL_CPM',
+      'CPV': 'MANUAL_CPV',
+      'MAXIMIZE_CLICKS': 'TARGET_SPEND'
+    },
+    legacy: {
+      'MANUAL_CPC': 'cpc', // AWQLの BiddingStrategyType の表示名
+      'MANUAL_CPM': 'cpm',
+      'MANUAL_CPV': 'cpv',
+      'ENHANCED_CPC': 'Enhanced CPC',
+      'MAXIMIZE_CONVERSIONS': 'Maximize conversions',
+      'MAXIMIZE_CONVERSION_VALUE': 'Maximize conversion value',
+      'TARGET_CPA': 'Target CPA',
+      'TARGET_ROAS': 'Target ROAS',
+      'TARGET_SPEND': 'Maximize clicks',
+      'TARGET_IMPRESSION_SHARE': 'Target impression share'
+    }
+  }
+};
+
+// どの種類にも共通する値
+const COMMON_ENUM_LABELS = {
+  'UNKNOWN': '（不明）',
+  'UNSPECIFIED': '（未指定）'
+};
+
+// ENUM_OUTPUT で選べる表記
+const ENUM_OUTPUTS = ['label', 'code', 'both', 'legacy'];
+
+// この実行で見つかった、変換表にない値（種類 → 値 → 件数）
+const unmappedEnumValues = new Map();
+
+/**
+ * 列定義の区分値の列を、ENUM_OUTPUT に合わせて展開する
+ * 'both' のときは、区分値の列の右隣に「（コード）」列を追加した列定義に組み替えて、見出しの一覧も作り直します。
+ * 元の列定義は dataset.declaredColumns に残すため、同じ実行で何度呼び出しても列が増え続けることはありません。
+ * @param {Object} dataset - データセット定義（registerSchema() 済みのもの）
+ * @param {string} enumOutput - 'label' / 'code' / 'both' / 'legacy'
+ */
+function applyEnumOutput(dataset, enumOutput) {
+  if (ENUM_OUTPUTS.indexOf(enumOutput) === -1) {
+    throw new Error(`区分値の表記「${enumOutput}」には対応していません（${ENUM_OUTPUTS.join(' / ')}）。`);
+  }
+  if (!dataset.declaredColumns) {
+    dataset.declaredColumns = dataset.columns;
+  }
+  dataset.enumOutput = enumOutput;
+  dataset.columns = [];
+  dataset.declaredColumns.forEach(column => {
+    dataset.columns.push(column);
+    if (column.enum && enumOutput === 'both') {
+      dataset.columns.push({ key: `${column.key}:code`, label: `${column.label}（コード）`, type: 'text' });
+    }
+  });
+  registerSchema(dataset);
+}
+
+/**
+ * 記録先シートの既存の行が、どの表記で記録されているかを調べる
+ * 「（コード）」列があれば 'both'、なければ区分値の列に多く記録されている表記（'label' / 'code'）を返します。
+ * @param {GoogleAppsScript.Spreadsheet.Sheet} sheet - 記録先のシート
+ * @param {Object} dataset - データセット定義（registerSchema() 済みのもの）
+ * @returns {string|null} 既存の行の表記（区分値の行がなければ null）
+ */
+function detectStoredEnumOutput(sheet, dataset) {
+  const counts = countStoredEnumForms(sheet, dataset);
+  if (counts.some(count => count.hasCodeColumn)) {
+    return 'both';
+  }
+  const labels = counts.reduce((sum, count) => sum + count.label, 0);
+  const codes = counts.reduce((sum, count) => sum + count.code, 0);
+  if (labels === 0 && codes === 0) {
+    return null;
+  }
+  return codes > labels ? 'code' : 'label';
+}
+
+/**
+ * 区分値の列に、日本語の表記とEnum値（またはAWQLの表示名などの旧表記）が混在していれば警告する
+ * 混在していると、シートのフィルタやレポートの集計で同じ値が別々に扱われるため、表記を揃える手順をログに出します。
+ * @param {GoogleAppsScript.Spreadsheet.Sheet} sheet - 記録先のシート
+ * @param {Object} dataset - applyEnumOutput() 済みのデータセット定義
+ */
+function warnMixedEnumValues(sheet, dataset) {
+  const mixed = countStoredEnumForms(sheet, dataset)
+    .filter(count => [count.label, count.code, count.legacy, count.other].filter(number => number > 0).length > 1)
+    .map(count => `「${count.header}」列（日本語の表記 ${count.label}件・Enum値 ${count.code}件・` +
+      (count.legacy > 0 ? `AWQLの頃の表記 ${count.legacy}件・` : '') + `その他の表記 ${count.other}件）`);
+  if (mixed.length === 0) {
+    return;
+  }
+  console.warn(`「${sheet.getName()}」シートで、区分値の表記が混在しています: ${mixed.join(', ')}。` +
+    `「${CONFIG_SHEET_NAME}」シートの ENUM_OUTPUT で表記を指定し（現在: ${dataset.enumOutput}）、` +
+    'MODE を \'range\' にして混在している期間を再取得すると揃います。');
+}
+
+/**
+ * 記録先シートの区分値の列ごとに、日本語の表記・Enum値・その他の表記（AWQLの表示名など）の件数を数える
+ * ENUM_OUTPUT が 'legacy' のときは、AWQLの頃の表記と同じ値（MOBILE・STREAMING_TV など）をまとめて legacy に数えます。
+ * 変換表にない値と、空欄・括弧書きの目印は数えません。
+ */
+function countStoredEnumForms(sheet, dataset) {
+  const lastRow = sheet.getLastRow();
+  if (lastRow === 0) {
+    return [];
+  }
+  const header = readHeaderRow(sheet);
+  const counts = [];
+  (dataset.declaredColumns || dataset.columns).filter(column => column.enum).forEach(column => {
+    const sheetIndex = header.findIndex(label => label === column.label || (column.previousLabels || []).indexOf(label) !== -1);
+    if (sheetIndex === -1) {
+      return;
+    }
+    const count = { header: column.label, label: 0, code: 0, legacy: 0, other: 0, hasCodeColumn: header.indexOf(`${column.label}（コード）`) !== -1 };
+    const values = lastRow > 1 ? sheet.getRange(2, sheetIndex + 1, lastRow - 1, 1).getValues() : [];
+    values.forEach(row => {
+      const form = classifyEnumValue(column.enum, row[0], dataset.enumOutput);
+      if (form) {
+        count[form]++;
+      }
+    });
+    counts.push(count);
+  });
+  return counts;
+}
+
+/**
+ * 記録済みの区分値が 'label'（日本語の表記）・'code'（Enum値）・'legacy'（AWQLの頃の表記）・'other'（旧表記）のどれかを返す
+ * 'legacy' は enumOutput が 'legacy' のときだけ返します（判別しない値は null）。
+ */
+function classifyEnumValue(kind, value, enumOutput) {
+  if (isEnumPlaceholder(value)) {
+    return null;
+  }
+  const definition = ENUM_DEFINITIONS[kind];
+  const text = String(value).trim();
+  const code = toEnumCode(kind, text);
+  const known = Object.prototype.hasOwnProperty.call(definition.labels, code) ||
+    Object.prototype.hasOwnProperty.call(COMMON_ENUM_LABELS, code);
+  if (!known) {
+    return null;
+  }
+  if (enumOutput === 'legacy' && text === toEnumLegacy(kind, code)) {
+    return 'legacy';
+  }
+  if (text === code) {
+    return 'code';
+  }
+  return text === (definition.labels[code] || COMMON_ENUM_LABELS[code]) ? 'label' : 'other';
+}
+
+/**
+ * fetchRows() の行（区分値は取得したままの値）を、ENUM_OUTPUT の表記に変換する
+ * @param {Object} dataset - applyEnumOutput() 済みのデータセット定義
+ * @param {Array<Array>} rows - 元の列定義と同じ並びの行
+ * @returns {Array<Array>} 展開後の列定義と同じ並びの行
+ */
+function translateEnumRows(dataset, rows) {
+  const columns = dataset.declaredColumns || dataset.columns;
+  if (!columns.some(column => column.enum)) {
+    return rows;
+  }
+  return rows.map(row => {
+    const translated = [];
+    columns.forEach((column, index) => {
+      if (!column.enum || index >= row.length) {
+        translated.push(row[index]);
+        return;
+      }
+      const code = toEnumCode(column.enum, row[index]);
+      const label = toEnumLabel(column.enum, code);
+      if (dataset.enumOutput === 'code') {
+        translated.push(code);
+      } else if (dataset.enumOutput === 'legacy') {
+        translated.push(toEnumLegacy(column.enum, code));
+      } else {
+        translated.push(label);
+        if (dataset.enumOutput === 'both') {
+          translated.push(code);
+        }
+      }
+    });
+    return translated;
+  });
+}
+
+/**
+ * 区分値をGAQLのEnum値に揃える
+ * GAQLのEnum値・AWQLの表示名（例: Mobile devices with full browsers）・日本語の表記のどれを渡しても、同じEnum値を返します。
+ * 空欄や「(P-MAX)」のような括弧書きの値（区分値がない行の目印）は、そのまま返します。
+ * 複数のEnum値が同じ日本語の表記を持つ場合（広告グループの種類の「インストリーム」など）は、どれか決められないため
+ * 表記のまま返します（書き込むときは変換表にない値と同じく「未登録の値」に記録されます）。
+ */
+function toEnumCode(kind, value) {
+  if (isEnumPlaceholder(value)) {
+    return value;
+  }
+  const definition = ENUM_DEFINITIONS[kind];
+  const text = String(value).trim();
+  const byLabel = Object.keys(definition.labels).filter(code => definition.labels[code] === text);
+  if (byLabel.length === 1) {
+    return byLabel[0];
+  }
+  if (byLabel.length > 1) {
+    return text;
+  }
+  const normalized = text.toUpperCase().replace(/[^A-Z0-9]+/g, '_').replace(/^_+|_+$/g, '');
+  return definition.aliases[normalized] || normalized || text;
+}
+
+/**
+ * GAQLのEnum値を日本語の表記に変換する（変換表にない値はそのまま返し、「未登録の値」として記録する）
+ */
+function toEnumLabel(kind, code) {
+  if (isEnumPlaceholder(code)) {
+    return code;
+  }
+  const definition = ENUM_DEFINITIONS[kind];
+  if (Object.prototype.hasOwnProperty.call(definition.labels, code)) {
+    return definition.labels[code];
+  }
+  if (Object.prototype.hasOwnProperty.call(COMMON_ENUM_LABELS, code)) {
+    return COMMON_ENUM_LABELS[code];
+  }
+  if (!unmappedEnumValues.has(kind)) {
+    unmappedEnumValues.set(kind, new Map());
+  }
+  const counts = unmappedEnumValues.get(kind);
+  counts.set(code, (counts.get(code) || 0) + 1);
+  return code;
+}
+
+/**
+ * GAQLのEnum値を、AWQLで取得していた頃に記録していた値に変換する（表記が変わっていない値はEnum値のまま返す）
+ */
+function toEnumLegacy(kind, code) {
+  const legacy = ENUM_DEFINITIONS[kind].legacy || {};
+  return Object.prototype.hasOwnProperty.call(legacy, code) ? legacy[code] : code;
+}
+
+/**
+ * 区分値として変換しない値（空欄・括弧書きの目印）かどうか
+ */
+function isEnumPlaceholder(value) {
+  if (value === null || value === undefined) {
+    return true;
+  }
+  const text = String(value).trim();
+  return text === '' || /^[(（].*[)）]$/.test(text);
+}
+
+/**
+ * この実行で見つかった変換表にない値を、ログと「未登録の値」シートに記録する
+ * 同じシート・種類・値の組み合わせは、シートに一度だけ記録します（2回目以降はログのみ）。
+ * @param {GoogleAppsScript.Spreadsheet.Spreadsheet} spreadsheet - 記録先のスプレッドシート
+ * @param {string} sheetName - データを記録しているシート名
+ */
+function reportUnmappedEnums(spreadsheet, sheetName) {
+  if (unmappedEnumValues.size === 0) {
+    return;
+  }
+  const found = [];
+  unmappedEnumValues.forEach((counts, kind) => {
+    counts.forEach((count, value) => found.push({ kind: ENUM_DEFINITIONS[kind].name, value: value, count: count }));
+  });
+  unmappedEnumValues.clear();
+  console.warn(`変換表にない区分値があったため、そのまま記録しました（「共通/列挙値.go」の ENUM_DEFINITIONS に追加してください）: ` +
+    found.map(item => `${item.kind}「${item.value}」${item.count}件`).join(', '));
+
+  let reportSheet = spreadsheet.getSheetByName(UNMAPPED_ENUM_SHEET_NAME);
+  if (!reportSheet) {
+    reportSheet = spreadsheet.insertSheet(UNMAPPED_ENUM_SHEET_NAME);
+    reportSheet.getRange(1, 1, 1, UNMAPPED_ENUM_HEADERS.length).setValues([UNMAPPED_ENUM_HEADERS]).setFontWeight('bold');
+  }
+  const recorded = new Set();
+  if (reportSheet.getLastRow() > 1) {
+    reportSheet.getRange(2, 2, reportSheet.getLastRow() - 1, 3).getValues().forEach(row => recorded.add(row.join('|')));
+  }
+  const newRows = found
+    .filter(item => !recorded.has([sheetName, item.kind, item.value].join('|')))
+    .map(item => [new Date(), sheetName, item.kind, item.value, item.count]);
+  if (newRows.length > 0) {
+    reportSheet.getRange(reportSheet.getLastRow() + 1, 1, newRows.length, UNMAPPED_ENUM_HEADERS.length).setValues(newRows);
+  }
+}
@@ -0,0 +1,778 @@
+/**
+ * 【共通ライブラリ・差分同期】
+ * Google広告のデータ取得スクリプトで共通して使う処理をまとめたファイルです。
+ * 取得期間の決定、データの追記、日付順の並べ替え、タイムゾーンの扱いを担当します。
+ * 取得済みの期間は「実行履歴.go」で記録し、次回の取得開始日の判断に使います。
+ * 取得方法や対象年などは「設定.go」で「設定」シートから読み込み、取得前に検証します。
+ * 記録先シートの見出し行は「スキーマ.go」で各データセットの列定義に合わせます。
+ * デバイス・マッチタイプなどの区分値は「列挙値.go」で、書き込む前に設定どおりの表記に揃えます。
+ * 地域別・年齢別・性別のデータをデバイスなどで分ける列は「セグメント.go」で追加します。
+ * コンバージョンアクション別のデータに、CV発生日で数えた列を追加する処理は「CV発生日.go」にあります。
+ * 長い期間は1か月ずつに分けて取得し、途中で止まっても次回の実行で続きから再開します。
+ * ★各データ取得スクリプトと同じスクリプト内に、このファイルの内容をすべて貼り付けてください。
+ */
+
+// --------------------------------------------------------------------------------
+// 既定値
+// --------------------------------------------------------------------------------
+
+// 各スクリプトの runSync() に渡す設定の既定値
+const SYNC_DEFAULTS = {
+  mode: 'daily',        // 'daily'（未取得分） / 'range'（期間指定） / 'year'（年指定） / 'all'（配信開始日から）
+  startDate: null,      // mode が 'range' のときの開始日（yyyy-MM-dd）
+  endDate: null,        // mode が 'range' のときの終了日（yyyy-MM-dd。未指定なら取得できる最新日まで）
+  targetYear: null,     // mode が 'year' のときに取得する年（西暦）
+  endOffsetDays: null,  // 何日前までを取得対象にするか（未指定なら daily は昨日、それ以外は前々日）
+  initialDays: 1,       // シートが空のときに、終了日から遡って取得する日数
+  lookbackDays: 0,      // daily のとき、取得済みでも毎回取り直す直近の日数（コンバージョンの計上遅れ対策）
+  writeMode: 'upsert',  // 'upsert'（キーが同じ既存行を置き換える） または 'append'（末尾に追記するのみ）
+  campaignFilter: '',   // キャンペーン名にこの文字列を含む行だけを記録する（空欄ならすべて）
+  enumOutput: null,     // 区分値の表記 'label'（日本語） / 'code'（GAQLのEnum値） / 'both'（両方の列を記録）。未指定なら既存の行と同じ表記（シートが空なら 'label'）
+  segments: [],         // 追加する分割（'device' / 'hour' / 'dayOfWeek'。データセットの supportedSegments にあるもののみ）
+  includeConversionDate: false, // CV発生日のコンバージョン数・価値も記録する（データセットに conversionDateAfter があるもののみ）
+  minRemainingSeconds: 180 // 残り実行時間がこれを下回ったら、次の月に進まずに中断する
+};
+
+// 次の月を取得するのに必要な時間の見積もり（直前までで最も時間がかかった月の何倍を見込むか）
+const CHUNK_TIME_SAFETY_FACTOR = 1.5;
+
+// 再取得時にコンバージョン数の変化を集計する列（データセットの headers から最初に見つかったものを使う）
+const CONVERSION_HEADERS = ['コンバージョン数', 'コンバージョン'];
+
+// mode が 'all' のとき、配信開始日を探し始める日付
+const ACCOUNT_HISTORY_FROM = '2000-01-01';
+
+// 直近の runSync() の実行結果（MCC実行で、各アカウントの結果を親スクリプトへ返すために使う）
+let lastSyncResult = null;
+
+// --------------------------------------------------------------------------------
+// メイン処理
+// --------------------------------------------------------------------------------
+
+/**
+ * データセット定義に従って、取得期間の決定からシートへの書き込みまでを実行する
+ * 取得期間は月ごとに分割し、1か月分ずつ書き込みと実行履歴の記録を行います。
+ * 残り実行時間が足りなくなった場合は月の区切りで中断し、次回の実行で続きから再開します。
+ * MCC（クライアントセンター）で実行した場合は、アカウント一覧の各アカウントに処理を振り分けます（「MCC実行.go」）。
+ * @param {Object} dataset - データセット定義（columns, keyHeaders, fetchRows）
+ * @param {Object} options - 実行設定（spreadsheetUrl, sheetName, mode, startDate, endDate, targetYear, lookbackDays, writeMode など）
+ * @returns {Object} 実行結果（status, rowCount, startDate, endDate, message）。MCCの親スクリプトでは、振り分けたアカウント数を message に入れて返します
+ */
+function runSync(dataset, options) {
+  const settings = Object.assign({}, SYNC_DEFAULTS, dataset.defaults || {}, options);
+  applyMccAccountSettings(settings);
+
+  const result = { status: RUN_STATUS.SUCCESS, rowCount: 0, startDate: null, endDate: null, message: '' };
+  lastSyncResult = result;
+  try {
+    // 列定義を検証し、「設定」シートの値を反映して、取得を始める前にすべての設定値を確認する
+    registerSchema(dataset);
+    loadSyncConfig(settings, dataset);
+    if (dataset.supportedSegments) {
+      applySegments(dataset, settings.segments);
+    }
+    if (dataset.conversionDateAfter) {
+      applyConversionDate(dataset, settings.includeConversionDate);
+    }
+    applyEnumOutput(dataset, settings.enumOutput || 'label');
+  } catch (e) {
+    console.error(e.message);
+    result.status = RUN_STATUS.ERROR;
+    result.message = e.message;
+    return result;
+  }
+
+  if (isMccParentExecution()) {
+    // 各アカウントの件数・期間は summarizeMccResults() が「MCC実行結果」シートにまとめる
+    try {
+      const accountCount = runSyncForAllAccounts(settings);
+      result.message = accountCount > 0 ? `${accountCount}アカウントに振り分け` : '取得するアカウントなし';
+    } catch (e) {
+      console.error('スクリプトの実行中にエラーが発生しました: ' + e.toString());
+      result.status = RUN_STATUS.ERROR;
+      result.message = e.toString();
+    }
+    return result;
+  }
+  let spreadsheet;
+  let sheet;
+  let chunks;
+  try {
+    spreadsheet = openSpreadsheet(settings.spreadsheetUrl);
+    sheet = getOrCreateSheet(spreadsheet, settings.sheetName);
+    if (!settings.enumOutput) {
+      // 既存の行と違う表記で書き足して混在させないよう、シートに記録済みの表記に合わせる
+      const storedEnumOutput = detectStoredEnumOutput(sheet, dataset);
+      if (storedEnumOutput && storedEnumOutput !== 'label') {
+        console.log(`区分値は、シートに記録済みの行と同じ表記（${storedEnumOutput}）で記録します（ENUM_OUTPUT で変更できます）。`);
+        applyEnumOutput(dataset, storedEnumOutput);
+      }
+    }
+    migrateHeaders(sheet, dataset);
+
+    const timezone = AdsApp.currentAccount().getTimeZone();
+    const range = resolveSyncRange(sheet, settings, timezone);
+    if (!range) {
+      result.message = '取得対象の期間なし';
+      return result;
+    }
+    console.log(`取得期間: ${range.startDate} から ${range.endDate}`);
+
+    chunks = planChunks(spreadsheet, settings.sheetName, range);
+    if (chunks.length === 0) {
+      console.log('指定された期間はすべて取得済みです。処理を終了します。');
+      result.message = 'すべて取得済み';
+      return result;
+    }
+  } catch (e) {
+    console.error('スクリプトの実行中にエラーが発生しました: ' + e.toString());
+    console.error('エラー詳細: ' + e.stack);
+    result.status = RUN_STATUS.ERROR;
+    result.message = e.toString();
+    return result;
+  }
+
+  let longestChunkSeconds = 0;
+  for (let i = 0; i < chunks.length; i++) {
+    // 途中で実行時間の上限に達して書きかけにならないよう、月の区切りで残り時間を確認する
+    const remainingSeconds = getRemainingSeconds();
+    const requiredSeconds = Math.max(settings.minRemainingSeconds, longestChunkSeconds * CHUNK_TIME_SAFETY_FACTOR);
+    if (remainingSeconds < requiredSeconds) {
+      suspendSync(spreadsheet, settings, chunks.slice(i), remainingSeconds);
+      result.status = RUN_STATUS.SUSPENDED;
+      result.message = `${chunks[i].startDate} 以降は次回の実行で再開`;
+      break;
+    }
+
+    if (chunks.length > 1) {
+      console.log(`--- [${i + 1}/${chunks.length}] ${chunks[i].startDate} から ${chunks[i].endDate} ---`);
+    }
+    const chunkStartedAt = Date.now();
+    const chunkResult = syncChunk(spreadsheet, sheet, dataset, settings, chunks[i]);
+    // エラーになった月より後は取得せず、次回の実行でその月から再開する
+    if (!chunkResult.success) {
+      result.status = RUN_STATUS.ERROR;
+      result.message = chunkResult.message;
+      break;
+    }
+    result.rowCount += chunkResult.rowCount;
+    result.startDate = result.startDate || chunks[i].startDate;
+    result.endDate = chunks[i].endDate;
+    longestChunkSeconds = Math.max(longestChunkSeconds, (Date.now() - chunkStartedAt) / 1000);
+  }
+  if (result.rowCount > 0) {
+    warnMixedEnumValues(sheet, dataset);
+  }
+  return result;
+}
+
+/**
+ * キャンペーン名に指定した文字列を含む行だけを残す
+ * @param {Object} dataset - データセット定義（headers に「キャンペーン名」を含むこと）
+ * @param {Array<Array>} rows - 取得した行
+ * @param {string} campaignFilter - キャンペーン名に含む文字列（空欄なら絞り込まない）
+ */
+function filterRowsByCampaign(dataset, rows, campaignFilter) {
+  if (!campaignFilter) {
+    return rows;
+  }
+  const campaignIndex = dataset.headers.indexOf('キャンペーン名');
+  const filtered = rows.filter(row => String(row[campaignIndex]).indexOf(campaignFilter) !== -1);
+  console.log(`キャンペーン名に「${campaignFilter}」を含む ${filtered.length}件 を記録します（対象外: ${rows.length - filtered.length}件）。`);
+  return filtered;
+}
+
+/**
+ * 残り実行時間が足りないため、未取得の期間を「中断」として実行履歴に記録する
+ * 取得済みの月は「成功」として記録されているため、次回の実行では残りの月から再開します。
+ * @param {GoogleAppsScript.Spreadsheet.Spreadsheet} spreadsheet - 対象のスプレッドシート
+ * @param {Object} settings - 実行設定
+ * @param {Array<Object>} remainingChunks - まだ取得していない期間の一覧
+ * @param {number} remainingSeconds - 残り実行時間（秒）
+ */
+function suspendSync(spreadsheet, settings, remainingChunks, remainingSeconds) {
+  const first = remainingChunks[0];
+  const last = remainingChunks[remainingChunks.length - 1];
+  console.log(`残り実行時間が少ないため（残り${Math.floor(remainingSeconds)}秒）、${first.startDate} 以降の${remainingChunks.length}か月分は次回の実行で取得します。`);
+  recordSyncRun(spreadsheet, {
+    sheetName: settings.sheetName,
+    job: first.job,
+    startDate: first.startDate,
+    endDate: last.endDate,
+    status: RUN_STATUS.SUSPENDED,
+    message: `残り${remainingChunks.length}か月分は次回の実行で再開`
+  });
+}
+
+/**
+ * スクリプトの残り実行時間（秒）を返す
+ */
+function getRemainingSeconds() {
+  return AdsApp.getExecutionInfo().getRemainingTime();
+}
+
+/**
+ * 1つの期間（通常は1か月分）を取得して書き込み、実行履歴に記録する
+ * @param {GoogleAppsScript.Spreadsheet.Spreadsheet} spreadsheet - 対象のスプレッドシート
+ * @param {GoogleAppsScript.Spreadsheet.Sheet} sheet - 記録先のシート
+ * @param {Object} dataset - データセット定義
+ * @param {Object} settings - 実行設定
+ * @param {Object} range - 取得期間（buildRange() の結果）
+ * @returns {Object} 成功したかどうか（success）と、書き込んだ件数（rowCount）またはエラー内容（message）
+ */
+function syncChunk(spreadsheet, sheet, dataset, settings, range) {
+  try {
+    const fetchedRows = translateEnumRows(dataset, dataset.fetchRows(range));
+    reportUnmappedEnums(spreadsheet, settings.sheetName);
+    const rows = filterRowsByCampaign(dataset, fetchedRows, settings.campaignFilter);
+    assertRowsMatchSchema(dataset, rows);
+    const messages = [];
+    if (rows.length === 0 && !range.restate) {
+      console.log('期間内に記録対象のデータはありませんでした。');
+    } else {
+      if ((settings.writeMode === 'upsert' && dataset.keyHeaders) || range.restate) {
+        const result = upsertRows(sheet, dataset, rows, range.restate);
+        console.log(`${rows.length}件のデータを書き込みました（うち既存データの置き換え: ${result.replacedCount}件）。`);
+        if (result.replacedCount > 0) {
+          messages.push(`置き換え: ${result.replacedCount}件`);
+        }
+        if (range.restate) {
+          const before = Math.round(result.conversionsBefore * 100) / 100;
+          const after = Math.round(result.conversionsAfter * 100) / 100;
+          const diff = Math.round((after - before) * 100) / 100;
+          const diffText = `${range.restate.startDate}〜${range.restate.endDate} のコンバージョン数: ` +
+            `${before} → ${after}（${diff >= 0 ? '+' : ''}${diff}）`;
+          console.log('再取得により ' + diffText);
+          messages.push(diffText);
+        }
+      } else {
+        appendRows(sheet, rows);
+        console.log(`${rows.length}件のデータを追記しました。`);
+      }
+
+      sortByDate(sheet);
+      console.log('シート全体を日付順に並べ替えました。');
+    }
+
+    // 書き込みが完了してから記録する（0件の期間も取得済みとして扱う）
+    recordSyncRun(spreadsheet, {
+      sheetName: settings.sheetName,
+      job: range.job,
+      startDate: range.startDate,
+      endDate: range.endDate,
+      rowCount: rows.length,
+      status: RUN_STATUS.SUCCESS,
+      message: messages.join(' / ')
+    });
+    return { success: true, rowCount: rows.length };
+
+  } catch (e) {
+    console.error('スクリプトの実行中にエラーが発生しました: ' + e.toString());
+    console.error('エラー詳細: ' + e.stack);
+    recordSyncRun(spreadsheet, {
+      sheetName: settings.sheetName,
+      job: range.job,
+      startDate: range.startDate,
+      endDate: range.endDate,
+      status: RUN_STATUS.ERROR,
+      message: e.toString()
+    });
+    return { success: false, message: e.toString() };
+  }
+}
+
+// --------------------------------------------------------------------------------
+// 取得期間の決定
+// --------------------------------------------------------------------------------
+
+/**
+ * 実行モードとシートの記録状況から、取得すべき期間を決定する
+ * daily 以外のモードでは、続きから再開するための識別名（job）を付けて返す
+ * @param {GoogleAppsScript.Spreadsheet.Sheet} sheet - 記録先のシート
+ * @param {Object} settings - 実行設定
+ * @param {string} timezone - アカウントのタイムゾーン
+ * @returns {Object|null} 取得期間（取得対象がない場合は null）
+ */
+function resolveSyncRange(sheet, settings, timezone) {
+  const today = todayString(timezone);
+
+  if (settings.mode !== 'daily') {
+    const endOffset = settings.endOffsetDays === null ? 2 : settings.endOffsetDays;
+    const latestDate = addDays(today, -endOffset);
+    let startDate;
+    let endDate = latestDate;
+    let job;
+
+    if (settings.mode === 'year') {
+      startDate = `${settings.targetYear}-01-01`;
+      endDate = `${settings.targetYear}-12-31`;
+      job = `年指定 ${settings.targetYear}`;
+    } else if (settings.mode === 'range') {
+      startDate = toDateString(settings.startDate, timezone);
+      endDate = settings.endDate ? toDateString(settings.endDate, timezone) : latestDate;
+      if (!startDate || !endDate) {
+        throw new Error('期間指定（range）では、開始日・終了日を yyyy-MM-dd 形式で指定してください。');
+      }
+      job = `期間指定 ${startDate}〜${settings.endDate ? endDate : '最新'}`;
+    } else if (settings.mode === 'all') {
+      startDate = findAccountStartDate(latestDate);
+      if (!startDate) {
+        console.log('このアカウントには取得できる配信実績がありません。');
+        return null;
+      }
+      console.log(`配信開始日: ${startDate}`);
+      job = '全期間';
+    } else {
+      throw new Error(`実行モード「${settings.mode}」には対応していません（daily / range / year / all）。`);
+    }
+
+    if (endDate > latestDate) {
+      endDate = latestDate;
+    }
+    if (startDate > endDate) {
+      console.log(`指定された期間(${startDate} から)のデータは、まだ取得できる範囲に達していません。`);
+      return null;
+    }
+    const range = buildRange(startDate, endDate, timezone);
+    range.job = job;
+    return range;
+  }
+
+  const endOffset = settings.endOffsetDays === null ? 1 : settings.endOffsetDays;
+  const endDate = addDays(today, -endOffset);
+  // 実行履歴の記録を優先し、履歴がない（導入前から使っている）シートは最終日付から判断する
+  const lastDate = getSyncWatermark(sheet.getParent(), sheet.getName()) || getLastStoredDate(sheet);
+
+  if (!lastDate) {
+    console.log(`データがないため、${settings.initialDays}日分のデータを取得します。`);
+    return buildRange(addDays(endDate, -(settings.initialDays - 1)), endDate, timezone);
+  }
+
+  let startDate = addDays(lastDate, 1);
+  let restate = null;
+
+  // コンバージョンは後から計上されるため、取得済みの直近の日も取り直して置き換える
+  if (settings.lookbackDays > 0) {
+    const restateStart = addDays(endDate, -(settings.lookbackDays - 1));
+    const restateEnd = lastDate < endDate ? lastDate : endDate;
+    if (restateStart <= restateEnd) {
+      restate = { startDate: restateStart, endDate: restateEnd };
+      if (restateStart < startDate) {
+        startDate = restateStart;
+      }
+      console.log(`直近${settings.lookbackDays}日分のうち取得済みの ${restateStart} から ${restateEnd} を再取得して置き換えます。`);
+    }
+  }
+
+  if (startDate > endDate) {
+    console.log('データは既に最新です。処理を終了します。');
+    return null;
+  }
+  console.log('通常実行：未取得の期間のデータを取得します。');
+  const range = buildRange(startDate, endDate, timezone);
+  range.restate = restate;
+  return range;
+}
+
+/**
+ * 取得期間を月ごとに分割し、同じ job で取得済みの月を除いた一覧を返す
+ * @param {GoogleAppsScript.Spreadsheet.Spreadsheet} spreadsheet - 対象のスプレッドシート
+ * @param {string} sheetName - 記録先のシート名
+ * @param {Object} range - resolveSyncRange() の結果
+ * @returns {Array<Object>} 取得する期間の一覧（古い順）
+ */
+function planChunks(spreadsheet, sheetName, range) {
+  const completed = range.job ? getCompletedChunks(spreadsheet, sheetName, range.job) : new Set();
+  const chunks = [];
+
+  let chunkStart = range.startDate;
+  while (chunkStart <= range.endDate) {
+    const monthEnd = addDays(addMonths(chunkStart.slice(0, 8) + '01', 1), -1);
+    const chunkEnd = monthEnd < range.endDate ? monthEnd : range.endDate;
+
+    if (completed.has(`${chunkStart}|${chunkEnd}`)) {
+      console.log(`${chunkStart} から ${chunkEnd} は取得済みのためスキップします。`);
+    } else {
+      const chunk = buildRange(chunkStart, chunkEnd, range.timezone);
+      chunk.job = range.job;
+      // 再取得して置き換える期間も、月ごとの範囲に合わせて切り出す
+      if (range.restate && range.restate.startDate <= chunkEnd && range.restate.endDate >= chunkStart) {
+        chunk.restate = {
+          startDate: range.restate.startDate > chunkStart ? range.restate.startDate : chunkStart,
+          endDate: range.restate.endDate < chunkEnd ? range.restate.endDate : chunkEnd
+        };
+      }
+      chunks.push(chunk);
+    }
+    chunkStart = addDays(chunkEnd, 1);
+  }
+  return chunks;
+}
+
+/**
+ * アカウントで最初に表示回数が発生した日（配信開始日）を探す
+ * @param {string} endDate - 探す範囲の終了日（yyyy-MM-dd）
+ * @returns {string|null} yyyy-MM-dd 形式の日付（実績がない場合は null）
+ */
+function findAccountStartDate(endDate) {
+  const query = `
+    SELECT segments.date, metrics.impressions
+    FROM customer
+    WHERE segments.date BETWEEN '${ACCOUNT_HISTORY_FROM}' AND '${endDate}'
+      AND metrics.impressions > 0
+    ORDER BY segments.date ASC
+    LIMIT 1
+  `;
+  const rows = reportRows(query);
+  return rows.length > 0 ? rows[0]['segments.date'] : null;
+}
+
+/**
+ * クエリで使いやすい形式の取得期間オブジェクトを作る
+ * @param {string} startDate - 開始日（yyyy-MM-dd）
+ * @param {string} endDate - 終了日（yyyy-MM-dd）
+ * @param {string} timezone - アカウントのタイムゾーン
+ * @returns {Object} startDate / endDate は GAQL 用、during は AWQL の DURING 句用
+ */
+function buildRange(startDate, endDate, timezone) {
+  return {
+    startDate: startDate,
+    endDate: endDate,
+    during: startDate.replace(/-/g, '') + ',' + endDate.replace(/-/g, ''),
+    timezone: timezone
+  };
+}
+
+/**
+ * シートに記録されている最も新しい日付を返す
+ * @param {GoogleAppsScript.Spreadsheet.Sheet} sheet - 対象シート
+ * @returns {string|null} yyyy-MM-dd 形式の日付（データがない場合は null）
+ */
+function getLastStoredDate(sheet) {
+  const lastRow = sheet.getLastRow();
+  if (lastRow <= 1) {
+    return null;
+  }
+  // シートの日付セルはスプレッドシートのタイムゾーンで解釈する
+  const sheetTimezone = sheet.getParent().getSpreadsheetTimeZone();
+  const values = sheet.getRange(2, 1, lastRow - 1, 1).getValues();
+
+  let lastDate = null;
+  values.forEach(row => {
+    const date = toDateString(row[0], sheetTimezone);
+    if (date && (!lastDate || date > lastDate)) {
+      lastDate = date;
+    }
+  });
+  return lastDate;
+}
+
+// --------------------------------------------------------------------------------
+// 日付の補助関数（日付は yyyy-MM-dd の文字列で扱う）
+// --------------------------------------------------------------------------------
+
+/**
+ * 指定したタイムゾーンでの今日の日付を返す
+ */
+function todayString(timezone) {
+  return Utilities.formatDate(new Date(), timezone, 'yyyy-MM-dd');
+}
+
+/**
+ * yyyy-MM-dd 形式の日付に日数を加算する（実行環境のタイムゾーンに左右されないようUTCで計算）
+ */
+function addDays(dateString, days) {
+  const parts = dateString.split('-').map(Number);
+  const date = new Date(Date.UTC(parts[0], parts[1] - 1, parts[2] + days));
+  return date.toISOString().slice(0, 10);
+}
+
+/**
+ * yyyy-MM-dd 形式の日付に月数を加算する（月末の繰り上がりは考慮しないため、1日の日付に対して使う）
+ */
+function addMonths(dateString, months) {
+  const parts = dateString.split('-').map(Number);
+  const date = new Date(Date.UTC(parts[0], parts[1] - 1 + months, parts[2]));
+  return date.toISOString().slice(0, 10);
+}
+
+/**
+ * セルの値（Date または日付文字列）を yyyy-MM-dd 形式に変換する
+ * @returns {string|null} 日付として解釈できない場合は null
+ */
+function toDateString(value, timezone) {
+  if (value instanceof Date) {
+    return isNaN(value.getTime()) ? null : Utilities.formatDate(value, timezone, 'yyyy-MM-dd');
+  }
+  const digits = String(value).replace(/[^0-9]/g, '');
+  if (digits.length !== 8) {
+    return null;
+  }
+  return `${digits.slice(0, 4)}-${digits.slice(4, 6)}-${digits.slice(6, 8)}`;
+}
+
+// --------------------------------------------------------------------------------
+// スプレッドシート操作
+// --------------------------------------------------------------------------------
+
+/**
+ * URLを確認してスプレッドシートを開く
+ */
+function openSpreadsheet(spreadsheetUrl) {
+  if (!spreadsheetUrl || spreadsheetUrl.indexOf('https://docs.google.com/spreadsheets/d/') === -1) {
+    throw new Error('スプレッドシートのURLを正しく設定してください。');
+  }
+  return SpreadsheetApp.openByUrl(spreadsheetUrl);
+}
+
+/**
+ * シートを取得し、存在しなければ作成する
+ */
+function getOrCreateSheet(spreadsheet, sheetName) {
+  return spreadsheet.getSheetByName(sheetName) || spreadsheet.insertSheet(sheetName);
+}
+
+/**
+ * シートの末尾にデータを一括で追記する
+ */
+function appendRows(sheet, rows) {
+  sheet.getRange(sheet.getLastRow() + 1, 1, rows.length, rows[0].length).setValues(rows);
+}
+
+/**
+ * キー列（dataset.keyHeaders）が同じ既存行を取り除いてから、新しい行を書き込む
+ * 同じ期間を再取得しても行が重複しないため、過去データの取り直しを安全に行えます。
+ * restate を指定した場合は、その期間の既存行をキーに関係なくすべて取り除きます
+ * （再取得で0件になった組み合わせの古い行を残さないため）。
+ * ただし dataset.filtersCurrentStatus が true のデータセット（キャンペーンの現在のステータスなどで取得対象を絞り込むもの）は、
+ * 停止・削除したキャンペーンの過去の行が再取得で返らないため、期間内でもキーが一致する行だけを置き換えます。
+ * 分割（セグメント.go）や後から追加したキー列（addedKey）より前に記録した行は、その列が空欄のため、それ以外のキーが同じ新しい行で置き換えます。
+ * @param {GoogleAppsScript.Spreadsheet.Sheet} sheet - 対象シート
+ * @param {Object} dataset - データセット定義（headers, keyHeaders, filtersCurrentStatus）
+ * @param {Array<Array>} rows - 書き込む行
+ * @param {Object} [restate] - 置き換える期間（startDate, endDate）
+ * @returns {Object} 置き換えた既存行の数（replacedCount）と、restate 期間のコンバージョン数（conversionsBefore / conversionsAfter）
+ */
+function upsertRows(sheet, dataset, rows, restate) {
+  const keyIndexes = (dataset.keyHeaders || []).map(header => {
+    const index = dataset.headers.indexOf(header);
+    if (index === -1) {
+      throw new Error(`キー列「${header}」がヘッダーに見つかりません。`);
+    }
+    return index;
+  });
+  const conversionHeader = CONVERSION_HEADERS.filter(header => dataset.headers.indexOf(header) !== -1)[0];
+  const conversionIndex = conversionHeader ? dataset.headers.indexOf(conversionHeader) : -1;
+
+  // 区分値のキー列はEnum値に揃えて比べる（表記を変更する前に記録した行も、同じキーとして置き換えるため）
+  const enumKinds = dataset.columns.map(column => column.enum);
+  const sheetTimezone = sheet.getParent().getSpreadsheetTimeZone();
+  const keyValue = (row, i) => {
+    const value = row[i];
+    if (value instanceof Date) {
+      return Utilities.formatDate(value, sheetTimezone, 'yyyy-MM-dd');
+    }
+    return enumKinds[i] ? String(toEnumCode(enumKinds[i], value)) : String(value);
+  };
+  const buildKey = row => keyIndexes.map(i => keyValue(row, i)).join('|');
+  const isRestated = row => {
+    const date = toDateString(row[0], sheetTimezone);
+    return !!restate && !!date && date >= restate.startDate && date <= restate.endDate;
+  };
+  const sumConversions = targetRows => conversionIndex === -1 ? 0 :
+    targetRows.filter(isRestated).reduce((total, row) => total + toNumber(row[conversionIndex]), 0);
+
+  const result = { replacedCount: 0, conversionsBefore: 0, conversionsAfter: sumConversions(rows) };
+
+  const lastRow = sheet.getLastRow();
+  if (lastRow <= 1) {
+    if (rows.length > 0) {
+      appendRows(sheet, rows);
+    }
+    return result;
+  }
+
+  const newKeys = new Set(keyIndexes.length > 0 ? rows.map(buildKey) : []);
+  // 分割や後から追加したキー列（addedKey）を追加する前の行（その列が空欄）は、空欄の列を除いたキーで突き合わせる
+  const isBlank = value => value === '' || value === null || value === undefined;
+  const segmentIndexes = (dataset.segmentHeaders || []).map(header => dataset.headers.indexOf(header));
+  const laterKeyIndexes = keyIndexes.filter(i => segmentIndexes.indexOf(i) !== -1 || dataset.columns[i].addedKey);
+  const partialKeySets = {};
+  const isUnsegmentedMatch = row => {
+    const blankIndexes = laterKeyIndexes.filter(i => isBlank(row[i]));
+    if (blankIndexes.length === 0) {
+      return false;
+    }
+    const partialKey = targetRow => keyIndexes.filter(i => blankIndexes.indexOf(i) === -1).map(i => keyValue(targetRow, i)).join('|');
+    const pattern = blankIndexes.join(',');
+    if (!partialKeySets[pattern]) {
+      partialKeySets[pattern] = new Set(rows.map(partialKey));
+    }
+    return partialKeySets[pattern].has(partialKey(row));
+  };
+  const width = Math.max(sheet.getLastColumn(), rows.length > 0 ? rows[0].length : 0);
+  const existingRows = sheet.getRange(2, 1, lastRow - 1, width).getValues();
+  const restatesAllRows = !dataset.filtersCurrentStatus;
+  const isReplaced = row => (restatesAllRows && isRestated(row)) || (keyIndexes.length > 0 && newKeys.has(buildKey(row))) || isUnsegmentedMatch(row);
+  const keptRows = existingRows.filter(row => !isReplaced(row));
+  result.replacedCount = existingRows.length - keptRows.length;
+  // 置き換えた行だけを比べる（残した行を減少として数えないため）
+  result.conversionsBefore = sumConversions(existingRows.filter(isReplaced));
+
+  if (result.replacedCount === 0) {
+    if (rows.length > 0) {
+      appendRows(sheet, rows);
+    }
+    return result;
+  }
+
+  // 既存行を入れ替えるため、データ部分を書き直す（列数はシートの幅に揃える）
+  const mergedRows = keptRows.concat(rows.map(row => {
+    const padded = row.slice();
+    while (padded.length < width) {
+      padded.push('');
+    }
+    return padded;
+  }));
+  sheet.getRange(2, 1, lastRow - 1, width).clearContent();
+  if (mergedRows.length > 0) {
+    sheet.getRange(2, 1, mergedRows.length, width).setValues(mergedRows);
+  }
+  return result;
+}
+
+/**
+ * ヘッダー行を除いたシート全体を、1列目（日付）の昇順で並べ替える
+ */
+function sortByDate(sheet) {
+  if (sheet.getLastRow() > 1) {
+    const dataRange = sheet.getRange(2, 1, sheet.getLastRow() - 1, sheet.getLastColumn());
+    dataRange.sort({column: 1, ascending: true});
+  }
+}
+
+// --------------------------------------------------------------------------------
+// レポート取得の補助関数
+// --------------------------------------------------------------------------------
+
+/**
+ * AdsApp.report() の結果をすべて配列に読み込む
+ * @param {string} query - AWQL または GAQL のクエリ
+ * @returns {Array<Object>} レポートの行
+ */
+function reportRows(query) {
+  const rows = [];
+  const iterator = AdsApp.report(query).rows();
+  while (iterator.hasNext()) {
+    rows.push(iterator.next());
+  }
+  return rows;
+}
+
+/**
+ * レポート（AWQL / GAQL）の行を、フィールドの並び順どおりの配列に変換する
+ * @param {Array<Object>} rows - reportRows() の結果
+ * @param {Array<string>} fields - SELECT したフィールド名（GAQLは 'metrics.clicks' などの完全な名前）
+ * @param {Object} transforms - フィールド名ごとの変換関数（例: { Cost: toNumber }）
+ * @returns {Array<Array>} シートに書き込む行
+ */
+function reportRowsToValues(rows, fields, transforms) {
+  return rows.map(row => fields.map(fieldName => {
+    let value = row[fieldName];
+    if (typeof value === 'number' && !isFinite(value)) {
+      value = 0;
+    }
+    if (transforms && transforms[fieldName]) {
+      value = transforms[fieldName](value);
+    }
+    return value;
+  }));
+}
+
+/**
+ * カンマ区切りの文字列などを数値に変換する（単位変換はしない）
+ */
+function toNumber(value) {
+  if (typeof value === 'number') {
+    return value;
+  }
+  return parseFloat(String(value).replace(/,/g, '')) || 0;
+}
+
+/**
+ * マイクロ単位の金額を円に変換する
+ */
+function microsToYen(value) {
+  return (parseFloat(value) || 0) / 1000000;
+}
+
+/**
+ * 地域ターゲティングの条件IDから、地域名（または半径指定の住所）を特定する
+ * @param {Array<string>} criterionIds - campaign_criterion.criterion_id の一覧
+ * @returns {Map<string, string>} 条件ID → 地域名
+ */
+function fetchLocationNames(criterionIds) {
+  const locationInfoMap = new Map();
+  if (criterionIds.length === 0) {
+    return locationInfoMap;
+  }
+
+  const criteriaQuery = `
+    SELECT
+      campaign_criterion.criterion_id,
+      campaign_criterion.type,
+      campaign_criterion.location.geo_target_constant,
+      campaign_criterion.proximity.radius,
+      campaign_criterion.proximity.radius_units,
+      campaign_criterion.proximity.address.street_address,
+      campaign_criterion.proximity.address.city_name
+    FROM
+      campaign_criterion
+    WHERE
+      campaign_criterion.criterion_id IN (${criterionIds.join(',')})
+  `;
+
+  const geoTargetIdsToLookup = new Set();
+  const tempCriterionInfo = new Map();
+
+  for (const row of reportRows(criteriaQuery)) {
+    const id = row['campaign_criterion.criterion_id'];
+    const type = row['campaign_criterion.type'];
+
+    if (type === 'PROXIMITY') {
+      const addressParts = [
+        row['campaign_criterion.proximity.address.city_name'],
+        row['campaign_criterion.proximity.address.street_address']
+      ].filter(Boolean).join(' '); // 住所を結合
+      const radius = row['campaign_criterion.proximity.radius'];
+      const units = row['campaign_criterion.proximity.radius_units'];
+      locationInfoMap.set(id, `[半径] ${addressParts} (${radius} ${units})`);
+    } else if (type === 'LOCATION') {
+      const geoTarget = row['campaign_criterion.location.geo_target_constant'];
+      if (geoTarget && geoTarget.startsWith('geoTargetConstants/')) {
+        geoTargetIdsToLookup.add(`'${geoTarget}'`);
+        tempCriterionInfo.set(id, { geoTarget: geoTarget });
+      }
+    }
+  }
+
+  if (geoTargetIdsToLookup.size > 0) {
+    const geoQuery = `
+      SELECT geo_target_constant.name, geo_target_constant.resource_name
+      FROM geo_target_constant
+      WHERE geo_target_constant.resource_name IN (${Array.from(geoTargetIdsToLookup).join(',')})
+    `;
+    const geoNameMap = new Map();
+    for (const row of reportRows(geoQuery)) {
+      geoNameMap.set(row['geo_target_constant.resource_name'], row['geo_target_constant.name']);
+    }
+
+    for (const [id, info] of tempCriterionInfo.entries()) {
+      if (geoNameMap.has(info.geoTarget)) {
+        locationInfoMap.set(id, geoNameMap.get(info.geoTarget));
+      }
+    }
+  }
+
+  return locationInfoMap;
+}
@@ -0,0 +1,129 @@
+/**
+ * 【共通ライブラリ・実行履歴】
+ * シートごとの「どこまで取得済みか（ウォーターマーク）」と、各実行の取得期間・件数・結果を記録します。
+ * シートの最終行は並べ替えや手入力で簡単に変わるため、次回の取得開始日はこの実行履歴から決定します。
+ * ★「同期処理.go」と一緒に貼り付けてください。
+ */
+
+// 実行履歴を記録するシート名（自動作成されます）
+const HISTORY_SHEET_NAME = '実行履歴';
+
+const HISTORY_HEADERS = ['記録日時', 'シート名', '開始日', '終了日', '件数', 'ステータス', 'メッセージ'];
+
+// 実行結果のステータス（ウォーターマークとして扱うのは「成功」の行のみ）
+const RUN_STATUS = {
+  SUCCESS: '成功',
+  ERROR: 'エラー',
+  SUSPENDED: '中断' // 実行時間が足りず、次回の実行に持ち越した期間
+};
+
+/**
+ * 実行履歴シートを取得し、存在しなければ作成する
+ * @param {GoogleAppsScript.Spreadsheet.Spreadsheet} spreadsheet - 対象のスプレッドシート
+ * @returns {GoogleAppsScript.Spreadsheet.Sheet} 実行履歴シート
+ */
+function getHistorySheet(spreadsheet) {
+  let historySheet = spreadsheet.getSheetByName(HISTORY_SHEET_NAME);
+  if (!historySheet) {
+    console.log('実行履歴シートが見つからないため、作成します。');
+    historySheet = spreadsheet.insertSheet(HISTORY_SHEET_NAME);
+    historySheet.getRange(1, 1, 1, HISTORY_HEADERS.length).setValues([HISTORY_HEADERS]).setFontWeight('bold');
+    // 日付が自動変換されないよう、開始日・終了日は文字列として保存する
+    historySheet.getRange('C:D').setNumberFormat('@');
+  }
+  return historySheet;
+}
+
+/**
+ * 指定したシートについて、途切れずに取得済みの最後の日を返す
+ * 日次更新（job なし）の成功した終了日を起点にし、そこから隙間なく続く期間指定・年指定の取得だけを加える。
+ * 先の期間だけを取得した job の終了日を使うと、その間の日が取得されないまま飛ばされるため。
+ * @param {GoogleAppsScript.Spreadsheet.Spreadsheet} spreadsheet - 対象のスプレッドシート
+ * @param {string} sheetName - データを記録しているシート名
+ * @returns {string|null} yyyy-MM-dd 形式の日付（記録がない場合は null）
+ */
+function getSyncWatermark(spreadsheet, sheetName) {
+  const historySheet = spreadsheet.getSheetByName(HISTORY_SHEET_NAME);
+  if (!historySheet || historySheet.getLastRow() <= 1) {
+    return null;
+  }
+  const sheetTimezone = spreadsheet.getSpreadsheetTimeZone();
+  const values = historySheet.getRange(2, 1, historySheet.getLastRow() - 1, HISTORY_HEADERS.length).getValues();
+
+  let watermark = null;
+  const runs = [];
+  values.forEach(row => {
+    if (row[1] !== sheetName || row[5] !== RUN_STATUS.SUCCESS) {
+      return;
+    }
+    const startDate = toDateString(row[2], sheetTimezone);
+    const endDate = toDateString(row[3], sheetTimezone);
+    if (!startDate || !endDate) {
+      return;
+    }
+    runs.push({ startDate: startDate, endDate: endDate });
+    const isDaily = String(row[6]).indexOf('[') !== 0;
+    if (isDaily && (!watermark || endDate > watermark)) {
+      watermark = endDate;
+    }
+  });
+  if (runs.length === 0) {
+    return null;
+  }
+
+  runs.sort((a, b) => (a.startDate < b.startDate ? -1 : a.startDate > b.startDate ? 1 : 0));
+  runs.forEach(run => {
+    // 日次更新の記録がなければ、最も古い取得から始まる連続した期間を使う
+    if (!watermark) {
+      watermark = run.endDate;
+    } else if (run.startDate <= addDays(watermark, 1) && run.endDate > watermark) {
+      watermark = run.endDate;
+    }
+  });
+  return watermark;
+}
+
+/**
+ * 実行結果を実行履歴シートに1行追記する
+ * @param {GoogleAppsScript.Spreadsheet.Spreadsheet} spreadsheet - 対象のスプレッドシート
+ * job を指定した場合は、メッセージの先頭に「[job]」を付けて、続きから再開する際の目印にする
+ * @param {Object} entry - 記録内容（sheetName, job, startDate, endDate, rowCount, status, message）
+ */
+function recordSyncRun(spreadsheet, entry) {
+  const historySheet = getHistorySheet(spreadsheet);
+  const jobPrefix = entry.job ? `[${entry.job}] ` : '';
+  historySheet.appendRow([
+    new Date(),
+    entry.sheetName,
+    entry.startDate || '',
+    entry.endDate || '',
+    entry.rowCount || 0,
+    entry.status,
+    (jobPrefix + (entry.message || '')).trim()
+  ]);
+}
+
+/**
+ * 指定した job で成功済みの期間（開始日|終了日）の一覧を返す
+ * @param {GoogleAppsScript.Spreadsheet.Spreadsheet} spreadsheet - 対象のスプレッドシート
+ * @param {string} sheetName - データを記録しているシート名
+ * @param {string} job - 期間指定や年指定の取得を識別する名前（例：「年指定 2024」）
+ * @returns {Set<string>} 「yyyy-MM-dd|yyyy-MM-dd」形式の文字列の集合
+ */
+function getCompletedChunks(spreadsheet, sheetName, job) {
+  const completed = new Set();
+  const historySheet = spreadsheet.getSheetByName(HISTORY_SHEET_NAME);
+  if (!historySheet || historySheet.getLastRow() <= 1) {
+    return completed;
+  }
+  const sheetTimezone = spreadsheet.getSpreadsheetTimeZone();
+  const values = historySheet.getRange(2, 1, historySheet.getLastRow() - 1, HISTORY_HEADERS.length).getValues();
+
+  values.forEach(row => {
+    if (row[1] !== sheetName || row[5] !== RUN_STATUS.SUCCESS || String(row[6]).indexOf(`[${job}]`) !== 0) {
+      return;
+    }
+    completed.add(`${toDateString(row[2], sheetTimezone)}|${toDateString(row[3], sheetTimezone)}`);
+  });
+  return completed;
+}
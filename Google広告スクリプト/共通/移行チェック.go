@@ -0,0 +1,130 @@
+/**
+ * 【共通ライブラリ・移行チェック】
+ * 旧クエリ（AWQL）と新クエリ（GAQL）で同じ期間を取得した結果を、キー列で突き合わせて差分をログに出力します。
+ * シートには書き込まないため、記録先のデータに触れずに「移行しても数値が変わっていないこと」を確認できます。
+ * AWQLの値は「1,234」「12.34%」「< 10%」「 --」のような表記で返るため、数値に直してから比べます。
+ * ★「同期処理.go」「スキーマ.go」と一緒に貼り付けてください（呼び出しは各スクリプトの checkGaqlMigration() から）。
+ */
+
+// 数値の差がこの値以下なら一致とみなす（金額などは0.01、割合の列は0.01%までの丸めの違いを許容）
+const MIGRATION_TOLERANCE = { number: 0.01, percent: 0.0001 };
+
+// 列ごとにログへ出す、食い違った値の例の件数
+const MIGRATION_SAMPLE_LIMIT = 3;
+
+/**
+ * 新旧の取得結果を比較し、差分をログに出力する
+ * @param {Object} dataset - データセット定義（registerSchema() 済みのもの）
+ * @param {Object} range - 比較した期間（buildRange() の結果）
+ * @param {Array<Array>} legacyRows - 旧クエリの行（列定義と同じ並び）
+ * @param {Array<Array>} newRows - 新クエリの行（dataset.fetchRows() の結果）
+ * @returns {boolean} すべての行・列が一致した場合は true
+ */
+function compareMigrationRows(dataset, range, legacyRows, newRows) {
+  const keyIndexes = dataset.keyHeaders.map(header => dataset.headers.indexOf(header));
+  const toKey = row => keyIndexes.map(index => String(normalizeLegacyValue(row[index]))).join(' / ');
+  const legacyByKey = new Map(legacyRows.map(row => [toKey(row), row]));
+  const newByKey = new Map(newRows.map(row => [toKey(row), row]));
+
+  const legacyOnly = Array.from(legacyByKey.keys()).filter(key => !newByKey.has(key));
+  const newOnly = Array.from(newByKey.keys()).filter(key => !legacyByKey.has(key));
+  const lines = [
+    `【移行チェック】${range.startDate}〜${range.endDate}`,
+    `行数: AWQL ${legacyRows.length}行 / GAQL ${newRows.length}行（AWQLのみ ${legacyOnly.length}行 / GAQLのみ ${newOnly.length}行）`
+  ];
+  if (legacyOnly.length > 0) {
+    lines.push(`  AWQLのみの行の例: ${legacyOnly.slice(0, MIGRATION_SAMPLE_LIMIT).join(' | ')}`);
+  }
+  if (newOnly.length > 0) {
+    lines.push(`  GAQLのみの行の例: ${newOnly.slice(0, MIGRATION_SAMPLE_LIMIT).join(' | ')}`);
+  }
+
+  // 両方にある行を列ごとに比べる（数値の列は合計も表示する）
+  let mismatchedColumns = 0;
+  lines.push('列ごとの比較:');
+  dataset.columns.forEach((column, index) => {
+    let mismatches = 0;
+    let legacyTotal = 0;
+    let newTotal = 0;
+    const samples = [];
+    const tolerance = MIGRATION_TOLERANCE[column.type] || 0;
+    legacyByKey.forEach((legacyRow, key) => {
+      const newRow = newByKey.get(key);
+      if (!newRow) {
+        return;
+      }
+      const before = normalizeLegacyValue(legacyRow[index]);
+      const after = normalizeLegacyValue(newRow[index]);
+      if (typeof before === 'number' && typeof after === 'number') {
+        legacyTotal += before;
+        newTotal += after;
+      }
+      if (!isSameMigrationValue(before, after, tolerance)) {
+        mismatches++;
+        if (samples.length < MIGRATION_SAMPLE_LIMIT) {
+          samples.push(`${key}: 「${legacyRow[index]}」→「${newRow[index]}」`);
+        }
+      }
+    });
+
+    const total = column.type === 'number' ? `（合計 AWQL ${roundForLog(legacyTotal)} / GAQL ${roundForLog(newTotal)}）` : '';
+    if (mismatches === 0) {
+      lines.push(`  ${column.label}: 一致${total}`);
+      return;
+    }
+    mismatchedColumns++;
+    lines.push(`  ${column.label}: ${mismatches}行で不一致${total}`);
+    samples.forEach(sample => lines.push(`    例 ${sample}`));
+  });
+
+  const matched = legacyOnly.length === 0 && newOnly.length === 0 && mismatchedColumns === 0;
+  lines.push(matched ? '結果: 新旧の結果はすべて一致しました。' :
+    `結果: 差分があります（行の過不足 ${legacyOnly.length + newOnly.length}行 / 不一致の列 ${mismatchedColumns}列）。上記の内容を確認してください。`);
+  console.log(lines.join('\n'));
+  return matched;
+}
+
+/**
+ * AWQLの表記（カンマ区切り・パーセント・「< 10%」・「 --」）を、GAQLと比べられる値に変換する
+ * 「< 10%」「> 90%」は、GAQLが同じ状態を返すときの値（0.0999 / 0.9001）に揃えます。
+ */
+function normalizeLegacyValue(value) {
+  if (typeof value !== 'string') {
+    return value;
+  }
+  const text = value.trim();
+  if (text === '--' || text === '') {
+    return '';
+  }
+  if (text === '< 10%') {
+    return 0.0999;
+  }
+  if (text === '> 90%') {
+    return 0.9001;
+  }
+  const percent = text.match(/^(-?[\d,]*\.?\d+)%$/);
+  if (percent) {
+    return parseFloat(percent[1].replace(/,/g, '')) / 100;
+  }
+  if (/^-?[\d,]*\.?\d+$/.test(text)) {
+    return parseFloat(text.replace(/,/g, ''));
+  }
+  return text;
+}
+
+/**
+ * 変換後の新旧の値が一致するかを判定する（数値は tolerance までの差を許容）
+ */
+function isSameMigrationValue(before, after, tolerance) {
+  if (typeof before === 'number' && typeof after === 'number') {
+    return Math.abs(before - after) <= tolerance + 1e-9;
+  }
+  return String(before) === String(after);
+}
+
+/**
+ * ログに出す合計値を小数点以下2桁に丸める
+ */
+function roundForLog(value) {
+  return Math.round(value * 100) / 100;
+}
@@ -0,0 +1,193 @@
+/**
+ * 【共通ライブラリ・設定シート】
+ * 記録先スプレッドシートの「設定」シートから、各データ取得スクリプトの設定値を読み込みます。
+ * スクリプト冒頭の定数（MODE・TARGET_YEAR など）は「設定」シートに値がないときの既定値として使われるため、
+ * 取得方法や対象年の変更は、スクリプトを編集せずにシート上で行えます。
+ * 読み込んだ値はデータの取得を始める前にすべて検証し、不備があれば該当する項目をまとめてログに出力して終了します。
+ *
+ * 「設定」シートの形式（1行目は見出し）
+ *   A列: データ（各スクリプトの SHEET_NAME。空欄ならすべてのスクリプトに適用）
+ *   B列: 項目（SYNC_CONFIG_ITEMS の key）
+ *   C列: 値
+ *   D列: メモ（自由記入・読み込みません）
+ * 同じ項目が両方にある場合は、データを指定した行の値を優先します。
+ * ※Google広告スクリプトにはスクリプトプロパティがないため、SPREADSHEET_URL だけはスクリプト冒頭でしか指定できません。
+ */
+
+// 設定値を記入するシート名（記録先スプレッドシート内・任意）
+const CONFIG_SHEET_NAME = '設定';
+
+// 「設定」シートで指定できる項目（key はスクリプト冒頭の定数名と同じ）
+const SYNC_CONFIG_ITEMS = [
+  { key: 'SHEET_NAME', setting: 'sheetName', label: '記録先のシート名', type: 'text', required: true },
+  { key: 'MODE', setting: 'mode', label: '取得方法', type: 'choice', required: true, choices: ['daily', 'range', 'year', 'all'] },
+  { key: 'START_DATE', setting: 'startDate', label: '期間指定の開始日', type: 'date' },
+  { key: 'END_DATE', setting: 'endDate', label: '期間指定の終了日', type: 'date' },
+  { key: 'TARGET_YEAR', setting: 'targetYear', label: '取得する年', type: 'year' },
+  { key: 'LOOKBACK_DAYS', setting: 'lookbackDays', label: '毎回取り直す直近の日数', type: 'integer', required: true, min: 0, max: 90 },
+  { key: 'CAMPAIGN_FILTER', setting: 'campaignFilter', label: '対象キャンペーン（キャンペーン名に含む文字列）', type: 'text' },
+  { key: 'ENUM_OUTPUT', setting: 'enumOutput', label: '区分値（デバイス・マッチタイプなど）の表記', type: 'choice', choices: ['label', 'code', 'both', 'legacy'] },
+  { key: 'SEGMENTS', setting: 'segments', label: 'デバイス・時間帯・曜日での分割', type: 'list', choices: ['device', 'hour', 'dayOfWeek'] },
+  { key: 'INCLUDE_CONVERSION_DATE', setting: 'includeConversionDate', label: 'CV発生日のコンバージョン数・価値も記録', type: 'boolean' }
+];
+
+/**
+ * 「設定」シートの値を実行設定に反映し、すべての設定値を検証する
+ * @param {Object} settings - 実行設定（スクリプト冒頭の定数の値。検証後の値で上書きします）
+ * @param {Object} dataset - データセット定義
+ * @throws {Error} 不備のある項目をすべて並べたエラー
+ */
+function loadSyncConfig(settings, dataset) {
+  const url = String(settings.spreadsheetUrl || '');
+  if (url.indexOf('https://docs.google.com/spreadsheets/d/') !== 0) {
+    throw new Error(formatConfigErrors([`SPREADSHEET_URL（記録先のスプレッドシート）: 「${url}」はスプレッドシートのURLではありません。`]));
+  }
+  const spreadsheet = openSpreadsheet(url);
+  const errors = [];
+  const sheetValues = readSyncConfigSheet(spreadsheet, settings.sheetName, errors);
+  const sheetTimezone = spreadsheet.getSpreadsheetTimeZone();
+
+  SYNC_CONFIG_ITEMS.forEach(item => {
+    const fromSheet = Object.prototype.hasOwnProperty.call(sheetValues, item.key);
+    const parsed = parseSyncConfigValue(item, fromSheet ? sheetValues[item.key] : settings[item.setting], sheetTimezone);
+    if (parsed.error) {
+      errors.push(`${item.key}（${item.label}・${fromSheet ? `「${CONFIG_SHEET_NAME}」シート` : 'スクリプト'}）: ${parsed.error}`);
+      return;
+    }
+    settings[item.setting] = parsed.value;
+  });
+
+  // 項目の組み合わせの確認
+  if (settings.mode === 'range' && !settings.startDate) {
+    errors.push('MODE が \'range\' のときは START_DATE（開始日）を指定してください。');
+  }
+  if (settings.mode === 'range' && settings.startDate && settings.endDate && settings.startDate > settings.endDate) {
+    errors.push(`START_DATE（${settings.startDate}）が END_DATE（${settings.endDate}）より後の日付になっています。`);
+  }
+  if (settings.mode === 'year' && !settings.targetYear) {
+    errors.push('MODE が \'year\' のときは TARGET_YEAR（取得する年）を指定してください。');
+  }
+  if (settings.campaignFilter && dataset.headers.indexOf('キャンペーン名') === -1) {
+    errors.push('CAMPAIGN_FILTER: このデータにはキャンペーン名の列がないため、キャンペーンで絞り込めません。');
+  }
+  if (settings.includeConversionDate && !dataset.conversionDateAfter) {
+    errors.push('INCLUDE_CONVERSION_DATE: このデータにはコンバージョンアクション別の列がないため、CV発生日の列を追加できません。');
+  }
+  const unsupportedSegments = settings.segments.filter(name => (dataset.supportedSegments || []).indexOf(name) === -1);
+  if (unsupportedSegments.length > 0) {
+    errors.push(`SEGMENTS: このデータでは「${unsupportedSegments.join(', ')}」で分割できません` +
+      `（指定できる分割: ${(dataset.supportedSegments || []).join(' / ') || 'なし'}）。`);
+  }
+
+  if (errors.length > 0) {
+    throw new Error(formatConfigErrors(errors));
+  }
+  const keys = Object.keys(sheetValues);
+  if (keys.length > 0) {
+    console.log(`「${CONFIG_SHEET_NAME}」シートの値を使用します: ${keys.map(key => `${key}=${sheetValues[key]}`).join(', ')}`);
+  }
+}
+
+/**
+ * 「設定」シートから、このスクリプト（データ）に適用する値を読み込む
+ * @param {GoogleAppsScript.Spreadsheet.Spreadsheet} spreadsheet - 記録先のスプレッドシート
+ * @param {string} datasetName - スクリプトの SHEET_NAME
+ * @param {Array<string>} errors - 不明な項目名などを追加するエラーの一覧
+ * @returns {Object} 項目（key）ごとの値（シートがない場合は空のオブジェクト）
+ */
+function readSyncConfigSheet(spreadsheet, datasetName, errors) {
+  const common = {};
+  const specific = {};
+  const configSheet = spreadsheet.getSheetByName(CONFIG_SHEET_NAME);
+  if (!configSheet || configSheet.getLastRow() <= 1) {
+    return common;
+  }
+
+  const values = configSheet.getRange(2, 1, configSheet.getLastRow() - 1, 3).getValues();
+  values.forEach((row, index) => {
+    const target = String(row[0]).trim();
+    const key = String(row[1]).trim();
+    if (!key || (target && target !== datasetName)) {
+      return;
+    }
+    if (!SYNC_CONFIG_ITEMS.some(item => item.key === key)) {
+      errors.push(`「${CONFIG_SHEET_NAME}」シート${index + 2}行目: 「${key}」は不明な項目です（指定できる項目: ${SYNC_CONFIG_ITEMS.map(item => item.key).join(', ')}）。`);
+      return;
+    }
+    (target ? specific : common)[key] = row[2];
+  });
+  return Object.assign(common, specific);
+}
+
+/**
+ * 設定値を項目の種類に合わせて変換し、検証する
+ * @param {Object} item - SYNC_CONFIG_ITEMS の項目
+ * @param {*} value - スクリプトの定数、または「設定」シートのセルの値
+ * @param {string} timezone - 日付のセルを読み取るタイムゾーン
+ * @returns {Object} 変換後の値（value）、または不備の内容（error）
+ */
+function parseSyncConfigValue(item, value, timezone) {
+  const text = (value === null || value === undefined) ? '' : String(value).trim();
+  if (text === '') {
+    if (item.required) {
+      return { error: '値が入力されていません。' };
+    }
+    return { value: item.type === 'text' ? '' : item.type === 'list' ? [] : item.type === 'boolean' ? false : null };
+  }
+
+  switch (item.type) {
+    case 'choice':
+      if (item.choices.indexOf(text) === -1) {
+        return { error: `「${text}」は指定できません（${item.choices.join(' / ')} のいずれか）。` };
+      }
+      return { value: text };
+    case 'list': {
+      // カンマ区切り（「device, hour」など）。スクリプトの定数は配列でも指定できる
+      const values = (Array.isArray(value) ? value : text.split(/[,、]/)).map(entry => String(entry).trim()).filter(Boolean);
+      const invalid = values.filter(entry => item.choices.indexOf(entry) === -1);
+      if (invalid.length > 0) {
+        return { error: `「${invalid.join(', ')}」は指定できません（${item.choices.join(' / ')} をカンマ区切りで指定）。` };
+      }
+      return { value: values.filter((entry, index) => values.indexOf(entry) === index) };
+    }
+    case 'boolean': {
+      // スクリプトの定数（true / false）のほか、チェックボックスや「TRUE」「FALSE」と入力したセルも読み取る
+      const lower = text.toLowerCase();
+      if (lower !== 'true' && lower !== 'false') {
+        return { error: `「${text}」は指定できません（true / false のいずれか）。` };
+      }
+      return { value: lower === 'true' };
+    }
+    case 'date': {
+      const dateString = toDateString(value, timezone);
+      if (!dateString || addDays(dateString, 0) !== dateString) {
+        return { error: `「${text}」は日付として読み取れません（yyyy-MM-dd の形式）。` };
+      }
+      return { value: dateString };
+    }
+    case 'year': {
+      const year = Number(text);
+      const currentYear = new Date().getFullYear();
+      if (!/^\d{4}$/.test(text) || year < 2000 || year > currentYear) {
+        return { error: `「${text}」は取得できる年ではありません（2000〜${currentYear}）。` };
+      }
+      return { value: year };
+    }
+    case 'integer': {
+      const number = Number(text);
+      if (!/^\d+$/.test(text) || number < item.min || number > item.max) {
+        return { error: `「${text}」は ${item.min}〜${item.max} の整数で指定してください。` };
+      }
+      return { value: number };
+    }
+    default:
+      return { value: text };
+  }
+}
+
+/**
+ * 設定の不備の一覧を、ログで読みやすい1つのメッセージにまとめる
+ */
+function formatConfigErrors(errors) {
+  return `設定に${errors.length}件の不備があるため、データを取得せずに終了します。\n- ${errors.join('\n- ')}`;
+}
@@ -0,0 +1,220 @@
+/**
+ * 【品質スコア取得】
+ * 有効なキーワードごとに、品質スコアと3つの要素（推定クリック率・広告の関連性・ランディングページの利便性）を取得し、
+ * 「品質スコア」シートに実行した日の値として追記します。
+ * 前回の記録と比べて上がった・下がった項目は、「品質スコアの変更履歴」シートに1項目ずつ記録します。
+ * ★品質スコアは日ごとの値ではなく取得した時点の値のため、過去の日付は取得できません。毎日のトリガーで実行してください。
+ * ★同じ日に2回以上実行した場合は、その日の記録と変更履歴を置き換えます。
+ * ★表示回数が少なく品質スコアが付いていないキーワードは、空欄で記録します（空欄からの変化は変更履歴に記録しません）。
+ * ★「共通/」フォルダのファイルを同じスクリプトに貼り付けて実行してください。
+ */
+
+// ▼▼【要設定】▼▼ 記録したいスプレッドシートのURLを貼り付けてください
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼設定▼ 記録先のシート名を指定してください
+const SHEET_NAME = '品質スコア';
+const CHANGE_LOG_SHEET_NAME = '品質スコアの変更履歴';
+
+// 変更履歴の「変化」の列に記録する値
+const QUALITY_CHANGE_DIRECTIONS = {
+  up: '上昇',
+  down: '低下'
+};
+
+// 3つの要素の評価を比べるための順位
+const QUALITY_BUCKET_RANKS = {
+  'BELOW_AVERAGE': 1,
+  'AVERAGE': 2,
+  'ABOVE_AVERAGE': 3
+};
+
+// --- データセット定義 ---
+const QUALITY_SCORE_DATASET = {
+  columns: [
+    { key: 'recordedDate', label: '記録日', type: 'date' },
+    { key: 'campaign.id', label: 'キャンペーンID', type: 'id' },
+    { key: 'campaign.name', label: 'キャンペーン名', type: 'text' },
+    { key: 'ad_group.id', label: '広告グループID', type: 'id' },
+    { key: 'ad_group.name', label: '広告グループ名', type: 'text' },
+    { key: 'ad_group_criterion.criterion_id', label: 'キーワードID', type: 'id' },
+    { key: 'ad_group_criterion.keyword.text', label: 'キーワード', type: 'text', format: '@' },
+    { key: 'ad_group_criterion.keyword.match_type', label: 'マッチタイプ', type: 'text', enum: 'matchType' },
+    { key: 'ad_group_criterion.quality_info.quality_score', label: '品質スコア', type: 'number' },
+    { key: 'ad_group_criterion.quality_info.search_predicted_ctr', label: '推定クリック率', type: 'text', enum: 'qualityScoreBucket' },
+    { key: 'ad_group_criterion.quality_info.creative_quality_score', label: '広告の関連性', type: 'text', enum: 'qualityScoreBucket' },
+    { key: 'ad_group_criterion.quality_info.post_click_quality_score', label: 'ランディングページの利便性', type: 'text', enum: 'qualityScoreBucket' }
+  ],
+  keyHeaders: ['記録日', '広告グループID', 'キーワードID']
+};
+
+const QUALITY_CHANGE_LOG_DATASET = {
+  columns: [
+    { key: 'recordedDate', label: '記録日', type: 'date' },
+    { key: 'previousDate', label: '前回の記録日', type: 'date' },
+    { key: 'campaign.name', label: 'キャンペーン名', type: 'text' },
+    { key: 'ad_group.id', label: '広告グループID', type: 'id' },
+    { key: 'ad_group.name', label: '広告グループ名', type: 'text' },
+    { key: 'ad_group_criterion.criterion_id', label: 'キーワードID', type: 'id' },
+    { key: 'ad_group_criterion.keyword.text', label: 'キーワード', type: 'text', format: '@' },
+    { key: 'ad_group_criterion.keyword.match_type', label: 'マッチタイプ', type: 'text', enum: 'matchType' },
+    { key: 'item', label: '項目', type: 'text' },
+    { key: 'before', label: '変更前', type: 'text' },
+    { key: 'after', label: '変更後', type: 'text' },
+    { key: 'direction', label: '変化', type: 'text' }
+  ],
+  keyHeaders: ['記録日', '広告グループID', 'キーワードID', '項目']
+};
+
+// 変更を確認する項目（品質スコアは数値、3つの要素は QUALITY_BUCKET_RANKS の順位で比べる）
+const QUALITY_ITEMS = ['品質スコア', '推定クリック率', '広告の関連性', 'ランディングページの利便性'];
+
+function main() {
+  try {
+    [QUALITY_SCORE_DATASET, QUALITY_CHANGE_LOG_DATASET].forEach(dataset => {
+      registerSchema(dataset);
+      applyEnumOutput(dataset, 'label');
+    });
+
+    const spreadsheet = openSpreadsheet(SPREADSHEET_URL);
+    const today = todayString(AdsApp.currentAccount().getTimeZone());
+    const sameDay = { startDate: today, endDate: today };
+
+    let rows = fetchQualityScoreRows(today);
+    rows = translateEnumRows(QUALITY_SCORE_DATASET, rows);
+    assertRowsMatchSchema(QUALITY_SCORE_DATASET, rows);
+    console.log(`${rows.length}件のキーワードの品質スコアを取得しました。`);
+
+    const sheet = getOrCreateSheet(spreadsheet, SHEET_NAME);
+    migrateHeaders(sheet, QUALITY_SCORE_DATASET);
+    const previous = loadPreviousSnapshot(sheet, today);
+    const changes = previous ? detectQualityChanges(previous, rows) : [];
+
+    upsertRows(sheet, QUALITY_SCORE_DATASET, rows, sameDay);
+    applyColumnFormats(sheet, QUALITY_SCORE_DATASET);
+
+    const logSheet = getOrCreateSheet(spreadsheet, CHANGE_LOG_SHEET_NAME);
+    migrateHeaders(logSheet, QUALITY_CHANGE_LOG_DATASET);
+    upsertRows(logSheet, QUALITY_CHANGE_LOG_DATASET, changes, sameDay);
+    applyColumnFormats(logSheet, QUALITY_CHANGE_LOG_DATASET);
+    reportUnmappedEnums(spreadsheet, SHEET_NAME);
+
+    if (!previous) {
+      console.log('前回の記録がないため、変更履歴は次回の実行から記録します。');
+    } else {
+      console.log(`${previous.date} の記録と比べて、${changes.length}件の変更を記録しました。`);
+    }
+  } catch (e) {
+    console.error('スクリプトの実行中にエラーが発生しました: ' + e.toString());
+    console.error('エラー詳細: ' + e.stack);
+  }
+}
+
+/**
+ * 有効なキーワードの品質スコアと3つの要素を取得する
+ */
+function fetchQualityScoreRows(recordedDate) {
+  const fields = QUALITY_SCORE_DATASET.declaredColumns.map(column => column.key).filter(key => key !== 'recordedDate');
+  const query =
+    'SELECT ' + fields.join(', ') + ' ' +
+    'FROM ad_group_criterion ' +
+    "WHERE ad_group_criterion.type = 'KEYWORD' " +
+    'AND ad_group_criterion.negative = FALSE ' +
+    "AND ad_group_criterion.status = 'ENABLED' " +
+    "AND ad_group.status = 'ENABLED' " +
+    "AND campaign.status = 'ENABLED'";
+
+  return reportRowsToValues(reportRows(query), fields, {
+    'ad_group_criterion.quality_info.quality_score': value => value ? Number(value) : ''
+  }).map(values => [recordedDate].concat(values));
+}
+
+/**
+ * シートから、今日より前で最も新しい記録日の行を読み込む
+ * @returns {Object|null} { date, rows: Map(広告グループID|キーワードID → 行) }（前回の記録がない場合は null）
+ */
+function loadPreviousSnapshot(sheet, today) {
+  const lastRow = sheet.getLastRow();
+  if (lastRow <= 1) {
+    return null;
+  }
+  const headers = QUALITY_SCORE_DATASET.headers;
+  const sheetTimezone = sheet.getParent().getSpreadsheetTimeZone();
+  const values = sheet.getRange(2, 1, lastRow - 1, headers.length).getValues();
+
+  let previousDate = null;
+  values.forEach(row => {
+    const date = toDateString(row[0], sheetTimezone);
+    if (date && date < today && (!previousDate || date > previousDate)) {
+      previousDate = date;
+    }
+  });
+  if (!previousDate) {
+    return null;
+  }
+
+  const rows = new Map();
+  values.filter(row => toDateString(row[0], sheetTimezone) === previousDate)
+    .forEach(row => rows.set(qualityRowKey(row), row));
+  return { date: previousDate, rows: rows };
+}
+
+/**
+ * 前回の記録と今回の行を比べて、上がった・下がった項目を変更履歴の行にする
+ * 前回になかったキーワード・どちらかが空欄の項目は比べません。
+ */
+function detectQualityChanges(previous, rows) {
+  const headers = QUALITY_SCORE_DATASET.headers;
+  const column = label => headers.indexOf(label);
+  const changes = [];
+  rows.forEach(row => {
+    const before = previous.rows.get(qualityRowKey(row));
+    if (!before) {
+      return;
+    }
+    QUALITY_ITEMS.forEach(item => {
+      const index = column(item);
+      const difference = qualityRank(item, row[index]) - qualityRank(item, before[index]);
+      if (isNaN(difference) || difference === 0) {
+        return;
+      }
+      changes.push([
+        row[column('記録日')],
+        previous.date,
+        row[column('キャンペーン名')],
+        row[column('広告グループID')],
+        row[column('広告グループ名')],
+        row[column('キーワードID')],
+        row[column('キーワード')],
+        row[column('マッチタイプ')],
+        item,
+        before[index],
+        row[index],
+        difference > 0 ? QUALITY_CHANGE_DIRECTIONS.up : QUALITY_CHANGE_DIRECTIONS.down
+      ]);
+    });
+  });
+  return changes;
+}
+
+/**
+ * 項目の値を比べられる数値にする（空欄や不明な値は NaN）
+ */
+function qualityRank(item, value) {
+  if (value === '' || value === null || value === undefined) {
+    return NaN;
+  }
+  if (item === '品質スコア') {
+    return Number(value);
+  }
+  const rank = QUALITY_BUCKET_RANKS[toEnumCode('qualityScoreBucket', value)];
+  return rank === undefined ? NaN : rank;
+}
+
+/**
+ * キーワードを識別するキー（広告グループID|キーワードID）
+ */
+function qualityRowKey(row) {
+  const headers = QUALITY_SCORE_DATASET.headers;
+  return String(row[headers.indexOf('広告グループID')]) + '|' + String(row[headers.indexOf('キーワードID')]);
+}
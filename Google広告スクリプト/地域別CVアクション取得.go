@@ -0,0 +1,117 @@
+/**
+ * 【地域別・CVアクション別データ取得】
+ * 地域別・コンバージョンアクション別データを取得し、シート全体を日付順に並べ替えます。
+ * ★SEGMENTS を指定すると、デバイス・時間帯・曜日でも分けて記録します。
+ * ★「共通/同期処理.go」を同じスクリプトに貼り付けて実行してください。
+ */
+
+// --------------------------------------------------------------------------------
+// 設定項目
+// --------------------------------------------------------------------------------
+
+// ▼▼▼【要設定】出力先のGoogleスプレッドシートのURLを指定してください ▼▼▼
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼▼▼【任意設定】出力先のシート名を指定してください ▼▼▼
+const SHEET_NAME = '地域別CVアクションデータ'; // シート名を変更
+
+// ▼▼【要設定】▼▼ 取得方法を選んでください
+//   'daily' … 未取得の期間を追記（毎日のトリガー実行用）
+//   'range' … START_DATE から END_DATE までを取得
+//   'year'  … TARGET_YEAR の1年分を取得
+//   'all'   … アカウントの配信開始日から取得
+// ※'daily' 以外は1か月ずつ取得し、途中で止まった場合は次回の実行で続きから再開します。
+// ※記録先スプレッドシートの「設定」シートに値がある場合は、そちらが優先されます（共通/README.md 参照）。
+const MODE = 'daily';
+const START_DATE = '2024-01-01'; // 'range' のときの開始日
+const END_DATE = '';             // 'range' のときの終了日（空欄なら取得できる最新日まで）
+const TARGET_YEAR = 2025;        // 'year' のときに取得する年
+
+// ▼設定▼ コンバージョンの計上遅れに備えて、毎回取り直す直近の日数（7 / 14 / 30 など。0 で無効）
+const LOOKBACK_DAYS = 7;
+
+// ▼設定▼ デバイス・時間帯・曜日で分けて記録する場合に指定します（カンマ区切り。空欄なら分けない）
+//   'device' … デバイス別 / 'hour' … 時間帯（0〜23時）別 / 'dayOfWeek' … 曜日別
+// ※日付の右隣に列が追加されます。途中から指定した場合、それまでの行の分割の列は空欄です（共通/README.md 参照）。
+const SEGMENTS = '';
+
+// ▼設定▼ コンバージョンが発生した日で数えたコンバージョン数・価値も記録する場合は true
+// ※「すべてのコンバージョン価値」の右隣に2列を追加します。false に戻すときは、先にシートから2列を削除してください（共通/README.md 参照）。
+const INCLUDE_CONVERSION_DATE = false;
+
+// --------------------------------------------------------------------------------
+// データセット定義
+// --------------------------------------------------------------------------------
+const REGION_CV_DATASET = {
+  supportedSegments: ['device', 'hour', 'dayOfWeek'],
+  columns: [
+    { key: 'segments.date', label: '日付', type: 'date' },
+    { key: 'campaign_criterion.criterion_id', label: '地域ID', type: 'id', format: '@', addedKey: true },
+    { key: 'location', label: 'ターゲット地域', type: 'text', format: '@' },
+    { key: 'campaign.advertising_channel_type', label: '広告チャネルタイプ', type: 'text', enum: 'channelType' },
+    { key: 'segments.conversion_action_name', label: 'コンバージョンアクション名', type: 'text' },
+    { key: 'metrics.conversions', label: 'コンバージョン数', type: 'number' },
+    { key: 'metrics.conversions_value', label: 'コンバージョン価値', type: 'number' },
+    { key: 'metrics.all_conversions_value', label: 'すべてのコンバージョン価値', type: 'number' }
+  ],
+  conversionDateAfter: 'すべてのコンバージョン価値', // CV発生日の2列を追加する位置（INCLUDE_CONVERSION_DATE）
+  keyHeaders: ['日付', '地域ID', '広告チャネルタイプ', 'コンバージョンアクション名'], // ターゲット地域は地域ID（条件ID）から特定した表示用の名前のため、キーには使わない
+  fetchRows: function (range) {
+    // --- Step 1: 地域別のコンバージョンデータを取得 ---
+    Logger.log('Step 1: コンバージョンデータを取得しています...');
+    const convQuery = `
+      SELECT
+        segments.date,
+        campaign_criterion.criterion_id${segmentSelectClause(REGION_CV_DATASET)},
+        campaign.advertising_channel_type,
+        segments.conversion_action_name,
+        metrics.conversions,
+        metrics.conversions_value,
+        metrics.all_conversions_value${conversionDateSelectClause(REGION_CV_DATASET)}
+      FROM
+        location_view
+      WHERE
+        segments.date BETWEEN '${range.startDate}' AND '${range.endDate}'
+        ${conversionFilterClause(REGION_CV_DATASET)}
+    `;
+    const convRows = reportRows(convQuery).filter(row => hasConversions(REGION_CV_DATASET, row));
+
+    // --- Step 2: 全ての地域IDの詳細情報を取得 ---
+    // レポートから重複を除いた地域IDのリストを作成
+    const allCriterionIds = new Set(convRows.map(row => row['campaign_criterion.criterion_id']));
+    Logger.log(`Step 2: ${allCriterionIds.size} 件の地域IDから名前を特定しています...`);
+    const locationInfoMap = fetchLocationNames(Array.from(allCriterionIds));
+
+    // --- Step 3: データを結合して出力 ---
+    Logger.log('Step 3: データを結合して出力します...');
+    return convRows.map(row => {
+      const criterionId = row['campaign_criterion.criterion_id'];
+      return [row['segments.date']].concat(segmentValues(REGION_CV_DATASET, row), [
+        criterionId,
+        locationInfoMap.get(criterionId) || criterionId,
+        row['campaign.advertising_channel_type'],
+        row['segments.conversion_action_name'],
+        row['metrics.conversions'],
+        row['metrics.conversions_value'],
+        row['metrics.all_conversions_value']
+      ].concat(conversionDateValues(REGION_CV_DATASET, row)));
+    });
+  }
+};
+
+// --------------------------------------------------------------------------------
+// メイン処理
+// --------------------------------------------------------------------------------
+function main() {
+  runSync(REGION_CV_DATASET, {
+    spreadsheetUrl: SPREADSHEET_URL,
+    sheetName: SHEET_NAME,
+    mode: MODE,
+    startDate: START_DATE,
+    endDate: END_DATE,
+    targetYear: TARGET_YEAR,
+    lookbackDays: LOOKBACK_DAYS,
+    segments: SEGMENTS,
+    includeConversionDate: INCLUDE_CONVERSION_DATE
+  });
+}
@@ -0,0 +1,120 @@
+/**
+ * 【地域別データ取得】
+ * 地域別データを取得し、シート全体を日付順に並べ替えます。
+ * ★SEGMENTS を指定すると、デバイス・時間帯・曜日でも分けて記録します。
+ * ★「共通/同期処理.go」を同じスクリプトに貼り付けて実行してください。
+ */
+
+// --------------------------------------------------------------------------------
+// 設定項目
+// --------------------------------------------------------------------------------
+
+// ▼▼▼【要設定】出力先のGoogleスプレッドシートのURLを指定してください ▼▼▼
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼▼▼【任意設定】出力先のシート名を指定してください ▼▼▼
+const SHEET_NAME = '地域別データ';
+
+// ▼▼【要設定】▼▼ 取得方法を選んでください
+//   'daily' … 未取得の期間を追記（毎日のトリガー実行用）
+//   'range' … START_DATE から END_DATE までを取得
+//   'year'  … TARGET_YEAR の1年分を取得
+//   'all'   … アカウントの配信開始日から取得
+// ※'daily' 以外は1か月ずつ取得し、途中で止まった場合は次回の実行で続きから再開します。
+// ※記録先スプレッドシートの「設定」シートに値がある場合は、そちらが優先されます（共通/README.md 参照）。
+const MODE = 'daily';
+const START_DATE = '2024-01-01'; // 'range' のときの開始日
+const END_DATE = '';             // 'range' のときの終了日（空欄なら取得できる最新日まで）
+const TARGET_YEAR = 2025;        // 'year' のときに取得する年
+
+// ▼設定▼ コンバージョンの計上遅れに備えて、毎回取り直す直近の日数（7 / 14 / 30 など。0 で無効）
+const LOOKBACK_DAYS = 7;
+
+// ▼設定▼ デバイス・時間帯・曜日で分けて記録する場合に指定します（カンマ区切り。空欄なら分けない）
+//   'device' … デバイス別 / 'hour' … 時間帯（0〜23時）別 / 'dayOfWeek' … 曜日別
+// ※日付の右隣に列が追加されます。途中から指定した場合、それまでの行の分割の列は空欄です（共通/README.md 参照）。
+const SEGMENTS = '';
+
+// --------------------------------------------------------------------------------
+// データセット定義
+// --------------------------------------------------------------------------------
+const REGION_DATASET = {
+  supportedSegments: ['device', 'hour', 'dayOfWeek'],
+  columns: [
+    { key: 'segments.date', label: '日付', type: 'date' },
+    { key: 'campaign_criterion.criterion_id', label: '地域ID', type: 'id', format: '@', addedKey: true },
+    { key: 'location', label: 'ターゲット地域', type: 'text', format: '@' },
+    { key: 'metrics.clicks', label: 'クリック数', type: 'number' },
+    { key: 'metrics.impressions', label: '表示回数', type: 'number' },
+    { key: 'metrics.cost_micros', label: '費用', type: 'number' },
+    { key: 'metrics.conversions', label: 'コンバージョン数', type: 'number' }
+  ],
+  keyHeaders: ['日付', '地域ID'], // ターゲット地域は地域ID（条件ID）から特定した表示用の名前のため、キーには使わない
+  fetchRows: function (range) {
+    // --- Step 1: パフォーマンス指標と地域IDを日別に取得 ---
+    Logger.log('Step 1: パフォーマンスデータを取得しています...');
+    const performanceQuery = `
+      SELECT
+        segments.date,
+        campaign_criterion.criterion_id${segmentSelectClause(REGION_DATASET)},
+        metrics.clicks,
+        metrics.impressions,
+        metrics.cost_micros,
+        metrics.conversions
+      FROM
+        location_view
+      WHERE
+        segments.date BETWEEN '${range.startDate}' AND '${range.endDate}'
+    `;
+
+    const performanceData = {};
+    const allCriterionIds = new Set();
+    for (const row of reportRows(performanceQuery)) {
+      const criterionId = row['campaign_criterion.criterion_id'];
+      const date = row['segments.date'];
+      if (!criterionId || !date) continue;
+      allCriterionIds.add(criterionId);
+      const segments = segmentValues(REGION_DATASET, row);
+      const key = [date].concat(segments, criterionId).join('_');
+      if (!performanceData[key]) {
+        performanceData[key] = { date: date, segments: segments, criterionId: criterionId, clicks: 0, impressions: 0, cost: 0, conversions: 0 };
+      }
+      performanceData[key].clicks += parseFloat(row['metrics.clicks']);
+      performanceData[key].impressions += parseFloat(row['metrics.impressions']);
+      performanceData[key].cost += microsToYen(row['metrics.cost_micros']);
+      performanceData[key].conversions += parseFloat(row['metrics.conversions']);
+    }
+
+    // --- Step 2: 全ての地域IDの詳細情報を、種類を判別しながら取得 ---
+    Logger.log(`Step 2: ${allCriterionIds.size} 件の地域IDから名前を特定しています...`);
+    const locationInfoMap = fetchLocationNames(Array.from(allCriterionIds));
+
+    // --- Step 3: データを結合して出力 ---
+    Logger.log('Step 3: データを結合して出力します...');
+    return Object.keys(performanceData).map(key => {
+      const data = performanceData[key];
+      const name = locationInfoMap.get(data.criterionId) || data.criterionId;
+      return [data.date].concat(data.segments, [
+        data.criterionId, name,
+        data.clicks, data.impressions,
+        Math.round(data.cost), data.conversions
+      ]);
+    });
+  }
+};
+
+// --------------------------------------------------------------------------------
+// メイン処理
+// --------------------------------------------------------------------------------
+function main() {
+  runSync(REGION_DATASET, {
+    spreadsheetUrl: SPREADSHEET_URL,
+    sheetName: SHEET_NAME,
+    mode: MODE,
+    startDate: START_DATE,
+    endDate: END_DATE,
+    targetYear: TARGET_YEAR,
+    lookbackDays: LOOKBACK_DAYS,
+    segments: SEGMENTS
+  });
+}
@@ -0,0 +1,177 @@
+/**
+ * 【基本データ・金額修正済み】
+ * キャンペーン・デバイス別の基本データを取得し、シート全体を日付順に並べ替えます。
+ * GAQLで取得し、金額の単位（円）・割合の表記（7.00% / < 10% / --）は、AWQLで記録していた値に揃えます。
+ * デバイス・ステータスなどの区分値も、既定ではAWQLの頃と同じ値（STREAMING_TV・enabled など）で記録します（「共通/列挙値.go」の 'legacy'）。
+ * 移行前後で数値が変わっていないかは、checkGaqlMigration() で確認できます（シートには書き込みません）。
+ * ★「共通/同期処理.go」を同じスクリプトに貼り付けて実行してください。
+ */
+
+// ▼▼【要設定】▼▼ 記録したいスプレッドシートのURLを貼り付けてください
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼設定▼ 記録先のシート名を指定してください
+const SHEET_NAME = '基本データ'; // シート名は変更OK
+
+// ▼▼【要設定】▼▼ 取得方法を選んでください
+//   'daily' … 未取得の期間を追記（毎日のトリガー実行用）
+//   'range' … START_DATE から END_DATE までを取得
+//   'year'  … TARGET_YEAR の1年分を取得
+//   'all'   … アカウントの配信開始日から取得
+// ※'daily' 以外は1か月ずつ取得し、途中で止まった場合は次回の実行で続きから再開します。
+// ※記録先スプレッドシートの「設定」シートに値がある場合は、そちらが優先されます（共通/README.md 参照）。
+const MODE = 'daily';
+const START_DATE = '2024-01-01'; // 'range' のときの開始日
+const END_DATE = '';             // 'range' のときの終了日（空欄なら取得できる最新日まで）
+const TARGET_YEAR = 2025;        // 'year' のときに取得する年
+
+// ▼設定▼ コンバージョンの計上遅れに備えて、毎回取り直す直近の日数（7 / 14 / 30 など。0 で無効）
+const LOOKBACK_DAYS = 7;
+
+// ▼設定▼ 移行チェック（checkGaqlMigration）で旧AWQLと新GAQLの結果を比べる日数（直近の確定日から遡る）
+const MIGRATION_CHECK_DAYS = 7;
+
+// --- データセット定義 ---
+// 取得する項目（key）とシートの見出し（label）は、ずれないよう1か所で定義する
+// GAQLへ移行済み。legacyKey は移行前のAWQLのフィールド名（checkGaqlMigration で新旧を比べるために残しています）
+const BASE_COLUMNS = [
+  { key: 'segments.date', legacyKey: 'Date', label: '日付', type: 'date' },
+  { key: 'segments.device', legacyKey: 'Device', label: 'デバイス', type: 'text', enum: 'device' },
+  { key: 'customer.descriptive_name', legacyKey: 'AccountDescriptiveName', label: 'アカウント名', type: 'text' },
+  { key: 'campaign.id', legacyKey: 'CampaignId', label: 'キャンペーンID', type: 'id' },
+  { key: 'campaign.name', legacyKey: 'CampaignName', label: 'キャンペーン名', type: 'text' },
+  { key: 'campaign.status', legacyKey: 'CampaignStatus', label: 'キャンペーンステータス', type: 'text', enum: 'status' },
+  { key: 'campaign.advertising_channel_type', legacyKey: 'AdvertisingChannelType', label: '広告チャネルタイプ', type: 'text', enum: 'channelType' },
+  { key: 'campaign.bidding_strategy_type', legacyKey: 'BiddingStrategyType', label: '入札戦略タイプ', type: 'text', enum: 'biddingStrategy' },
+  { key: 'metrics.impressions', legacyKey: 'Impressions', label: '表示回数', type: 'number' },
+  { key: 'metrics.clicks', legacyKey: 'Clicks', label: 'クリック数', type: 'number' },
+  { key: 'metrics.cost_micros', legacyKey: 'Cost', label: 'ご利用額', type: 'number' },
+  { key: 'metrics.ctr', legacyKey: 'Ctr', label: 'クリック率', type: 'percent' },
+  { key: 'metrics.average_cpc', legacyKey: 'AverageCpc', label: '平均クリック単価', type: 'number' },
+  { key: 'metrics.conversions', legacyKey: 'Conversions', label: 'コンバージョン', type: 'number' },
+  { key: 'metrics.conversions_from_interactions_rate', legacyKey: 'ConversionRate', label: 'コンバージョン率', type: 'percent' },
+  { key: 'metrics.cost_per_conversion', legacyKey: 'CostPerConversion', label: 'コンバージョン単価', type: 'number' },
+  { key: 'metrics.conversions_value', legacyKey: 'ConversionValue', label: 'コンバージョン価値', type: 'number' },
+  { key: 'metrics.all_conversions', legacyKey: 'AllConversions', label: 'すべてのコンバージョン', type: 'number' },
+  { key: 'metrics.all_conversions_from_interactions_rate', legacyKey: 'AllConversionRate', label: 'すべてのコンバージョン率', type: 'percent' },
+  { key: 'metrics.cost_per_all_conversions', legacyKey: 'CostPerAllConversion', label: 'すべてのコンバージョン単価', type: 'number' },
+  { key: 'metrics.all_conversions_value', legacyKey: 'AllConversionValue', label: 'すべてのコンバージョン価値', type: 'number' },
+  { key: 'metrics.view_through_conversions', legacyKey: 'ViewThroughConversions', label: 'ビュースルーコンバージョン', type: 'number' },
+  { key: 'metrics.interactions', legacyKey: 'Interactions', label: 'インタラクション', type: 'number' },
+  { key: 'metrics.interaction_rate', legacyKey: 'InteractionRate', label: 'インタラクション率', type: 'percent' },
+  { key: 'metrics.average_cost', legacyKey: 'AverageCost', label: '平均費用', type: 'number' },
+  { key: 'metrics.average_cpm', legacyKey: 'AverageCpm', label: '平均CPM', type: 'number' },
+  { key: 'metrics.trueview_average_cpv', legacyKey: 'AverageCpv', label: '平均CPV', type: 'number' },
+  { key: 'metrics.search_impression_share', legacyKey: 'SearchImpressionShare', label: '検索IS', type: 'percent' },
+  { key: 'metrics.search_top_impression_share', legacyKey: 'SearchTopImpressionShare', label: '検索TOP IS', type: 'percent' },
+  { key: 'metrics.search_absolute_top_impression_share', legacyKey: 'SearchAbsoluteTopImpressionShare', label: '検索Abs.TOP IS', type: 'percent' },
+  { key: 'metrics.search_budget_lost_impression_share', legacyKey: 'SearchBudgetLostImpressionShare', label: '検索IS損失率(予算)', type: 'percent' },
+  { key: 'metrics.search_rank_lost_impression_share', legacyKey: 'SearchRankLostImpressionShare', label: '検索IS損失率(ランク)', type: 'percent' },
+  { key: 'metrics.content_impression_share', legacyKey: 'ContentImpressionShare', label: 'コンテンツIS', type: 'percent' },
+  { key: 'metrics.content_budget_lost_impression_share', legacyKey: 'ContentBudgetLostImpressionShare', label: 'コンテンツIS損失率(予算)', type: 'percent' },
+  { key: 'metrics.content_rank_lost_impression_share', legacyKey: 'ContentRankLostImpressionShare', label: 'コンテンツIS損失率(ランク)', type: 'percent' },
+  { key: 'metrics.video_trueview_views', legacyKey: 'VideoViews', label: '動画再生回数', type: 'number' },
+  { key: 'metrics.video_trueview_view_rate', legacyKey: 'VideoViewRate', label: '動画再生率', type: 'percent' },
+  { key: 'metrics.video_quartile_p25_rate', legacyKey: 'VideoQuartile25Rate', label: '動画再生25%', type: 'percent' },
+  { key: 'metrics.video_quartile_p50_rate', legacyKey: 'VideoQuartile50Rate', label: '動画再生50%', type: 'percent' },
+  { key: 'metrics.video_quartile_p75_rate', legacyKey: 'VideoQuartile75Rate', label: '動画再生75%', type: 'percent' },
+  { key: 'metrics.video_quartile_p100_rate', legacyKey: 'VideoQuartile100Rate', label: '動画再生100%', type: 'percent' }
+];
+const BASE_API_FIELDS = BASE_COLUMNS.map(column => column.key);
+
+// マイクロ単位で返ってくる金額の項目（円に変換する）
+const BASE_MICROS_FIELDS = [
+  'metrics.cost_micros', 'metrics.average_cpc', 'metrics.cost_per_conversion', 'metrics.cost_per_all_conversions',
+  'metrics.average_cost', 'metrics.average_cpm', 'metrics.trueview_average_cpv'
+];
+
+// 値が出ない（対象外・データ不足）ときに空になるインプレッションシェアの項目
+const BASE_SHARE_FIELDS = BASE_API_FIELDS.filter(field => /impression_share$/.test(field));
+
+// 割合（0.07 など）で返ってくる項目（AWQLと同じ「7.00%」の表記にする）
+const BASE_PERCENT_FIELDS = BASE_COLUMNS.filter(column => column.type === 'percent').map(column => column.key);
+
+const BASE_DATASET = {
+  // 「Google広告用レポート」が移行前の値（SEARCH・MOBILE・enabled など）で集計するため、区分値は既定でAWQLの頃の表記で記録する
+  defaults: { enumOutput: 'legacy' },
+  columns: BASE_COLUMNS,
+  keyHeaders: ['日付', 'デバイス', 'キャンペーンID'],
+  fetchRows: function (range) {
+    // AWQLのレポートと同じく、表示回数が0の行は含めない
+    const query =
+      'SELECT ' + BASE_API_FIELDS.join(', ') + ' ' +
+      'FROM campaign ' +
+      `WHERE segments.date BETWEEN '${range.startDate}' AND '${range.endDate}' ` +
+      'AND metrics.impressions > 0 ' +
+      'ORDER BY segments.date ASC';
+
+    // --- AWQLで記録していた値と同じ単位に揃える ---
+    const transforms = {};
+    BASE_MICROS_FIELDS.forEach(field => {
+      transforms[field] = microsToYen;
+    });
+    BASE_PERCENT_FIELDS.forEach(field => {
+      transforms[field] = value => toLegacyPercent(value, BASE_SHARE_FIELDS.indexOf(field) !== -1);
+    });
+    return reportRowsToValues(reportRows(query), BASE_API_FIELDS, transforms);
+  }
+};
+
+/**
+ * 割合をAWQLのレポートと同じ表記（「7.00%」）にする
+ * インプレッションシェアは、値が出ないときの「 --」と、10%未満・90%超を表す「< 10%」「> 90%」もAWQLに揃えます。
+ * @param {number|string|null} value - GAQLの値（0.07 など）
+ * @param {boolean} isShare - インプレッションシェアの項目かどうか
+ */
+function toLegacyPercent(value, isShare) {
+  if (value === null || value === undefined || value === '' || value === '--') {
+    return isShare ? ' --' : '0.00%';
+  }
+  const rate = Number(value);
+  if (isShare && rate === 0.0999) {
+    return '< 10%';
+  }
+  if (isShare && rate === 0.9001) {
+    return '> 90%';
+  }
+  return (rate * 100).toFixed(2) + '%';
+}
+
+/**
+ * 【移行チェック】旧AWQLと新GAQLで同じ期間を取得し、差分をログに出力する（シートには書き込みません）
+ * 実行する関数に「checkGaqlMigration」を選んで実行してください。
+ * ※旧AWQLの行も、移行前のスクリプトと同じ値（デバイスの STREAMING_TV など）に直してから、シートに記録する値どうしで比べます。
+ */
+function checkGaqlMigration() {
+  try {
+    registerSchema(BASE_DATASET);
+    applyEnumOutput(BASE_DATASET, 'legacy');
+    const timezone = AdsApp.currentAccount().getTimeZone();
+    const endDate = addDays(todayString(timezone), -2);
+    const range = buildRange(addDays(endDate, -(MIGRATION_CHECK_DAYS - 1)), endDate, timezone);
+
+    const legacyFields = BASE_COLUMNS.map(column => column.legacyKey);
+    const legacyQuery =
+      'SELECT ' + legacyFields.join(', ') + ' ' +
+      'FROM CAMPAIGN_PERFORMANCE_REPORT ' +
+      'DURING ' + range.during;
+    const legacyRows = translateEnumRows(BASE_DATASET, reportRowsToValues(reportRows(legacyQuery), legacyFields));
+    const newRows = translateEnumRows(BASE_DATASET, BASE_DATASET.fetchRows(range));
+
+    compareMigrationRows(BASE_DATASET, range, legacyRows, newRows);
+  } catch (e) {
+    console.error('スクリプトの実行中にエラーが発生しました: ' + e.message);
+  }
+}
+
+function main() {
+  runSync(BASE_DATASET, {
+    spreadsheetUrl: SPREADSHEET_URL,
+    sheetName: SHEET_NAME,
+    mode: MODE,
+    startDate: START_DATE,
+    endDate: END_DATE,
+    targetYear: TARGET_YEAR,
+    lookbackDays: LOOKBACK_DAYS
+  });
+}
@@ -0,0 +1,137 @@
+/**
+ * 【広告別データ取得】
+ * 広告（ad_group_ad）ごとの実績を、広告見出し・説明文・最終ページURL・広告の有効性と一緒に取得し、
+ * シート全体を日付順に並べ替えます。
+ * ★広告見出し・説明文はレスポンシブ検索広告のものを「 / 」区切りで記録します（固定表示している場合は「[1]」のように位置を付けます）。
+ * ★広告文・広告の有効性は取得した時点の内容です。広告文を差し替えた場合は、広告IDが変わるため別の行になります。
+ * ★「Google広告用レポート」のHTMLレポートで、成果の良い広告・悪い広告の比較に使います。
+ * ★「共通/同期処理.go」を同じスクリプトに貼り付けて実行してください。
+ */
+
+// ▼▼【要設定】▼▼ 記録したいスプレッドシートのURLを貼り付けてください
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼設定▼ 記録先のシート名を指定してください
+const SHEET_NAME = '広告データ';
+
+// ▼▼【要設定】▼▼ 取得方法を選んでください
+//   'daily' … 未取得の期間を追記（毎日のトリガー実行用）
+//   'range' … START_DATE から END_DATE までを取得
+//   'year'  … TARGET_YEAR の1年分を取得
+//   'all'   … アカウントの配信開始日から取得
+// ※'daily' 以外は1か月ずつ取得し、途中で止まった場合は次回の実行で続きから再開します。
+// ※記録先スプレッドシートの「設定」シートに値がある場合は、そちらが優先されます（共通/README.md 参照）。
+const MODE = 'daily';
+const START_DATE = '2024-01-01'; // 'range' のときの開始日
+const END_DATE = '';             // 'range' のときの終了日（空欄なら取得できる最新日まで）
+const TARGET_YEAR = 2025;        // 'year' のときに取得する年
+
+// ▼設定▼ コンバージョンの計上遅れに備えて、毎回取り直す直近の日数（7 / 14 / 30 など。0 で無効）
+const LOOKBACK_DAYS = 7;
+
+// 広告見出し・説明文の区切り
+const AD_TEXT_SEPARATOR = ' / ';
+
+// --- データセット定義 ---
+const AD_DATASET = {
+  columns: [
+    { key: 'segments.date', label: '日付', type: 'date' },
+    { key: 'campaign.id', label: 'キャンペーンID', type: 'id' },
+    { key: 'campaign.name', label: 'キャンペーン名', type: 'text' },
+    { key: 'campaign.advertising_channel_type', label: '広告チャネルタイプ', type: 'text', enum: 'channelType' },
+    { key: 'ad_group.id', label: '広告グループID', type: 'id' },
+    { key: 'ad_group.name', label: '広告グループ名', type: 'text' },
+    { key: 'ad_group_ad.ad.id', label: '広告ID', type: 'id' },
+    { key: 'ad_group_ad.ad.type', label: '広告タイプ', type: 'text', enum: 'adType' },
+    { key: 'ad_group_ad.status', label: '広告ステータス', type: 'text', enum: 'status' },
+    { key: 'ad_group_ad.ad_strength', label: '広告の有効性', type: 'text', enum: 'adStrength' },
+    { key: 'ad_group_ad.ad.responsive_search_ad.headlines', label: '広告見出し', type: 'text', format: '@' },
+    { key: 'ad_group_ad.ad.responsive_search_ad.descriptions', label: '説明文', type: 'text', format: '@' },
+    { key: 'ad_group_ad.ad.final_urls', label: '最終ページURL', type: 'text', format: '@' },
+    { key: 'metrics.impressions', label: '表示回数', type: 'number' },
+    { key: 'metrics.clicks', label: 'クリック数', type: 'number' },
+    { key: 'metrics.cost_micros', label: '費用', type: 'number' },
+    { key: 'metrics.conversions', label: 'コンバージョン数', type: 'number' },
+    { key: 'metrics.conversions_value', label: 'コンバージョン価値', type: 'number', previousLabels: ['コンバージョン値'] }
+  ],
+  keyHeaders: ['日付', '広告グループID', '広告ID'],
+  fetchRows: function (range) {
+    // 広告見出し・説明文は配列で返されるため、AdsApp.report() ではなく AdsApp.search() で取得する
+    const query = `
+      SELECT
+        segments.date,
+        campaign.id,
+        campaign.name,
+        campaign.advertising_channel_type,
+        ad_group.id,
+        ad_group.name,
+        ad_group_ad.ad.id,
+        ad_group_ad.ad.type,
+        ad_group_ad.status,
+        ad_group_ad.ad_strength,
+        ad_group_ad.ad.responsive_search_ad.headlines,
+        ad_group_ad.ad.responsive_search_ad.descriptions,
+        ad_group_ad.ad.final_urls,
+        metrics.impressions,
+        metrics.clicks,
+        metrics.cost_micros,
+        metrics.conversions,
+        metrics.conversions_value
+      FROM ad_group_ad
+      WHERE segments.date BETWEEN '${range.startDate}' AND '${range.endDate}'
+        AND metrics.impressions > 0
+      ORDER BY segments.date ASC`;
+
+    const rows = [];
+    for (const row of AdsApp.search(query)) {
+      const ad = row.adGroupAd.ad;
+      const rsa = ad.responsiveSearchAd || {};
+      // 値が0の指標は応答に含まれないため、0 として扱う
+      const metrics = row.metrics || {};
+      rows.push([
+        row.segments.date,
+        row.campaign.id,
+        row.campaign.name,
+        row.campaign.advertisingChannelType,
+        row.adGroup.id,
+        row.adGroup.name,
+        ad.id,
+        ad.type,
+        row.adGroupAd.status,
+        row.adGroupAd.adStrength || '',
+        formatAdTextAssets(rsa.headlines),
+        formatAdTextAssets(rsa.descriptions),
+        (ad.finalUrls || [])[0] || '',
+        Number(metrics.impressions) || 0,
+        Number(metrics.clicks) || 0,
+        microsToYen(metrics.costMicros),
+        Number(metrics.conversions) || 0,
+        Number(metrics.conversionsValue) || 0
+      ]);
+    }
+    return rows;
+  }
+};
+
+/**
+ * 広告見出し・説明文のアセットを1つの文字列にまとめる（固定表示の位置は「[1]」のように先頭に付ける）
+ * @param {Array<Object>} assets - { text, pinnedField } の配列（HEADLINE_1 / DESCRIPTION_2 などの固定表示の位置）
+ */
+function formatAdTextAssets(assets) {
+  return (assets || []).map(asset => {
+    const position = /_(\d+)$/.exec(asset.pinnedField || '');
+    return (position ? `[${position[1]}]` : '') + asset.text;
+  }).join(AD_TEXT_SEPARATOR);
+}
+
+function main() {
+  runSync(AD_DATASET, {
+    spreadsheetUrl: SPREADSHEET_URL,
+    sheetName: SHEET_NAME,
+    mode: MODE,
+    startDate: START_DATE,
+    endDate: END_DATE,
+    targetYear: TARGET_YEAR,
+    lookbackDays: LOOKBACK_DAYS
+  });
+}
@@ -0,0 +1,99 @@
+/**
+ * 【性別・CVアクション別データ取得】
+ * 性別・コンバージョンアクション別のデータを取得し、シート全体を日付順に並べ替える。
+ * ★広告チャネルタイプを追加（大文字）
+ * ★SEGMENTS を指定すると、デバイス・曜日でも分けて記録します。
+ * ★「共通/同期処理.go」を同じスクリプトに貼り付けて実行してください。
+ */
+
+// ▼▼【要設定】▼▼ 記録したいスプレッドシートのURLを貼り付けてください
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼設定▼ 記録先のシート名を指定してください
+const SHEET_NAME = '性別CVアクションデータ'; // シート名を変更
+
+// ▼▼【要設定】▼▼ 取得方法を選んでください
+//   'daily' … 未取得の期間を追記（毎日のトリガー実行用）
+//   'range' … START_DATE から END_DATE までを取得
+//   'year'  … TARGET_YEAR の1年分を取得
+//   'all'   … アカウントの配信開始日から取得
+// ※'daily' 以外は1か月ずつ取得し、途中で止まった場合は次回の実行で続きから再開します。
+// ※記録先スプレッドシートの「設定」シートに値がある場合は、そちらが優先されます（共通/README.md 参照）。
+const MODE = 'daily';
+const START_DATE = '2024-01-01'; // 'range' のときの開始日
+const END_DATE = '';             // 'range' のときの終了日（空欄なら取得できる最新日まで）
+const TARGET_YEAR = 2025;        // 'year' のときに取得する年
+
+// ▼設定▼ コンバージョンの計上遅れに備えて、毎回取り直す直近の日数（7 / 14 / 30 など。0 で無効）
+const LOOKBACK_DAYS = 7;
+
+// ▼設定▼ デバイス・曜日で分けて記録する場合に指定します（カンマ区切り。空欄なら分けない）
+//   'device' … デバイス別 / 'dayOfWeek' … 曜日別（年齢・性別のレポートは時間帯では分けられません）
+// ※日付の右隣に列が追加されます。途中から指定した場合、それまでの行の分割の列は空欄です（共通/README.md 参照）。
+const SEGMENTS = '';
+
+// ▼設定▼ コンバージョンが発生した日で数えたコンバージョン数・価値も記録する場合は true
+// ※「すべてのコンバージョン価値」の右隣に2列を追加します。false に戻すときは、先にシートから2列を削除してください（共通/README.md 参照）。
+const INCLUDE_CONVERSION_DATE = false;
+
+// --- データセット定義 ---
+const GENDER_CV_DATASET = {
+  supportedSegments: ['device', 'dayOfWeek'],
+  columns: [
+    { key: 'segments.date', label: '日付', type: 'date' },
+    { key: 'campaign.name', label: 'キャンペーン名', type: 'text' },
+    { key: 'campaign.advertising_channel_type', label: '広告チャネルタイプ', type: 'text', enum: 'channelType' },
+    { key: 'ad_group.name', label: '広告グループ名', type: 'text' },
+    { key: 'ad_group_criterion.gender.type', label: '性別', type: 'text', enum: 'gender' },
+    { key: 'segments.conversion_action_name', label: 'コンバージョンアクション名', type: 'text' },
+    { key: 'metrics.conversions', label: 'コンバージョン数', type: 'number' },
+    { key: 'metrics.conversions_value', label: 'コンバージョン価値', type: 'number' },
+    { key: 'metrics.all_conversions_value', label: 'すべてのコンバージョン価値', type: 'number' }
+  ],
+  conversionDateAfter: 'すべてのコンバージョン価値', // CV発生日の2列を追加する位置（INCLUDE_CONVERSION_DATE）
+  keyHeaders: ['日付', 'キャンペーン名', '広告チャネルタイプ', '広告グループ名', '性別', 'コンバージョンアクション名'],
+  fetchRows: function (range) {
+    const query = `
+      SELECT
+        segments.date${segmentSelectClause(GENDER_CV_DATASET)},
+        campaign.name,
+        campaign.advertising_channel_type,
+        ad_group.name,
+        ad_group_criterion.gender.type,
+        segments.conversion_action_name,
+        metrics.conversions,
+        metrics.conversions_value,
+        metrics.all_conversions_value${conversionDateSelectClause(GENDER_CV_DATASET)}
+      FROM gender_view
+      WHERE
+        segments.date >= '${range.startDate}'
+        AND segments.date <= '${range.endDate}'
+        ${conversionFilterClause(GENDER_CV_DATASET)}
+    `;
+
+    return reportRows(query).filter(row => hasConversions(GENDER_CV_DATASET, row)).map(row => [row['segments.date']].concat(segmentValues(GENDER_CV_DATASET, row), [
+      row['campaign.name'],
+      row['campaign.advertising_channel_type'],
+      row['ad_group.name'],
+      row['ad_group_criterion.gender.type'],
+      row['segments.conversion_action_name'],
+      row['metrics.conversions'],
+      row['metrics.conversions_value'],
+      row['metrics.all_conversions_value']
+    ], conversionDateValues(GENDER_CV_DATASET, row)));
+  }
+};
+
+function main() {
+  runSync(GENDER_CV_DATASET, {
+    spreadsheetUrl: SPREADSHEET_URL,
+    sheetName: SHEET_NAME,
+    mode: MODE,
+    startDate: START_DATE,
+    endDate: END_DATE,
+    targetYear: TARGET_YEAR,
+    lookbackDays: LOOKBACK_DAYS,
+    segments: SEGMENTS,
+    includeConversionDate: INCLUDE_CONVERSION_DATE
+  });
+}
@@ -0,0 +1,88 @@
+/**
+ * 【性別データ取得】
+ * 性別データを取得し、シート全体を日付順に並べ替える。
+ * ★SEGMENTS を指定すると、デバイス・曜日でも分けて記録します。
+ * ★「共通/同期処理.go」を同じスクリプトに貼り付けて実行してください。
+ */
+
+// ▼▼【要設定】▼▼ 記録したいスプレッドシートのURLを貼り付けてください
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼設定▼ 記録先のシート名を指定してください
+const SHEET_NAME = '性別データ';
+
+// ▼▼【要設定】▼▼ 取得方法を選んでください
+//   'daily' … 未取得の期間を追記（毎日のトリガー実行用）
+//   'range' … START_DATE から END_DATE までを取得
+//   'year'  … TARGET_YEAR の1年分を取得
+//   'all'   … アカウントの配信開始日から取得
+// ※'daily' 以外は1か月ずつ取得し、途中で止まった場合は次回の実行で続きから再開します。
+// ※記録先スプレッドシートの「設定」シートに値がある場合は、そちらが優先されます（共通/README.md 参照）。
+const MODE = 'daily';
+const START_DATE = '2024-01-01'; // 'range' のときの開始日
+const END_DATE = '';             // 'range' のときの終了日（空欄なら取得できる最新日まで）
+const TARGET_YEAR = 2025;        // 'year' のときに取得する年
+
+// ▼設定▼ コンバージョンの計上遅れに備えて、毎回取り直す直近の日数（7 / 14 / 30 など。0 で無効）
+const LOOKBACK_DAYS = 7;
+
+// ▼設定▼ デバイス・曜日で分けて記録する場合に指定します（カンマ区切り。空欄なら分けない）
+//   'device' … デバイス別 / 'dayOfWeek' … 曜日別（年齢・性別のレポートは時間帯では分けられません）
+// ※日付の右隣に列が追加されます。途中から指定した場合、それまでの行の分割の列は空欄です（共通/README.md 参照）。
+const SEGMENTS = '';
+
+// --- データセット定義 ---
+const GENDER_DATASET = {
+  supportedSegments: ['device', 'dayOfWeek'],
+  columns: [
+    { key: 'segments.date', label: '日付', type: 'date' },
+    { key: 'campaign.name', label: 'キャンペーン名', type: 'text' },
+    { key: 'ad_group.name', label: '広告グループ名', type: 'text' },
+    { key: 'ad_group_criterion.gender.type', label: '性別', type: 'text', enum: 'gender' },
+    { key: 'metrics.impressions', label: '表示回数', type: 'number' },
+    { key: 'metrics.clicks', label: 'クリック数', type: 'number' },
+    { key: 'metrics.cost_micros', label: '費用', type: 'number' },
+    { key: 'metrics.conversions', label: 'コンバージョン数', type: 'number' }
+  ],
+  keyHeaders: ['日付', 'キャンペーン名', '広告グループ名', '性別'],
+  fetchRows: function (range) {
+    const query = `
+      SELECT
+        segments.date${segmentSelectClause(GENDER_DATASET)},
+        campaign.name,
+        ad_group.name,
+        ad_group_criterion.gender.type,
+        metrics.impressions,
+        metrics.clicks,
+        metrics.cost_micros,
+        metrics.conversions
+      FROM gender_view
+      WHERE
+        segments.date >= '${range.startDate}'
+        AND segments.date <= '${range.endDate}'
+    `;
+
+    return reportRows(query).map(row => [row['segments.date']].concat(segmentValues(GENDER_DATASET, row), [
+      row['campaign.name'],
+      row['ad_group.name'],
+      row['ad_group_criterion.gender.type'],
+      row['metrics.impressions'],
+      row['metrics.clicks'],
+      microsToYen(row['metrics.cost_micros']),
+      row['metrics.conversions']
+    ]));
+  }
+};
+
+function main() {
+  runSync(GENDER_DATASET, {
+    spreadsheetUrl: SPREADSHEET_URL,
+    sheetName: SHEET_NAME,
+    mode: MODE,
+    startDate: START_DATE,
+    endDate: END_DATE,
+    targetYear: TARGET_YEAR,
+    lookbackDays: LOOKBACK_DAYS,
+    segments: SEGMENTS
+  });
+}
@@ -0,0 +1,76 @@
+/**
+ * 【時間帯別データ取得】
+ * 日付・時間帯（0〜23時）・キャンペーン・デバイス別の表示回数・クリック数・費用・コンバージョン数を取得し、
+ * シート全体を日付順に並べ替えます。
+ * ★曜日は日付から分かりますが、シート上で集計しやすいよう列として記録します。
+ * ★「Google広告用レポート」のHTMLレポートで、時間帯×曜日のヒートマップに使います。
+ * ★「共通/同期処理.go」を同じスクリプトに貼り付けて実行してください。
+ */
+
+// ▼▼【要設定】▼▼ 記録したいスプレッドシートのURLを貼り付けてください
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼設定▼ 記録先のシート名を指定してください
+const SHEET_NAME = '時間帯別データ';
+
+// ▼▼【要設定】▼▼ 取得方法を選んでください
+//   'daily' … 未取得の期間を追記（毎日のトリガー実行用）
+//   'range' … START_DATE から END_DATE までを取得
+//   'year'  … TARGET_YEAR の1年分を取得
+//   'all'   … アカウントの配信開始日から取得
+// ※'daily' 以外は1か月ずつ取得し、途中で止まった場合は次回の実行で続きから再開します。
+// ※時間帯別のデータは行数が多い（1日あたり キャンペーン数×デバイス数×最大24行）ため、過去分は 'year' や 'range' で分けて取得してください。
+// ※記録先スプレッドシートの「設定」シートに値がある場合は、そちらが優先されます（共通/README.md 参照）。
+const MODE = 'daily';
+const START_DATE = '2024-01-01'; // 'range' のときの開始日
+const END_DATE = '';             // 'range' のときの終了日（空欄なら取得できる最新日まで）
+const TARGET_YEAR = 2025;        // 'year' のときに取得する年
+
+// ▼設定▼ コンバージョンの計上遅れに備えて、毎回取り直す直近の日数（7 / 14 / 30 など。0 で無効）
+const LOOKBACK_DAYS = 7;
+
+// --- データセット定義 ---
+const HOURLY_COLUMNS = [
+  { key: 'segments.date', label: '日付', type: 'date' },
+  { key: 'segments.hour', label: '時間帯', type: 'number', format: '0' },
+  { key: 'segments.day_of_week', label: '曜日', type: 'text', enum: 'dayOfWeek' },
+  { key: 'campaign.id', label: 'キャンペーンID', type: 'id' },
+  { key: 'campaign.name', label: 'キャンペーン名', type: 'text' },
+  { key: 'campaign.advertising_channel_type', label: '広告チャネルタイプ', type: 'text', enum: 'channelType' },
+  { key: 'segments.device', label: 'デバイス', type: 'text', enum: 'device' },
+  { key: 'metrics.impressions', label: '表示回数', type: 'number' },
+  { key: 'metrics.clicks', label: 'クリック数', type: 'number' },
+  { key: 'metrics.cost_micros', label: '費用', type: 'number' },
+  { key: 'metrics.conversions', label: 'コンバージョン数', type: 'number' }
+];
+const HOURLY_API_FIELDS = HOURLY_COLUMNS.map(column => column.key);
+
+const HOURLY_DATASET = {
+  columns: HOURLY_COLUMNS,
+  keyHeaders: ['日付', '時間帯', 'キャンペーンID', 'デバイス'],
+  fetchRows: function (range) {
+    // 表示回数が0の時間帯は記録しない（行数を抑えるため）
+    const query =
+      'SELECT ' + HOURLY_API_FIELDS.join(', ') + ' ' +
+      'FROM campaign ' +
+      `WHERE segments.date BETWEEN '${range.startDate}' AND '${range.endDate}' ` +
+      'AND metrics.impressions > 0 ' +
+      'ORDER BY segments.date ASC, segments.hour ASC';
+
+    return reportRowsToValues(reportRows(query), HOURLY_API_FIELDS, {
+      'metrics.cost_micros': microsToYen
+    });
+  }
+};
+
+function main() {
+  runSync(HOURLY_DATASET, {
+    spreadsheetUrl: SPREADSHEET_URL,
+    sheetName: SHEET_NAME,
+    mode: MODE,
+    startDate: START_DATE,
+    endDate: END_DATE,
+    targetYear: TARGET_YEAR,
+    lookbackDays: LOOKBACK_DAYS
+  });
+}
@@ -40,37 +40,13 @@ function main() {
       return;
     }
 
-    // ④-1 アカウント全体の合計費用を取得
-    const totalCostQuery = `SELECT Month, Cost FROM ACCOUNT_PERFORMANCE_REPORT DURING ${startDate},${endDate}`;
-    const totalReport = AdsApp.report(totalCostQuery);
-    const totalRows = totalReport.rows();
-    const totalCosts = {};
-    while (totalRows.hasNext()) {
-      const row = totalRows.next();
-      totalCosts[row["Month"]] = parseFloat(row["Cost"].replace(/,/g, ''));
-    }
-
-    // ④-2 キャンペーン別の費用を取得
-    const campaignCostQuery = `SELECT CampaignName, Month, Cost FROM CAMPAIGN_PERFORMANCE_REPORT DURING ${startDate},${endDate}`;
-    const campaignReport = AdsApp.report(campaignCostQuery);
-    const campaignRows = campaignReport.rows();
-
+    // ④ アカウント全体の合計費用と、キャンペーン別の費用を取得（GAQL）
+    //    ※移行前（AWQL）と数値が変わっていないかは、checkGaqlMigration() を実行して確認できます
     // ⑤ データを整形
-    const monthlyCampaignData = {};
-    const campaignSet = new Set();
-
-    while (campaignRows.hasNext()) {
-      const row = campaignRows.next();
-      const campaignName = row["CampaignName"];
-      const month = row["Month"];
-      const cost = parseFloat(row["Cost"].replace(/,/g, ''));
-
-      if (!monthlyCampaignData[month]) {
-        monthlyCampaignData[month] = {};
-      }
-      monthlyCampaignData[month][campaignName] = cost;
-      campaignSet.add(campaignName);
-    }
+    const costs = fetchMonthlyCosts(startDate, endDate);
+    const totalCosts = costs.totalCosts;
+    const monthlyCampaignData = costs.monthlyCampaignData;
+    const campaignSet = costs.campaignSet;
 
     if (Object.keys(totalCosts).length === 0) {
       Logger.log("期間内に広告費用データが見つかりませんでした。");
@@ -133,4 +109,118 @@ function main() {
   } catch (e) {
     Logger.log(`エラーが発生しました: ${e.message} (Line: ${e.lineNumber})`);
   }
-}
\ No newline at end of file
+}
+
+/**
+ * 月別の合計費用と、キャンペーン別の費用をGAQLで取得する
+ * 月（segments.month）は AWQL の Month と同じ「yyyy-MM-01」の形式で返ります。
+ * @param {string} startDate - 開始日（yyyyMMdd）
+ * @param {string} endDate - 終了日（yyyyMMdd）
+ * @returns {{totalCosts: Object, monthlyCampaignData: Object, campaignSet: Set}} 月 → 費用、月 → キャンペーン名 → 費用、キャンペーン名の一覧
+ */
+function fetchMonthlyCosts(startDate, endDate) {
+  const during = `segments.date BETWEEN '${toGaqlDate(startDate)}' AND '${toGaqlDate(endDate)}'`;
+
+  const totalCosts = {};
+  const totalRows = AdsApp.report(`SELECT segments.month, metrics.cost_micros FROM customer WHERE ${during}`).rows();
+  while (totalRows.hasNext()) {
+    const row = totalRows.next();
+    totalCosts[row["segments.month"]] = Number(row["metrics.cost_micros"]) / 1000000;
+  }
+
+  // AWQLのレポートと同じく、表示回数が0の行は含めない（費用が0円でも表示があったキャンペーンは列を作る）
+  const monthlyCampaignData = {};
+  const campaignSet = new Set();
+  const campaignRows = AdsApp.report(
+    `SELECT campaign.name, segments.month, metrics.cost_micros FROM campaign WHERE ${during} AND metrics.impressions > 0`
+  ).rows();
+  while (campaignRows.hasNext()) {
+    const row = campaignRows.next();
+    const campaignName = row["campaign.name"];
+    const month = row["segments.month"];
+    if (!monthlyCampaignData[month]) {
+      monthlyCampaignData[month] = {};
+    }
+    monthlyCampaignData[month][campaignName] = Number(row["metrics.cost_micros"]) / 1000000;
+    campaignSet.add(campaignName);
+  }
+
+  return { totalCosts: totalCosts, monthlyCampaignData: monthlyCampaignData, campaignSet: campaignSet };
+}
+
+/**
+ * 移行前（AWQL）のクエリで、fetchMonthlyCosts() と同じ形の結果を取得する（移行チェック用）
+ */
+function fetchLegacyMonthlyCosts(startDate, endDate) {
+  const totalCosts = {};
+  const totalRows = AdsApp.report(`SELECT Month, Cost FROM ACCOUNT_PERFORMANCE_REPORT DURING ${startDate},${endDate}`).rows();
+  while (totalRows.hasNext()) {
+    const row = totalRows.next();
+    totalCosts[row["Month"]] = parseFloat(row["Cost"].replace(/,/g, ''));
+  }
+
+  const monthlyCampaignData = {};
+  const campaignSet = new Set();
+  const campaignRows = AdsApp.report(`SELECT CampaignName, Month, Cost FROM CAMPAIGN_PERFORMANCE_REPORT DURING ${startDate},${endDate}`).rows();
+  while (campaignRows.hasNext()) {
+    const row = campaignRows.next();
+    if (!monthlyCampaignData[row["Month"]]) {
+      monthlyCampaignData[row["Month"]] = {};
+    }
+    monthlyCampaignData[row["Month"]][row["CampaignName"]] = parseFloat(row["Cost"].replace(/,/g, ''));
+    campaignSet.add(row["CampaignName"]);
+  }
+
+  return { totalCosts: totalCosts, monthlyCampaignData: monthlyCampaignData, campaignSet: campaignSet };
+}
+
+/**
+ * 【移行チェック】直近12か月分を旧AWQLと新GAQLの両方で取得し、月別・キャンペーン別の費用の差分をログに出力する
+ * シートには書き込みません。実行する関数に「checkGaqlMigration」を選んで実行してください。
+ */
+function checkGaqlMigration() {
+  try {
+    const today = new Date();
+    const firstDay = new Date(today.getFullYear(), today.getMonth() - 12, 1);
+    const lastDay = new Date(today.getFullYear(), today.getMonth(), 0);
+    const format = date => date.getFullYear() + ("0" + (date.getMonth() + 1)).slice(-2) + ("0" + date.getDate()).slice(-2);
+    const startDate = format(firstDay);
+    const endDate = format(lastDay);
+
+    const legacy = fetchLegacyMonthlyCosts(startDate, endDate);
+    const current = fetchMonthlyCosts(startDate, endDate);
+    const differences = [];
+    const isSame = (a, b) => Math.abs((a || 0) - (b || 0)) <= 0.01;
+
+    const months = Array.from(new Set(Object.keys(legacy.totalCosts).concat(Object.keys(current.totalCosts)))).sort();
+    months.forEach(month => {
+      if (!isSame(legacy.totalCosts[month], current.totalCosts[month])) {
+        differences.push(`${month} 合計: AWQL ${legacy.totalCosts[month] || 0} / GAQL ${current.totalCosts[month] || 0}`);
+      }
+      const campaigns = new Set(Object.keys(legacy.monthlyCampaignData[month] || {}).concat(Object.keys(current.monthlyCampaignData[month] || {})));
+      campaigns.forEach(campaignName => {
+        const before = (legacy.monthlyCampaignData[month] || {})[campaignName];
+        const after = (current.monthlyCampaignData[month] || {})[campaignName];
+        if (before === undefined || after === undefined || !isSame(before, after)) {
+          differences.push(`${month} ${campaignName}: AWQL ${before === undefined ? '（行なし）' : before} / GAQL ${after === undefined ? '（行なし）' : after}`);
+        }
+      });
+    });
+
+    Logger.log(`【移行チェック】${startDate}〜${endDate}（${months.length}か月・キャンペーン AWQL ${legacy.campaignSet.size}件 / GAQL ${current.campaignSet.size}件）`);
+    if (differences.length === 0) {
+      Logger.log("結果: 月別の合計費用・キャンペーン別の費用はすべて一致しました。");
+    } else {
+      Logger.log(`結果: ${differences.length}件の差分があります。\n- ${differences.join('\n- ')}`);
+    }
+  } catch (e) {
+    Logger.log(`エラーが発生しました: ${e.message} (Line: ${e.lineNumber})`);
+  }
+}
+
+/**
+ * yyyyMMdd の日付を、GAQLで使う yyyy-MM-dd の形式に変換する
+ */
+function toGaqlDate(dateString) {
+  return dateString.replace(/^(\d{4})(\d{2})(\d{2})$/, '$1-$2-$3');
+}
@@ -74,8 +74,12 @@ function main() {
       return;
     }
 
-    // ④ キャンペーン別の費用を取得 (元のコードのまま)
-    const campaignCostQuery = `SELECT Month, CampaignName, Cost FROM CAMPAIGN_PERFORMANCE_REPORT DURING ${startDate},${endDate}`;
+    // ④ キャンペーン別の費用を取得（GAQL。月は AWQL の Month と同じ「yyyy-MM-01」で返る）
+    //    ※移行前（AWQL）との差分は「月々の費用取得.go」の checkGaqlMigration() で確認できます（キャンペーン別の費用は同じ条件で取得しています）
+    const toGaqlDate = dateString => dateString.replace(/^(\d{4})(\d{2})(\d{2})$/, '$1-$2-$3');
+    const campaignCostQuery =
+      `SELECT segments.month, campaign.name, metrics.cost_micros FROM campaign ` +
+      `WHERE segments.date BETWEEN '${toGaqlDate(startDate)}' AND '${toGaqlDate(endDate)}' AND metrics.impressions > 0`;
     const report = AdsApp.report(campaignCostQuery);
     const rows = report.rows();
 
@@ -83,9 +87,9 @@ function main() {
     const dataToAppend = [];
     while (rows.hasNext()) {
       const row = rows.next();
-      const date = new Date(row["Month"]);
-      const cost = parseFloat(row["Cost"].replace(/,/g, ''));
-      const campaignName = row["CampaignName"];
+      const date = new Date(row["segments.month"]);
+      const cost = Number(row["metrics.cost_micros"]) / 1000000;
+      const campaignName = row["campaign.name"];
 
       if (cost > 0) {
         dataToAppend.push([date, cost, campaignName]);
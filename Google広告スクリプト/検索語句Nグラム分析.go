@@ -0,0 +1,284 @@
+/**
+ * 【検索語句Nグラム分析】
+ * 「検索語句データ取得.go」で記録した検索語句を単語に分け、1〜3語の組み合わせ（Nグラム）ごとに実績を集計します。
+ * コンバージョンのない検索語句にかかった費用（無駄な費用）の多い順に「Nグラム集計」シートへ書き出し、
+ * 条件を満たしたものを「除外キーワード候補」シートに一覧にします（登録は「除外キーワード自動追加.go」のルールで行います）。
+ * ★日本語の検索語句は、スペースのほかに漢字・ひらがな・カタカナ・英数字の切れ目で単語に分けます。
+ *   「東京の脱毛サロン」→「東京」「脱毛」「サロン」（「の」「で」などの助詞は除きます）
+ *   形態素解析は行わないため、「脱毛するなら」のように語尾の付いた言葉は1語として扱われます。
+ * ★「共通/」フォルダのファイルを同じスクリプトに貼り付けて実行してください（週1回のトリガー実行を想定しています）。
+ */
+
+// ▼▼【要設定】▼▼ 検索語句データを記録しているスプレッドシートのURLを貼り付けてください
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼設定▼ 読み込むシートと、書き出すシートの名前
+const SOURCE_SHEET_NAME = '検索語句データ';
+const NGRAM_SHEET_NAME = 'Nグラム集計';
+const CANDIDATE_SHEET_NAME = '除外キーワード候補';
+
+// ▼設定▼ 集計する期間（昨日から遡る日数）
+const ANALYSIS_DAYS = 30;
+
+// ▼設定▼ 何語の組み合わせまで集計するか（1〜3）
+const MAX_NGRAM_SIZE = 3;
+
+// ▼設定▼ 除外キーワード候補にする条件（コンバージョンが0件で、次の両方を満たすもの）
+const MIN_WASTED_COST = 3000; // 無駄な費用（円）がこの金額以上
+const MIN_CLICKS = 5;         // クリック数がこの回数以上
+
+// ▼設定▼ 除外キーワード候補にしない語句（ブランド名など。この語句を含む組み合わせも候補にしません）
+const PROTECTED_WORDS = [];
+
+// ▼設定▼ 「Nグラム集計」シートに書き出す最大の行数
+const MAX_NGRAM_ROWS = 1000;
+
+// ▼設定▼ 文字の種類が混ざっていても1語として扱う言葉（「口コミ」が「口」「コミ」に分かれないように）
+const NGRAM_COMPOUND_WORDS = ['口コミ', 'お試し', 'お得', 'お問い合わせ'];
+
+// 単語の区切りとして扱う助詞（ひらがなだけの並びがこれと一致したら除く）
+const NGRAM_PARTICLES = ['の', 'は', 'が', 'を', 'に', 'へ', 'と', 'で', 'や', 'も', 'から', 'まで', 'より'];
+
+// 「主な検索語句」「主なキャンペーン」に表示する件数
+const NGRAM_EXAMPLE_COUNT = 3;
+
+const NGRAM_HEADERS = ['Nグラム', '語数', '検索語句数', '表示回数', 'クリック数', '費用', 'コンバージョン数', 'コンバージョン単価', '無駄な費用', '主な検索語句'];
+const CANDIDATE_HEADERS = ['除外キーワード', '推奨マッチタイプ', '語数', '無駄な費用', 'クリック数', '検索語句数', '主なキャンペーン', '主な検索語句', '集計期間'];
+
+function main() {
+  try {
+    const spreadsheet = openSpreadsheet(SPREADSHEET_URL);
+    const sourceSheet = spreadsheet.getSheetByName(SOURCE_SHEET_NAME);
+    if (!sourceSheet) {
+      throw new Error(`「${SOURCE_SHEET_NAME}」シートが見つかりません。先に「検索語句データ取得.go」を実行してください。`);
+    }
+
+    const timezone = AdsApp.currentAccount().getTimeZone();
+    const endDate = addDays(todayString(timezone), -1);
+    const startDate = addDays(endDate, -(ANALYSIS_DAYS - 1));
+    const period = `${startDate}〜${endDate}`;
+    console.log(`集計期間: ${period}`);
+
+    const searchTerms = loadSearchTerms(sourceSheet, startDate, endDate, timezone);
+    console.log(`検索語句: ${searchTerms.length}件`);
+
+    const ngrams = aggregateNgrams(searchTerms, MAX_NGRAM_SIZE);
+    const candidates = selectNegativeCandidates(ngrams, {
+      minWastedCost: MIN_WASTED_COST,
+      minClicks: MIN_CLICKS,
+      protectedWords: PROTECTED_WORDS
+    });
+
+    writeSnapshot(getOrCreateSheet(spreadsheet, NGRAM_SHEET_NAME), NGRAM_HEADERS,
+      ngrams.slice(0, MAX_NGRAM_ROWS).map(ngram => [
+        ngram.text,
+        ngram.size,
+        ngram.queryCount,
+        ngram.impressions,
+        ngram.clicks,
+        Math.round(ngram.cost),
+        ngram.conversions,
+        ngram.conversions > 0 ? Math.round(ngram.cost / ngram.conversions) : '',
+        Math.round(ngram.wastedCost),
+        topNames(ngram.queries, NGRAM_EXAMPLE_COUNT).join('、')
+      ]));
+    writeSnapshot(getOrCreateSheet(spreadsheet, CANDIDATE_SHEET_NAME), CANDIDATE_HEADERS,
+      candidates.map(ngram => [
+        ngram.text,
+        ngram.size === 1 ? '部分一致' : 'フレーズ一致',
+        ngram.size,
+        Math.round(ngram.wastedCost),
+        ngram.clicks,
+        ngram.queryCount,
+        topNames(ngram.campaigns, NGRAM_EXAMPLE_COUNT).join('、'),
+        topNames(ngram.queries, NGRAM_EXAMPLE_COUNT).join('、'),
+        period
+      ]));
+
+    console.log(`${ngrams.length}件のNグラムを集計し、除外キーワード候補を${candidates.length}件 書き出しました。`);
+  } catch (e) {
+    console.error('スクリプトの実行中にエラーが発生しました: ' + e.toString());
+    console.error('エラー詳細: ' + e.stack);
+  }
+}
+
+/**
+ * 検索語句データのシートから、期間内の行を検索語句ごとに合算して読み込む
+ * 除外済みの検索語句は除きます。費用のない P-MAX のカテゴリ（取得元「P-MAX」）は集計に含めます
+ * （カテゴリでコンバージョンしている語句を、除外キーワードの候補にしないため）。
+ * @returns {Array<Object>} 検索語句ごとの実績（text, tokens, impressions, clicks, cost, conversions, campaigns）
+ */
+function loadSearchTerms(sheet, startDate, endDate, timezone) {
+  const values = sheet.getDataRange().getValues();
+  const headers = values[0].map(value => String(value).trim());
+  const index = {};
+  ['日付', 'キャンペーン名', '検索語句', '表示回数', 'クリック数', '費用', 'コンバージョン数'].forEach(header => {
+    index[header] = headers.indexOf(header);
+    if (index[header] === -1) {
+      throw new Error(`「${sheet.getName()}」シートに「${header}」列がありません。`);
+    }
+  });
+  const statusIndex = headers.indexOf('追加・除外');
+
+  const terms = new Map();
+  values.slice(1).forEach(row => {
+    const date = toDateString(row[index['日付']], timezone);
+    if (!date || date < startDate || date > endDate) return;
+    // すでに除外キーワードに登録されている語句は、候補に出しても意味がないため除く
+    if (statusIndex !== -1 && ['EXCLUDED', 'ADDED_EXCLUDED'].indexOf(toEnumCode('searchTermStatus', row[statusIndex])) !== -1) return;
+
+    const text = normalizeSearchTerm(row[index['検索語句']]);
+    if (!text) return;
+    if (!terms.has(text)) {
+      terms.set(text, { text: text, tokens: tokenizeSearchTerm(text), impressions: 0, clicks: 0, cost: 0, conversions: 0, campaigns: new Map() });
+    }
+    const term = terms.get(text);
+    const cost = toNumber(row[index['費用']]);
+    term.impressions += toNumber(row[index['表示回数']]);
+    term.clicks += toNumber(row[index['クリック数']]);
+    term.cost += cost;
+    term.conversions += toNumber(row[index['コンバージョン数']]);
+    addToTotal(term.campaigns, String(row[index['キャンペーン名']]), cost);
+  });
+  return Array.from(terms.values());
+}
+
+/**
+ * 検索語句の表記を揃える（全角英数字・記号を半角に、半角カナを全角に、英字を小文字に、連続する空白を1つに）
+ */
+function normalizeSearchTerm(value) {
+  return String(value).normalize('NFKC').toLowerCase().replace(/\s+/g, ' ').trim();
+}
+
+/**
+ * 検索語句を単語に分ける
+ * スペース・記号で区切ったうえで、文字の種類（漢字・ひらがな・カタカナ・英数字）が変わるところでも区切ります。
+ * 漢字の直後のひらがなは送り仮名として漢字と合わせ、助詞（NGRAM_PARTICLES）だけのひらがなは除きます。
+ * NGRAM_COMPOUND_WORDS の言葉は、文字の種類が混ざっていても1語として扱います。
+ * @param {string} text - normalizeSearchTerm() で表記を揃えた検索語句
+ * @returns {Array<string>} 単語の一覧
+ */
+function tokenizeSearchTerm(text) {
+  // 1語として扱う言葉を先に照合してから、文字の種類ごとの並びに分ける
+  const compounds = NGRAM_COMPOUND_WORDS
+    .map(normalizeSearchTerm)
+    .sort((a, b) => b.length - a.length)
+    .map(word => word.replace(/[.*+?^${}()|[\]\\\-]/g, '\\$&'));
+  const runPattern = new RegExp(compounds.concat([
+    '[一-鿿々〆ヶ]+', '[ぁ-ゟ]+', '[゠-ヿ]+', "[a-z0-9&'+\\-]+", "[^一-鿿々〆ヶぁ-ゟ゠-ヿa-z0-9&'+\\-]+"
+  ]).join('|'), 'g');
+  const tokens = [];
+  text.split(/[\s、。,.・!?「」()\[\]\/]+/).forEach(chunk => {
+    const runs = chunk.match(runPattern) || [];
+    let previousKanji = false;
+    runs.forEach(run => {
+      const isHiragana = /^[ぁ-ゟ]+$/.test(run);
+      if (isHiragana && NGRAM_PARTICLES.indexOf(run) !== -1) {
+        previousKanji = false;
+        return;
+      }
+      if (isHiragana && previousKanji) {
+        tokens[tokens.length - 1] += run;
+      } else {
+        tokens.push(run);
+      }
+      previousKanji = /^[一-鿿々〆ヶ]+$/.test(run);
+    });
+  });
+  return tokens;
+}
+
+/**
+ * 検索語句の単語から、1〜maxSize 語の連続した組み合わせ（Nグラム）を作る（同じ組み合わせは1回だけ）
+ */
+function buildNgrams(tokens, maxSize) {
+  const ngrams = new Set();
+  for (let size = 1; size <= maxSize; size++) {
+    for (let i = 0; i + size <= tokens.length; i++) {
+      ngrams.add(tokens.slice(i, i + size).join(' '));
+    }
+  }
+  return Array.from(ngrams);
+}
+
+/**
+ * 検索語句の実績を、Nグラムごとに集計する
+ * 無駄な費用は、そのNグラムを含む検索語句のうち、コンバージョンが0件のものの費用の合計です。
+ * @param {Array<Object>} searchTerms - loadSearchTerms() の結果
+ * @param {number} maxSize - 何語の組み合わせまで集計するか
+ * @returns {Array<Object>} Nグラムごとの実績（無駄な費用の多い順）
+ */
+function aggregateNgrams(searchTerms, maxSize) {
+  const ngrams = new Map();
+  searchTerms.forEach(term => {
+    buildNgrams(term.tokens, maxSize).forEach(text => {
+      if (!ngrams.has(text)) {
+        ngrams.set(text, { text: text, size: text.split(' ').length, queryCount: 0, impressions: 0, clicks: 0, cost: 0, conversions: 0, wastedCost: 0, queries: new Map(), campaigns: new Map() });
+      }
+      const ngram = ngrams.get(text);
+      ngram.queryCount++;
+      ngram.impressions += term.impressions;
+      ngram.clicks += term.clicks;
+      ngram.cost += term.cost;
+      ngram.conversions += term.conversions;
+      if (term.conversions === 0) {
+        ngram.wastedCost += term.cost;
+      }
+      addToTotal(ngram.queries, term.text, term.cost);
+      term.campaigns.forEach((cost, name) => addToTotal(ngram.campaigns, name, cost));
+    });
+  });
+  return Array.from(ngrams.values()).sort((a, b) => b.wastedCost - a.wastedCost || b.clicks - a.clicks);
+}
+
+/**
+ * 除外キーワードの候補を選ぶ
+ * コンバージョンが0件で、無駄な費用・クリック数が基準以上のNグラムを候補にします。
+ * 候補にした短いNグラムを含む長いNグラム（「無料」を候補にしたときの「無料 体験」など）は、除外しても効果が変わらないため除きます。
+ * @param {Array<Object>} ngrams - aggregateNgrams() の結果
+ * @param {Object} options - minWastedCost, minClicks, protectedWords
+ * @returns {Array<Object>} 候補のNグラム（無駄な費用の多い順）
+ */
+function selectNegativeCandidates(ngrams, options) {
+  const protectedWords = options.protectedWords.map(normalizeSearchTerm).filter(Boolean);
+  const selected = [];
+  ngrams
+    .filter(ngram => ngram.conversions === 0 && ngram.wastedCost >= options.minWastedCost && ngram.clicks >= options.minClicks)
+    .filter(ngram => !protectedWords.some(word => ngram.text.replace(/ /g, '').indexOf(word.replace(/ /g, '')) !== -1))
+    .sort((a, b) => a.size - b.size)
+    .forEach(ngram => {
+      const padded = ` ${ngram.text} `;
+      if (!selected.some(shorter => padded.indexOf(` ${shorter.text} `) !== -1)) {
+        selected.push(ngram);
+      }
+    });
+  return selected.sort((a, b) => b.wastedCost - a.wastedCost || b.clicks - a.clicks);
+}
+
+/**
+ * 名前ごとの合計に値を加える
+ */
+function addToTotal(totals, name, value) {
+  totals.set(name, (totals.get(name) || 0) + value);
+}
+
+/**
+ * 合計の大きい順に、名前を指定した件数だけ返す
+ */
+function topNames(totals, count) {
+  return Array.from(totals.entries())
+    .sort((a, b) => b[1] - a[1])
+    .slice(0, count)
+    .map(entry => entry[0]);
+}
+
+/**
+ * シートの内容を、見出し行と集計結果で置き換える
+ */
+function writeSnapshot(sheet, headers, rows) {
+  sheet.clearContents();
+  const values = [headers].concat(rows);
+  sheet.getRange(1, 1, values.length, headers.length).setValues(values);
+  sheet.getRange(1, 1, 1, headers.length).setFontWeight('bold');
+  sheet.setFrozenRows(1);
+}
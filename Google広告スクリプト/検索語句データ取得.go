@@ -0,0 +1,155 @@
+/**
+ * 【検索語句データ取得】
+ * 実際に検索された語句（検索語句レポート）を、日付・キャンペーン・広告グループ・マッチタイプ別に取得し、
+ * シート全体を日付順に並べ替えます。
+ * ★P-MAX キャンペーンは検索語句ごとの実績を取得できないため、「検索語句の分析情報」のカテゴリ別に記録します（取得元「P-MAX」）。
+ *   カテゴリ別の実績には費用がないため、費用の列は空欄です。
+ * ★記録したシートは「検索語句Nグラム分析.go」で、除外キーワードの候補を探すのに使います。
+ * ★「共通/同期処理.go」を同じスクリプトに貼り付けて実行してください。
+ */
+
+// ▼▼【要設定】▼▼ 記録したいスプレッドシートのURLを貼り付けてください
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼設定▼ 記録先のシート名を指定してください
+const SHEET_NAME = '検索語句データ';
+
+// ▼▼【要設定】▼▼ 取得方法を選んでください
+//   'daily' … 未取得の期間を追記（毎日のトリガー実行用）
+//   'range' … START_DATE から END_DATE までを取得
+//   'year'  … TARGET_YEAR の1年分を取得
+//   'all'   … アカウントの配信開始日から取得
+// ※'daily' 以外は1か月ずつ取得し、途中で止まった場合は次回の実行で続きから再開します。
+// ※記録先スプレッドシートの「設定」シートに値がある場合は、そちらが優先されます（共通/README.md 参照）。
+const MODE = 'daily';
+const START_DATE = '2024-01-01'; // 'range' のときの開始日
+const END_DATE = '';             // 'range' のときの終了日（空欄なら取得できる最新日まで）
+const TARGET_YEAR = 2025;        // 'year' のときに取得する年
+
+// ▼設定▼ コンバージョンの計上遅れに備えて、毎回取り直す直近の日数（7 / 14 / 30 など。0 で無効）
+const LOOKBACK_DAYS = 7;
+
+// ▼設定▼ P-MAX キャンペーンの検索語句カテゴリも記録する場合は true
+// ※キャンペーン・日ごとに取得するため、P-MAX キャンペーンが多いと実行時間が長くなります。
+const INCLUDE_PMAX_INSIGHTS = true;
+
+// 取得元の列に記録する値
+const SEARCH_TERM_SOURCES = {
+  searchTerm: '検索語句',
+  pmax: 'P-MAX'
+};
+
+// P-MAX のカテゴリ名が空欄（分類されていない語句）のときに記録する値
+const PMAX_UNCATEGORIZED_LABEL = '（分類なし）';
+
+// --- データセット定義 ---
+const SEARCH_TERM_COLUMNS = [
+  { key: 'segments.date', label: '日付', type: 'date' },
+  { key: 'source', label: '取得元', type: 'text' },
+  { key: 'campaign.id', label: 'キャンペーンID', type: 'id' },
+  { key: 'campaign.name', label: 'キャンペーン名', type: 'text' },
+  { key: 'ad_group.id', label: '広告グループID', type: 'id' },
+  { key: 'ad_group.name', label: '広告グループ名', type: 'text' },
+  { key: 'search_term_view.search_term', label: '検索語句', type: 'text', format: '@' },
+  { key: 'segments.search_term_match_type', label: 'マッチタイプ', type: 'text', enum: 'searchTermMatchType' },
+  { key: 'search_term_view.status', label: '追加・除外', type: 'text', enum: 'searchTermStatus' },
+  { key: 'metrics.impressions', label: '表示回数', type: 'number' },
+  { key: 'metrics.clicks', label: 'クリック数', type: 'number' },
+  { key: 'metrics.cost_micros', label: '費用', type: 'number' },
+  { key: 'metrics.conversions', label: 'コンバージョン数', type: 'number' },
+  { key: 'metrics.conversions_value', label: 'コンバージョン価値', type: 'number', previousLabels: ['コンバージョン値'] }
+];
+
+const SEARCH_TERM_DATASET = {
+  columns: SEARCH_TERM_COLUMNS,
+  keyHeaders: ['日付', '取得元', 'キャンペーンID', '広告グループID', '検索語句', 'マッチタイプ'],
+  fetchRows: function (range) {
+    const rows = fetchSearchTermRows(range);
+    if (INCLUDE_PMAX_INSIGHTS) {
+      const insightRows = fetchPmaxInsightRows(range);
+      console.log(`検索語句: ${rows.length}件 / P-MAX の検索語句カテゴリ: ${insightRows.length}件`);
+      return rows.concat(insightRows);
+    }
+    return rows;
+  }
+};
+
+/**
+ * 検索語句レポート（search_term_view）を取得する
+ */
+function fetchSearchTermRows(range) {
+  const fields = SEARCH_TERM_COLUMNS.map(column => column.key).filter(key => key !== 'source');
+  const query =
+    'SELECT ' + fields.join(', ') + ' ' +
+    'FROM search_term_view ' +
+    `WHERE segments.date BETWEEN '${range.startDate}' AND '${range.endDate}' ` +
+    'AND metrics.impressions > 0 ' +
+    'ORDER BY segments.date ASC';
+
+  return reportRowsToValues(reportRows(query), fields, {
+    'metrics.cost_micros': microsToYen
+  }).map(values => [values[0], SEARCH_TERM_SOURCES.searchTerm].concat(values.slice(1)));
+}
+
+/**
+ * P-MAX キャンペーンの検索語句カテゴリ（campaign_search_term_insight）を取得する
+ * このレポートは1つのキャンペーンずつ・日付で分けずに集計されるため、配信のあった日ごとに1日分ずつ取得します。
+ */
+function fetchPmaxInsightRows(range) {
+  const servingQuery = `
+    SELECT segments.date, campaign.id, campaign.name
+    FROM campaign
+    WHERE campaign.advertising_channel_type = 'PERFORMANCE_MAX'
+      AND segments.date BETWEEN '${range.startDate}' AND '${range.endDate}'
+      AND metrics.impressions > 0
+    ORDER BY segments.date ASC
+  `;
+
+  const rows = [];
+  for (const serving of reportRows(servingQuery)) {
+    const date = serving['segments.date'];
+    const insightQuery = `
+      SELECT
+        campaign_search_term_insight.category_label,
+        metrics.impressions,
+        metrics.clicks,
+        metrics.conversions,
+        metrics.conversions_value
+      FROM campaign_search_term_insight
+      WHERE segments.date BETWEEN '${date}' AND '${date}'
+        AND campaign_search_term_insight.campaign_id = '${serving['campaign.id']}'
+    `;
+    for (const insight of reportRows(insightQuery)) {
+      if (!(parseFloat(insight['metrics.impressions']) > 0)) continue;
+      rows.push([
+        date,
+        SEARCH_TERM_SOURCES.pmax,
+        serving['campaign.id'],
+        serving['campaign.name'],
+        '',
+        '',
+        insight['campaign_search_term_insight.category_label'] || PMAX_UNCATEGORIZED_LABEL,
+        '',
+        '',
+        parseFloat(insight['metrics.impressions']) || 0,
+        parseFloat(insight['metrics.clicks']) || 0,
+        '', // カテゴリ別の費用は取得できない
+        parseFloat(insight['metrics.conversions']) || 0,
+        parseFloat(insight['metrics.conversions_value']) || 0
+      ]);
+    }
+  }
+  return rows;
+}
+
+function main() {
+  runSync(SEARCH_TERM_DATASET, {
+    spreadsheetUrl: SPREADSHEET_URL,
+    sheetName: SHEET_NAME,
+    mode: MODE,
+    startDate: START_DATE,
+    endDate: END_DATE,
+    targetYear: TARGET_YEAR,
+    lookbackDays: LOOKBACK_DAYS
+  });
+}
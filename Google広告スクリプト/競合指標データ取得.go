@@ -0,0 +1,190 @@
+/**
+ * 【競合指標データ取得】
+ * 検索キャンペーンのインプレッションシェアと、オークション分析の競合ドメイン別の指標を、週（月曜始まり）・キャンペーン別に取得し、
+ * シート全体を週の順に並べ替えます。
+ * 自社の行（表示URLドメインが「（自社）」）には、インプレッションシェア・上部のインプレッションシェア・損失率（予算・ランク）を記録します。
+ * 競合の行には、競合ドメインのインプレッションシェア・重複率・上位掲載率・優位表示シェア・ページ上部表示率を記録します。
+ * ★オークション分析の指標は、APIで提供されているアカウントでのみ取得できます。取得できない場合は自社の行だけを記録します。
+ * ★週の途中までの行は、次回以降の実行でその週の初日から取り直して置き換えます。
+ * ★「Google広告用レポート」のHTMLレポートで、インプレッションシェアと競合の推移に使います。
+ * ★「共通/同期処理.go」を同じスクリプトに貼り付けて実行してください。
+ */
+
+// ▼▼【要設定】▼▼ 記録したいスプレッドシートのURLを貼り付けてください
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼設定▼ 記録先のシート名を指定してください
+const SHEET_NAME = '競合指標データ';
+
+// ▼▼【要設定】▼▼ 取得方法を選んでください
+//   'daily' … 未取得の期間を追記（毎日のトリガー実行用）
+//   'range' … START_DATE から END_DATE までを取得
+//   'year'  … TARGET_YEAR の1年分を取得
+//   'all'   … アカウントの配信開始日から取得
+// ※'daily' 以外は1か月ずつ取得し、途中で止まった場合は次回の実行で続きから再開します。
+// ※記録先スプレッドシートの「設定」シートに値がある場合は、そちらが優先されます（共通/README.md 参照）。
+const MODE = 'daily';
+const START_DATE = '2024-01-01'; // 'range' のときの開始日
+const END_DATE = '';             // 'range' のときの終了日（空欄なら取得できる最新日まで）
+const TARGET_YEAR = 2025;        // 'year' のときに取得する年
+
+// ▼設定▼ インプレッションシェアは数日後に確定するため、毎回取り直す直近の日数（7 / 14 など。0 で無効）
+const LOOKBACK_DAYS = 7;
+
+// ▼設定▼ オークション分析（競合ドメイン別の指標）も記録する場合は true
+const INCLUDE_AUCTION_INSIGHTS = true;
+
+// 自社の行の「表示URLドメイン」に記録する値
+const OWN_DOMAIN_LABEL = '（自社）';
+
+// --- データセット定義 ---
+const COMPETITION_DATASET = {
+  columns: [
+    { key: 'segments.week', label: '週', type: 'date' },
+    { key: 'campaign.id', label: 'キャンペーンID', type: 'id' },
+    { key: 'campaign.name', label: 'キャンペーン名', type: 'text' },
+    { key: 'segments.auction_insight_domain', label: '表示URLドメイン', type: 'text' },
+    { key: 'metrics.impressions', label: '表示回数', type: 'number' },
+    { key: 'metrics.clicks', label: 'クリック数', type: 'number' },
+    { key: 'metrics.cost_micros', label: '費用', type: 'number' },
+    { key: 'metrics.search_impression_share', label: '検索IS', type: 'percent' },
+    { key: 'metrics.search_top_impression_share', label: '検索TOP IS', type: 'percent' },
+    { key: 'metrics.search_absolute_top_impression_share', label: '検索Abs.TOP IS', type: 'percent' },
+    { key: 'metrics.search_budget_lost_impression_share', label: '検索IS損失率(予算)', type: 'percent' },
+    { key: 'metrics.search_rank_lost_impression_share', label: '検索IS損失率(ランク)', type: 'percent' },
+    { key: 'metrics.auction_insight_search_overlap_rate', label: '重複率', type: 'percent' },
+    { key: 'metrics.auction_insight_search_position_above_rate', label: '上位掲載率', type: 'percent' },
+    { key: 'metrics.auction_insight_search_outranking_share', label: '優位表示シェア', type: 'percent' },
+    { key: 'metrics.auction_insight_search_top_impression_percentage', label: 'ページ上部表示率', type: 'percent' },
+    { key: 'metrics.auction_insight_search_absolute_top_impression_percentage', label: 'ページ最上部表示率', type: 'percent' }
+  ],
+  keyHeaders: ['週', 'キャンペーンID', '表示URLドメイン'],
+  fetchRows: function (range) {
+    // 週の途中から取得すると週の一部だけの行で置き換えてしまうため、開始日をその週の月曜日まで広げる
+    const weekRange = { startDate: weekStartOf(range.startDate), endDate: range.endDate };
+    const rows = fetchOwnShareRows(weekRange);
+    if (INCLUDE_AUCTION_INSIGHTS && !auctionInsightsUnavailable) {
+      const insightRows = fetchAuctionInsightRows(weekRange);
+      console.log(`自社: ${rows.length}件 / 競合ドメイン: ${insightRows.length}件`);
+      return rows.concat(insightRows);
+    }
+    return rows;
+  }
+};
+
+// オークション分析を取得できないアカウントで、月ごとに同じエラーを繰り返さないための目印
+let auctionInsightsUnavailable = false;
+
+/**
+ * 自社の検索キャンペーンのインプレッションシェアを、週ごとに取得する
+ */
+function fetchOwnShareRows(range) {
+  const query = `
+    SELECT
+      segments.week,
+      campaign.id,
+      campaign.name,
+      metrics.impressions,
+      metrics.clicks,
+      metrics.cost_micros,
+      metrics.search_impression_share,
+      metrics.search_top_impression_share,
+      metrics.search_absolute_top_impression_share,
+      metrics.search_budget_lost_impression_share,
+      metrics.search_rank_lost_impression_share
+    FROM campaign
+    WHERE campaign.advertising_channel_type = 'SEARCH'
+      AND segments.date BETWEEN '${range.startDate}' AND '${range.endDate}'
+      AND metrics.impressions > 0
+    ORDER BY segments.week ASC
+  `;
+  return reportRows(query).map(row => [
+    row['segments.week'],
+    row['campaign.id'],
+    row['campaign.name'],
+    OWN_DOMAIN_LABEL,
+    parseFloat(row['metrics.impressions']) || 0,
+    parseFloat(row['metrics.clicks']) || 0,
+    microsToYen(row['metrics.cost_micros']),
+    toShareValue(row['metrics.search_impression_share']),
+    toShareValue(row['metrics.search_top_impression_share']),
+    toShareValue(row['metrics.search_absolute_top_impression_share']),
+    toShareValue(row['metrics.search_budget_lost_impression_share']),
+    toShareValue(row['metrics.search_rank_lost_impression_share']),
+    '', '', '', '', ''
+  ]);
+}
+
+/**
+ * オークション分析の競合ドメイン別の指標を、週ごとに取得する（取得できないアカウントでは空の配列を返す）
+ */
+function fetchAuctionInsightRows(range) {
+  const query = `
+    SELECT
+      segments.week,
+      campaign.id,
+      campaign.name,
+      segments.auction_insight_domain,
+      metrics.auction_insight_search_impression_share,
+      metrics.auction_insight_search_overlap_rate,
+      metrics.auction_insight_search_position_above_rate,
+      metrics.auction_insight_search_outranking_share,
+      metrics.auction_insight_search_top_impression_percentage,
+      metrics.auction_insight_search_absolute_top_impression_percentage
+    FROM campaign
+    WHERE campaign.advertising_channel_type = 'SEARCH'
+      AND segments.date BETWEEN '${range.startDate}' AND '${range.endDate}'
+    ORDER BY segments.week ASC
+  `;
+  let rows;
+  try {
+    rows = reportRows(query);
+  } catch (e) {
+    auctionInsightsUnavailable = true;
+    console.warn('このアカウントではオークション分析の指標を取得できないため、自社のインプレッションシェアのみ記録します: ' + e.message);
+    return [];
+  }
+  return rows.filter(row => row['segments.auction_insight_domain']).map(row => [
+    row['segments.week'],
+    row['campaign.id'],
+    row['campaign.name'],
+    row['segments.auction_insight_domain'],
+    '', '', '',
+    toShareValue(row['metrics.auction_insight_search_impression_share']),
+    '', '', '', '',
+    toShareValue(row['metrics.auction_insight_search_overlap_rate']),
+    toShareValue(row['metrics.auction_insight_search_position_above_rate']),
+    toShareValue(row['metrics.auction_insight_search_outranking_share']),
+    toShareValue(row['metrics.auction_insight_search_top_impression_percentage']),
+    toShareValue(row['metrics.auction_insight_search_absolute_top_impression_percentage'])
+  ]);
+}
+
+/**
+ * 割合の値を数値にする（対象外・データ不足で値がない場合は空欄）
+ */
+function toShareValue(value) {
+  const share = parseFloat(value);
+  return isNaN(share) ? '' : share;
+}
+
+/**
+ * yyyy-MM-dd 形式の日付を、その週の月曜日にする
+ */
+function weekStartOf(dateString) {
+  const parts = dateString.split('-').map(Number);
+  const dayOfWeek = new Date(Date.UTC(parts[0], parts[1] - 1, parts[2])).getUTCDay();
+  return addDays(dateString, -((dayOfWeek + 6) % 7));
+}
+
+function main() {
+  runSync(COMPETITION_DATASET, {
+    spreadsheetUrl: SPREADSHEET_URL,
+    sheetName: SHEET_NAME,
+    mode: MODE,
+    startDate: START_DATE,
+    endDate: END_DATE,
+    targetYear: TARGET_YEAR,
+    lookbackDays: LOOKBACK_DAYS
+  });
+}
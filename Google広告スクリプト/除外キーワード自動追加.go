@@ -0,0 +1,576 @@
+/**
+ * 【除外キーワード自動追加】
+ * 「除外ルール」シートに書いた条件（例:「30日間で費用5,000円以上・コンバージョン0件」「求人を含む」）を検索語句レポートに当てはめ、
+ * 該当した検索語句を、キャンペーンまたは共有の除外キーワードリストに追加します。
+ * RUN_MODE が 'preview' のときは、追加案を「除外キーワードの追加案」シートに書き出すだけで、除外キーワードは追加しません。
+ * 'apply' にすると追加案のとおりに追加し、追加した除外キーワードを1件ずつ「除外キーワードの変更履歴」シートに記録します。
+ * ★初回の実行で「除外ルール」シートを作成し、記入例を書き込みます（記入例は「有効」が FALSE のため使われません）。
+ * ★ルールに誤りがある場合は、どのルールも当てはめずにエラーで終了します（ログに行番号と原因が表示されます）。
+ * ★「検索語句データ取得.go」で記録した「検索語句データ」シートが集計期間をすべて含んでいれば、検索語句レポートを取得せずにそのシートを使います。
+ *   含んでいない場合は、最も長いルールの期間の検索語句レポートを1回だけ取得し、ルールごとの期間で集計します。
+ * ★「共通/」フォルダのファイルを同じスクリプトに貼り付けて実行してください。
+ */
+
+// ▼▼【要設定】▼▼ ルールと結果を記録するスプレッドシートのURLを貼り付けてください
+const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+
+// ▼▼【要設定】▼▼ 実行方法を選んでください
+//   'preview' … 追加案をシートに書き出すだけ（除外キーワードは追加しません）
+//   'apply'   … 追加案のとおりに除外キーワードを追加し、変更履歴に記録します
+// ※まず 'preview' で追加案を確認してから 'apply' に切り替えてください。
+const RUN_MODE = 'preview';
+
+// ▼設定▼ シート名
+const RULE_SHEET_NAME = '除外ルール';
+const PROPOSAL_SHEET_NAME = '除外キーワードの追加案';
+const CHANGE_LOG_SHEET_NAME = '除外キーワードの変更履歴';
+
+// ▼設定▼ 「検索語句データ取得.go」で検索語句を記録しているシート名（同じスプレッドシート内）
+const SEARCH_TERM_SHEET_NAME = '検索語句データ';
+
+// ▼設定▼ ルールの「期間（日）」が空欄のときに集計する日数（昨日から遡る）
+const DEFAULT_RULE_DAYS = 30;
+
+// ▼設定▼ 1回の実行で追加する除外キーワードの上限（ルールの誤りで大量に追加されるのを防ぐため）
+const MAX_CHANGES_PER_RUN = 100;
+
+const RUN_MODES = ['preview', 'apply'];
+
+// 「除外ルール」シートの見出し
+const RULE_HEADERS = ['有効', 'ルール名', '対象キャンペーン', '期間（日）', '検索語句に含む', '費用（円）以上', 'クリック数以上', 'コンバージョン数以下', '除外する語句', 'マッチタイプ', '追加先', 'メモ'];
+
+// 「除外ルール」シートを作成するときに書き込む記入例
+const RULE_EXAMPLES = [
+  [false, 'CVなしで費用がかさんだ語句', '', 30, '', 5000, '', 0, '検索語句', '完全一致', 'キャンペーン', '30日間で費用5,000円以上・コンバージョン0件の検索語句を、その検索語句で除外'],
+  [false, '求人・アルバイト', '', 30, '求人、バイト', '', '', '', '含む語句', 'フレーズ一致', 'キャンペーン', '「求人」「バイト」を含む検索語句を、含んでいた語句で除外']
+];
+
+// 「除外する語句」に指定できる値
+//   検索語句 … 該当した検索語句そのものを除外する
+//   含む語句 … 「検索語句に含む」の語句（例: 求人）を除外する
+const NEGATIVE_TERM_SOURCES = ['検索語句', '含む語句'];
+
+// マッチタイプごとの除外キーワードの書き方
+const NEGATIVE_MATCH_TYPES = {
+  '完全一致': text => `[${text}]`,
+  'フレーズ一致': text => `"${text}"`,
+  '部分一致': text => text
+};
+
+// 「追加先」をキャンペーンにするときの値（それ以外の値は、共有の除外キーワードリストの名前として扱う）
+const CAMPAIGN_TARGET = 'キャンペーン';
+
+// 除外キーワードとして追加できる長さ（Google広告の上限）
+const NEGATIVE_MAX_LENGTH = 80;
+const NEGATIVE_MAX_WORDS = 10;
+
+// 除外キーワードに使えない記号（追加するとGoogle広告でエラーになるため、preview の時点で「エラー」にします）
+const NEGATIVE_INVALID_CHARACTERS = ['!', '@', '%', ',', '*', '^', '=', ';', '~', '`', '<', '>', '?', '\\', '|', '(', ')', '{', '}', '[', ']', '"'];
+
+// 追加案の状態
+const NEGATIVE_STATUS = {
+  planned: '追加予定',
+  added: '追加済み',
+  exists: '登録済み',
+  overLimit: '上限のため見送り',
+  error: 'エラー'
+};
+
+const PROPOSAL_HEADERS = ['記録日時', 'ルール名', 'キャンペーン名', '除外キーワード', 'マッチタイプ', '追加先', '該当した検索語句', '検索語句数', '費用', 'クリック数', 'コンバージョン数', '状態'];
+
+function main() {
+  try {
+    if (RUN_MODES.indexOf(RUN_MODE) === -1) {
+      throw new Error(`RUN_MODE「${RUN_MODE}」には対応していません（${RUN_MODES.join(' / ')}）。`);
+    }
+    const spreadsheet = openSpreadsheet(SPREADSHEET_URL);
+    const ruleSheet = spreadsheet.getSheetByName(RULE_SHEET_NAME);
+    if (!ruleSheet) {
+      createRuleSheet(spreadsheet);
+      console.log(`「${RULE_SHEET_NAME}」シートを作成しました。ルールを入力し、「有効」を TRUE にしてから再実行してください。`);
+      return;
+    }
+
+    const rules = readNegativeRules(ruleSheet).filter(rule => rule.enabled);
+    if (rules.length === 0) {
+      console.log('有効なルールがありません。「除外ルール」シートの「有効」を TRUE にしてください。');
+      return;
+    }
+
+    const timezone = AdsApp.currentAccount().getTimeZone();
+    const endDate = addDays(todayString(timezone), -1);
+    const longestDays = Math.max.apply(null, rules.map(rule => rule.days));
+    const records = loadSearchTermRecords(spreadsheet, addDays(endDate, -(longestDays - 1)), endDate, timezone);
+    const proposals = buildNegativeProposals(rules, endDate, records);
+    console.log(`${rules.length}件のルールから、${proposals.length}件の除外キーワードの追加案を作成しました。`);
+
+    resolveNegativeProposals(proposals, RUN_MODE === 'apply');
+
+    const recordedAt = Utilities.formatDate(new Date(), timezone, 'yyyy-MM-dd HH:mm:ss');
+    const rows = proposals.map(proposal => proposalToRow(proposal, recordedAt));
+    writeProposalSheet(getOrCreateSheet(spreadsheet, PROPOSAL_SHEET_NAME), rows);
+
+    if (RUN_MODE === 'apply') {
+      // 追加を試みた除外キーワード（成功・失敗とも）を1件ずつ残す
+      const changes = rows.filter((row, i) => [NEGATIVE_STATUS.added, NEGATIVE_STATUS.error].indexOf(proposals[i].status) !== -1);
+      if (changes.length > 0) {
+        const logSheet = getOrCreateSheet(spreadsheet, CHANGE_LOG_SHEET_NAME);
+        if (logSheet.getLastRow() === 0) {
+          logSheet.appendRow(PROPOSAL_HEADERS);
+          logSheet.getRange(1, 1, 1, PROPOSAL_HEADERS.length).setFontWeight('bold');
+        }
+        appendRows(logSheet, changes);
+      }
+      if (AdsApp.getExecutionInfo().isPreview()) {
+        console.log('※スクリプトの「プレビュー」で実行したため、除外キーワードは実際には追加されていません。');
+      }
+    }
+
+    const counts = {};
+    proposals.forEach(proposal => {
+      counts[proposal.status] = (counts[proposal.status] || 0) + 1;
+    });
+    console.log('結果: ' + (Object.keys(counts).map(status => `${status} ${counts[status]}件`).join(' / ') || '追加案なし'));
+  } catch (e) {
+    console.error('スクリプトの実行中にエラーが発生しました: ' + e.toString());
+    console.error('エラー詳細: ' + e.stack);
+  }
+}
+
+// --------------------------------------------------------------------------------
+// ルールの読み込み
+// --------------------------------------------------------------------------------
+
+/**
+ * 「除外ルール」シートを作成し、見出しと記入例を書き込む
+ */
+function createRuleSheet(spreadsheet) {
+  const sheet = spreadsheet.insertSheet(RULE_SHEET_NAME);
+  const values = [RULE_HEADERS].concat(RULE_EXAMPLES);
+  sheet.getRange(1, 1, values.length, RULE_HEADERS.length).setValues(values);
+  sheet.getRange(1, 1, 1, RULE_HEADERS.length).setFontWeight('bold');
+  sheet.setFrozenRows(1);
+}
+
+/**
+ * 「除外ルール」シートを読み込み、すべての行を検証する
+ * 誤りのある行が1つでもあれば、誤って除外キーワードを追加しないよう、すべての誤りをまとめてエラーにします。
+ * @returns {Array<Object>} ルールの一覧（enabled, name, campaignFilter, days, contains, minCost, minClicks, maxConversions, termSource, matchType, target）
+ */
+function readNegativeRules(sheet) {
+  const values = sheet.getDataRange().getValues();
+  const headers = values[0].map(value => String(value).trim());
+  const missing = RULE_HEADERS.filter(header => header !== 'メモ' && headers.indexOf(header) === -1);
+  if (missing.length > 0) {
+    throw new Error(`「${sheet.getName()}」シートに列「${missing.join('、')}」がありません。`);
+  }
+  const cell = (row, header) => row[headers.indexOf(header)];
+
+  const errors = [];
+  const rules = [];
+  values.slice(1).forEach((row, i) => {
+    if (row.every(value => String(value).trim() === '')) return;
+    const name = String(cell(row, 'ルール名')).trim() || `${i + 2}行目のルール`;
+    const rowErrors = [];
+    const number = header => {
+      const value = cell(row, header);
+      if (String(value).trim() === '') return null;
+      const parsed = Number(String(value).replace(/[,¥￥円]/g, ''));
+      if (!isFinite(parsed) || parsed < 0) {
+        rowErrors.push(`「${header}」は0以上の数値で指定してください（${value}）`);
+        return null;
+      }
+      return parsed;
+    };
+
+    const contains = String(cell(row, '検索語句に含む')).split(/[,、]/).map(normalizeNegativeText).filter(Boolean);
+    const rule = {
+      enabled: cell(row, '有効') === true || ['TRUE', '有効', 'はい', '○'].indexOf(String(cell(row, '有効')).trim().toUpperCase()) !== -1,
+      name: name,
+      campaignFilter: String(cell(row, '対象キャンペーン')).trim(),
+      days: Math.floor(number('期間（日）')) || DEFAULT_RULE_DAYS,
+      contains: contains,
+      minCost: number('費用（円）以上'),
+      minClicks: number('クリック数以上'),
+      maxConversions: number('コンバージョン数以下'),
+      termSource: String(cell(row, '除外する語句')).trim() || (contains.length > 0 ? '含む語句' : '検索語句'),
+      matchType: String(cell(row, 'マッチタイプ')).trim(),
+      target: String(cell(row, '追加先')).trim() || CAMPAIGN_TARGET
+    };
+    rule.matchType = rule.matchType || (rule.termSource === '検索語句' ? '完全一致' : 'フレーズ一致');
+
+    if (rule.contains.length === 0 && rule.minCost === null && rule.minClicks === null) {
+      // コンバージョン数だけの条件では、ほとんどの検索語句が該当してしまう
+      rowErrors.push('「検索語句に含む」「費用（円）以上」「クリック数以上」のいずれかを指定してください');
+    }
+    if (NEGATIVE_TERM_SOURCES.indexOf(rule.termSource) === -1) {
+      rowErrors.push(`「除外する語句」は ${NEGATIVE_TERM_SOURCES.join(' / ')} のいずれかを指定してください（${rule.termSource}）`);
+    } else if (rule.termSource === '含む語句' && rule.contains.length === 0) {
+      rowErrors.push('「除外する語句」を「含む語句」にする場合は、「検索語句に含む」を指定してください');
+    }
+    if (!Object.prototype.hasOwnProperty.call(NEGATIVE_MATCH_TYPES, rule.matchType)) {
+      rowErrors.push(`「マッチタイプ」は ${Object.keys(NEGATIVE_MATCH_TYPES).join(' / ')} のいずれかを指定してください（${rule.matchType}）`);
+    }
+
+    rowErrors.forEach(message => errors.push(`${i + 2}行目（${name}）: ${message}`));
+    rules.push(rule);
+  });
+
+  if (errors.length > 0) {
+    throw new Error(`「${sheet.getName()}」シートのルールに誤りがあるため、処理を中止しました。\n- ` + errors.join('\n- '));
+  }
+  return rules;
+}
+
+/**
+ * 検索語句・除外キーワードを比べるために表記を揃える（全角英数字を半角に、英字を小文字に、連続する空白を1つに）
+ */
+function normalizeNegativeText(value) {
+  return String(value).normalize('NFKC').toLowerCase().replace(/\s+/g, ' ').trim();
+}
+
+// --------------------------------------------------------------------------------
+// ルールの当てはめ
+// --------------------------------------------------------------------------------
+
+/**
+ * 各ルールを検索語句の実績に当てはめ、除外キーワードの追加案を作る
+ * 同じ追加先に同じ除外キーワードを追加する案は1つにまとめ、該当した検索語句の実績を合算します。
+ * @param {Array<Object>} rules - 有効なルール
+ * @param {string} endDate - 集計期間の終了日（yyyy-MM-dd）
+ * @param {Array<Object>} records - loadSearchTermRecords() の結果（最も長いルールの期間の、日別の検索語句の実績）
+ * @returns {Array<Object>} 追加案（費用の多い順）
+ */
+function buildNegativeProposals(rules, endDate, records) {
+  const termsByDays = new Map();
+  const proposals = new Map();
+
+  rules.forEach(rule => {
+    if (!termsByDays.has(rule.days)) {
+      termsByDays.set(rule.days, totalSearchTerms(records, addDays(endDate, -(rule.days - 1)), endDate));
+    }
+    termsByDays.get(rule.days).forEach(term => {
+      if (term.excluded) return;
+      if (rule.campaignFilter && term.campaignName.indexOf(rule.campaignFilter) === -1) return;
+      const matchedWord = rule.contains.find(word => term.normalized.indexOf(word) !== -1);
+      if (rule.contains.length > 0 && !matchedWord) return;
+      if (rule.minCost !== null && term.cost < rule.minCost) return;
+      if (rule.minClicks !== null && term.clicks < rule.minClicks) return;
+      if (rule.maxConversions !== null && term.conversions > rule.maxConversions) return;
+
+      const keyword = NEGATIVE_MATCH_TYPES[rule.matchType](rule.termSource === '検索語句' ? term.text : matchedWord);
+      const isCampaignTarget = rule.target === CAMPAIGN_TARGET;
+      const key = [rule.target, isCampaignTarget ? term.campaignId : '', normalizeNegativeText(keyword)].join('|');
+      if (!proposals.has(key)) {
+        proposals.set(key, {
+          ruleName: rule.name,
+          keyword: keyword,
+          matchType: rule.matchType,
+          target: rule.target,
+          campaignId: isCampaignTarget ? term.campaignId : null,
+          campaignNames: new Set(),
+          searchTerms: [],
+          cost: 0,
+          clicks: 0,
+          conversions: 0,
+          status: NEGATIVE_STATUS.planned,
+          message: ''
+        });
+      }
+      const proposal = proposals.get(key);
+      proposal.campaignNames.add(term.campaignName);
+      proposal.searchTerms.push(term.text);
+      proposal.cost += term.cost;
+      proposal.clicks += term.clicks;
+      proposal.conversions += term.conversions;
+    });
+  });
+
+  return Array.from(proposals.values()).sort((a, b) => b.cost - a.cost);
+}
+
+/**
+ * 期間内の検索語句の実績を、日付×キャンペーン×検索語句ごとに読み込む
+ * 「検索語句データ」シートに期間内のすべての日が記録されていればシートから、そうでなければ検索語句レポートから読み込みます。
+ * @returns {Array<Object>} 日別の実績（date, campaignId, campaignName, text, excluded, cost, clicks, conversions）
+ */
+function loadSearchTermRecords(spreadsheet, startDate, endDate, timezone) {
+  const sheet = spreadsheet.getSheetByName(SEARCH_TERM_SHEET_NAME);
+  if (sheet) {
+    const records = readSearchTermSheet(sheet, startDate, endDate, timezone);
+    if (records) {
+      console.log(`「${SEARCH_TERM_SHEET_NAME}」シートから ${startDate} から ${endDate} の検索語句を読み込みました（${records.length}行）。`);
+      return records;
+    }
+  }
+  return fetchSearchTermRecords(startDate, endDate);
+}
+
+/**
+ * 「検索語句データ」シートから期間内の行を読み込む（期間内に記録されていない日がある場合や、必要な列がない場合は null）
+ * P-MAX の検索語句カテゴリ（取得元「P-MAX」）は検索語句ではないため、読み込みません。
+ * 「追加・除外」は記録した時点の状態のため、その後に登録した除外キーワードは追加案の「登録済み」で確認します。
+ */
+function readSearchTermSheet(sheet, startDate, endDate, timezone) {
+  const lastDate = getSyncWatermark(sheet.getParent(), sheet.getName()) || getLastStoredDate(sheet);
+  if (!lastDate || lastDate < endDate) {
+    console.log(`「${sheet.getName()}」シートに ${endDate} までの検索語句が記録されていないため、検索語句レポートから取得します。`);
+    return null;
+  }
+  const values = sheet.getDataRange().getValues();
+  const headers = values[0].map(value => String(value).trim());
+  const index = {};
+  const missing = ['日付', '取得元', 'キャンペーンID', 'キャンペーン名', '検索語句', '追加・除外', 'クリック数', '費用', 'コンバージョン数'].filter(header => {
+    index[header] = headers.indexOf(header);
+    return index[header] === -1;
+  });
+  if (missing.length > 0) {
+    console.log(`「${sheet.getName()}」シートに列「${missing.join('、')}」がないため、検索語句レポートから取得します。`);
+    return null;
+  }
+
+  const records = [];
+  let firstDate = null;
+  values.slice(1).forEach(row => {
+    const date = toDateString(row[index['日付']], timezone);
+    if (!date) return;
+    if (!firstDate || date < firstDate) {
+      firstDate = date;
+    }
+    if (date < startDate || date > endDate || String(row[index['取得元']]).trim() !== '検索語句') return;
+    records.push({
+      date: date,
+      campaignId: String(row[index['キャンペーンID']]),
+      campaignName: String(row[index['キャンペーン名']]),
+      text: String(row[index['検索語句']]),
+      excluded: ['EXCLUDED', 'ADDED_EXCLUDED'].indexOf(toEnumCode('searchTermStatus', row[index['追加・除外']])) !== -1,
+      cost: toNumber(row[index['費用']]),
+      clicks: toNumber(row[index['クリック数']]),
+      conversions: toNumber(row[index['コンバージョン数']])
+    });
+  });
+  if (!firstDate || firstDate > startDate) {
+    console.log(`「${sheet.getName()}」シートに ${startDate} からの検索語句が記録されていないため、検索語句レポートから取得します。`);
+    return null;
+  }
+  return records;
+}
+
+/**
+ * 検索語句レポートから、期間内の検索語句の実績を日別に取得する
+ */
+function fetchSearchTermRecords(startDate, endDate) {
+  const query = `
+    SELECT
+      segments.date,
+      campaign.id,
+      campaign.name,
+      search_term_view.search_term,
+      search_term_view.status,
+      metrics.clicks,
+      metrics.cost_micros,
+      metrics.conversions
+    FROM search_term_view
+    WHERE segments.date BETWEEN '${startDate}' AND '${endDate}'
+      AND metrics.impressions > 0
+  `;
+  const records = [];
+  for (const row of reportRows(query)) {
+    records.push({
+      date: row['segments.date'],
+      campaignId: String(row['campaign.id']),
+      campaignName: String(row['campaign.name']),
+      text: String(row['search_term_view.search_term']),
+      excluded: ['EXCLUDED', 'ADDED_EXCLUDED'].indexOf(row['search_term_view.status']) !== -1,
+      cost: microsToYen(row['metrics.cost_micros']),
+      clicks: parseFloat(row['metrics.clicks']) || 0,
+      conversions: parseFloat(row['metrics.conversions']) || 0
+    });
+  }
+  console.log(`検索語句レポートから ${startDate} から ${endDate} の検索語句を取得しました（${records.length}行）。`);
+  return records;
+}
+
+/**
+ * 期間内の検索語句の実績を、キャンペーン×検索語句ごとに合算する
+ * 広告グループ・マッチタイプ・日付が違っても同じ検索語句として扱い、いずれかで除外済みのものには excluded を付けます。
+ */
+function totalSearchTerms(records, startDate, endDate) {
+  const terms = new Map();
+  records.forEach(record => {
+    if (record.date < startDate || record.date > endDate) return;
+    const key = `${record.campaignId}|${normalizeNegativeText(record.text)}`;
+    if (!terms.has(key)) {
+      terms.set(key, {
+        campaignId: record.campaignId,
+        campaignName: record.campaignName,
+        text: record.text,
+        normalized: normalizeNegativeText(record.text),
+        excluded: false,
+        cost: 0,
+        clicks: 0,
+        conversions: 0
+      });
+    }
+    const term = terms.get(key);
+    term.excluded = term.excluded || record.excluded;
+    term.cost += record.cost;
+    term.clicks += record.clicks;
+    term.conversions += record.conversions;
+  });
+  console.log(`${startDate} から ${endDate} の検索語句: ${terms.size}件`);
+  return Array.from(terms.values());
+}
+
+// --------------------------------------------------------------------------------
+// 除外キーワードの追加
+// --------------------------------------------------------------------------------
+
+/**
+ * 追加案ごとに、追加先の既存の除外キーワードと照らし合わせ、apply のときは追加する
+ * 結果は各追加案の status（NEGATIVE_STATUS）と message に記録します。
+ * @param {Array<Object>} proposals - buildNegativeProposals() の結果
+ * @param {boolean} apply - true のときは除外キーワードを追加する
+ */
+function resolveNegativeProposals(proposals, apply) {
+  const targets = new Map();
+  let changeCount = 0;
+
+  proposals.forEach(proposal => {
+    const targetKey = proposal.campaignId ? `campaign|${proposal.campaignId}` : `list|${proposal.target}`;
+    if (!targets.has(targetKey)) {
+      targets.set(targetKey, proposal.campaignId ? findCampaignNegatives(proposal.campaignId) : findListNegatives(proposal.target));
+    }
+    const target = targets.get(targetKey);
+    const text = proposal.keyword.replace(/^[\["]|[\]"]$/g, '');
+    const problem = findNegativeKeywordProblem(text);
+
+    if (target.error) {
+      proposal.status = NEGATIVE_STATUS.error;
+      proposal.message = target.error;
+    } else if (target.keywords.has(normalizeNegativeText(proposal.keyword))) {
+      proposal.status = NEGATIVE_STATUS.exists;
+    } else if (problem) {
+      proposal.status = NEGATIVE_STATUS.error;
+      proposal.message = problem;
+    } else if (changeCount >= MAX_CHANGES_PER_RUN) {
+      proposal.status = NEGATIVE_STATUS.overLimit;
+    } else {
+      changeCount++;
+      target.keywords.add(normalizeNegativeText(proposal.keyword));
+      if (apply) {
+        try {
+          target.add(proposal.keyword);
+          proposal.status = NEGATIVE_STATUS.added;
+          console.log(`追加しました: ${proposal.keyword}（${proposalTargetName(proposal)}）`);
+        } catch (e) {
+          proposal.status = NEGATIVE_STATUS.error;
+          proposal.message = e.toString();
+          console.error(`追加できませんでした: ${proposal.keyword}（${proposalTargetName(proposal)}）: ${e}`);
+        }
+      }
+    }
+  });
+  if (changeCount >= MAX_CHANGES_PER_RUN) {
+    console.warn(`1回の実行で追加する上限（${MAX_CHANGES_PER_RUN}件）に達したため、残りは次回の実行で追加します。`);
+  }
+}
+
+/**
+ * 除外キーワードとして追加できない理由を返す（追加できる場合は空文字）
+ * apply で追加したときにGoogle広告で拒否される語句を、preview の追加案でも同じように「エラー」にするための確認です。
+ * @param {string} text - マッチタイプの記号を除いた除外キーワード
+ */
+function findNegativeKeywordProblem(text) {
+  if (text.length > NEGATIVE_MAX_LENGTH || text.split(' ').length > NEGATIVE_MAX_WORDS) {
+    return `${NEGATIVE_MAX_LENGTH}文字・${NEGATIVE_MAX_WORDS}語を超えるため追加できません`;
+  }
+  const invalid = NEGATIVE_INVALID_CHARACTERS.filter(character => text.indexOf(character) !== -1);
+  if (invalid.length > 0) {
+    return `除外キーワードに使えない記号（${invalid.join(' ')}）を含むため追加できません`;
+  }
+  return '';
+}
+
+/**
+ * キャンペーンの除外キーワードの一覧と、追加する関数を返す
+ */
+function findCampaignNegatives(campaignId) {
+  const campaigns = AdsApp.campaigns().withIds([campaignId]).get();
+  if (!campaigns.hasNext()) {
+    return { error: `キャンペーン（ID: ${campaignId}）が見つかりません` };
+  }
+  const campaign = campaigns.next();
+  return {
+    keywords: collectNegativeTexts(campaign.negativeKeywords().get()),
+    add: text => campaign.createNegativeKeyword(text)
+  };
+}
+
+/**
+ * 共有の除外キーワードリストの除外キーワードの一覧と、追加する関数を返す
+ */
+function findListNegatives(listName) {
+  const lists = AdsApp.negativeKeywordLists().withCondition(`shared_set.name = '${listName.replace(/'/g, "\\'")}'`).get();
+  if (!lists.hasNext()) {
+    return { error: `除外キーワードリスト「${listName}」が見つかりません` };
+  }
+  const list = lists.next();
+  return {
+    keywords: collectNegativeTexts(list.negativeKeywords().get()),
+    add: text => list.addNegativeKeyword(text)
+  };
+}
+
+/**
+ * 除外キーワードのイテレーターから、表記を揃えた除外キーワード（マッチタイプの記号付き）の一覧を作る
+ */
+function collectNegativeTexts(iterator) {
+  const texts = new Set();
+  while (iterator.hasNext()) {
+    texts.add(normalizeNegativeText(iterator.next().getText()));
+  }
+  return texts;
+}
+
+// --------------------------------------------------------------------------------
+// シートへの書き出し
+// --------------------------------------------------------------------------------
+
+/**
+ * ログに表示する追加先の名前
+ */
+function proposalTargetName(proposal) {
+  return proposal.campaignId ? `キャンペーン: ${Array.from(proposal.campaignNames)[0]}` : `リスト: ${proposal.target}`;
+}
+
+/**
+ * 追加案を、追加案・変更履歴のシートに書き込む行にする
+ */
+function proposalToRow(proposal, recordedAt) {
+  return [
+    recordedAt,
+    proposal.ruleName,
+    Array.from(proposal.campaignNames).join('、'),
+    proposal.keyword,
+    proposal.matchType,
+    proposal.campaignId ? CAMPAIGN_TARGET : proposal.target,
+    proposal.searchTerms.slice(0, 3).join('、') + (proposal.searchTerms.length > 3 ? ' ほか' : ''),
+    proposal.searchTerms.length,
+    Math.round(proposal.cost),
+    proposal.clicks,
+    proposal.conversions,
+    proposal.message ? `${proposal.status}: ${proposal.message}` : proposal.status
+  ];
+}
+
+/**
+ * 「除外キーワードの追加案」シートを、今回の追加案で置き換える
+ */
+function writeProposalSheet(sheet, rows) {
+  sheet.clearContents();
+  const values = [PROPOSAL_HEADERS].concat(rows);
+  sheet.getRange(1, 1, values.length, PROPOSAL_HEADERS.length).setValues(values);
+  sheet.getRange(1, 1, 1, PROPOSAL_HEADERS.length).setFontWeight('bold');
+  sheet.setFrozenRows(1);
+}
@@ -45,10 +45,12 @@ function getReportData(refresh) {
 
     console.log('キャッシュが見つからないため、新しいレポートデータを生成します。');
 
-    const ss = SpreadsheetApp.openByUrl(SPREADSHEET_URL);
-    const baseSheet = ss.getSheetByName(SHEET_NAME_BASE);
-    const cvSheet = ss.getSheetByName(SHEET_NAME_CV);
-    const keywordSheet = ss.getSheetByName(SHEET_NAME_KEYWORD);
+    const config = getConfig();
+    const ss = SpreadsheetApp.openByUrl(config.SPREADSHEET_URL);
+    loadConversionRoles(ss, config.SHEET_NAME_CONVERSION_ACTIONS);
+    const baseSheet = ss.getSheetByName(config.SHEET_NAME_BASE);
+    const cvSheet = ss.getSheetByName(config.SHEET_NAME_CV);
+    const keywordSheet = ss.getSheetByName(config.SHEET_NAME_KEYWORD);
 
     if (!baseSheet || !cvSheet || !keywordSheet) {
       throw new Error(`必要なシートが見つかりません。`);
@@ -79,7 +81,19 @@ function getReportData(refresh) {
     );
     console.log("データ集計処理が完了しました。");
 
-    const reportData = { lastMonthData, prevMonthData, monthlyData };
+    // アセットグループデータのシートがある場合のみ、P-MAXのタブ用に集計する
+    let pmaxData = null;
+    const assetGroupSheet = ss.getSheetByName(config.SHEET_NAME_PMAX);
+    if (assetGroupSheet) {
+      const assetGroupData = assetGroupSheet.getDataRange().getValues();
+      const assetGroupHeaders = assetGroupData.shift();
+      pmaxData = {
+        lastMonth: aggregatePmaxAssetGroups(assetGroupData, assetGroupHeaders, cvData, cvHeaders, lastMonthStartDate, lastMonthEndDate),
+        prevMonth: aggregatePmaxAssetGroups(assetGroupData, assetGroupHeaders, cvData, cvHeaders, prevMonthStartDate, prevMonthEndDate)
+      };
+    }
+
+    const reportData = { lastMonthData, prevMonthData, monthlyData, pmaxData };
 
     cache.put(cacheKey, JSON.stringify(reportData), 21600); // 6時間キャッシュ
     console.log('新しいレポートデータを生成し、キャッシュに保存しました。');
@@ -99,23 +113,37 @@ function processAllData(baseData, cvData, keywordData, baseHeaders, cvHeaders, k
   const getIndex = (headers, name) => headers.indexOf(name);
   const col = {
     base: { date: getIndex(baseHeaders, '日付'), device: getIndex(baseHeaders, 'デバイス'), campaign: getIndex(baseHeaders, 'キャンペーン名'), channel: getIndex(baseHeaders, '広告チャネルタイプ'), cost: getIndex(baseHeaders, 'ご利用額'), clicks: getIndex(baseHeaders, 'クリック数'), imp: getIndex(baseHeaders, '表示回数'), },
-    cv: { date: getIndex(cvHeaders, '日付'), device: getIndex(cvHeaders, 'デバイス'), campaign: getIndex(cvHeaders, 'キャンペーン名'), action: getIndex(cvHeaders, 'コンバージョンアクション名'), cvs: getIndex(cvHeaders, 'コンバージョン数'), channel: getIndex(cvHeaders, '広告チャネルタイプ') },
+    cv: { date: getIndex(cvHeaders, '日付'), device: getIndex(cvHeaders, 'デバイス'), campaign: getIndex(cvHeaders, 'キャンペーン名'), action: getIndex(cvHeaders, 'コンバージョンアクション名'), cvs: getIndex(cvHeaders, 'コンバージョン数'), value: getIndex(cvHeaders, 'コンバージョン価値'), channel: getIndex(cvHeaders, '広告チャネルタイプ'), cvsByConversionDate: getIndex(cvHeaders, 'コンバージョン数（CV発生日）'), valueByConversionDate: getIndex(cvHeaders, 'コンバージョン価値（CV発生日）') },
     kw: { date: getIndex(keywordHeaders, '日付'), keyword: getIndex(keywordHeaders, 'キーワード'), match: getIndex(keywordHeaders, 'マッチタイプ'), cost: getIndex(keywordHeaders, 'ご利用額'), clicks: getIndex(keywordHeaders, 'クリック数'), cvs: getIndex(keywordHeaders, 'コンバージョン数'), }
   };
 
   const monthlyAgg = {};
+  const roleAgg = {};
+  // CV内訳データにCV発生日の列がある場合は、「今月発生したCV」もあわせて集計する（「コンバージョン数」はクリックした日の月に計上される）
+  const hasConversionDate = col.cv.cvsByConversionDate !== -1;
+  const newMonthlyEntry = () => ({ imp: 0, clicks: 0, cost: 0, cv: 0, value: 0, cvByConversionDate: 0, valueByConversionDate: 0 });
 
   cvData.forEach(row => {
     try {
       const rowDate = new Date(row[col.cv.date]);
       if (isNaN(rowDate.getTime())) return;
       const monthKey = Utilities.formatDate(rowDate, 'JST', 'yyyy-MM');
-      const actionName = row[col.cv.action] || '';
+      const role = getConversionRole(row[col.cv.action]);
       const channel = row[col.cv.channel];
 
-      if (channel === 'SEARCH' && !actionName.includes('中間')) {
-        if (!monthlyAgg[monthKey]) monthlyAgg[monthKey] = { imp: 0, clicks: 0, cost: 0, cv: 0 };
-        monthlyAgg[monthKey].cv += parseFloat(row[col.cv.cvs]) || 0;
+      if (isSearchChannel(channel)) {
+        const cvs = parseFloat(row[col.cv.cvs]) || 0;
+        if (!roleAgg[monthKey]) roleAgg[monthKey] = { primary: 0, intermediate: 0, micro: 0 };
+        roleAgg[monthKey][role] += cvs;
+        if (role === 'primary') {
+          if (!monthlyAgg[monthKey]) monthlyAgg[monthKey] = newMonthlyEntry();
+          monthlyAgg[monthKey].cv += cvs;
+          monthlyAgg[monthKey].value += toConversionValue(row, col.cv.value);
+          if (hasConversionDate) {
+            monthlyAgg[monthKey].cvByConversionDate += toConversionValue(row, col.cv.cvsByConversionDate);
+            monthlyAgg[monthKey].valueByConversionDate += toConversionValue(row, col.cv.valueByConversionDate);
+          }
+        }
       }
     } catch (e) { /* 無視 */ }
   });
@@ -124,9 +152,9 @@ function processAllData(baseData, cvData, keywordData, baseHeaders, cvHeaders, k
     try {
       const rowDate = new Date(row[col.base.date]);
       if (isNaN(rowDate.getTime())) return;
-      if (row[col.base.channel] === 'SEARCH') {
+      if (isSearchChannel(row[col.base.channel])) {
         const monthKey = Utilities.formatDate(rowDate, 'JST', 'yyyy-MM');
-        if (!monthlyAgg[monthKey]) monthlyAgg[monthKey] = { imp: 0, clicks: 0, cost: 0, cv: 0 };
+        if (!monthlyAgg[monthKey]) monthlyAgg[monthKey] = newMonthlyEntry();
         monthlyAgg[monthKey].imp += parseInt(row[col.base.imp]) || 0;
         monthlyAgg[monthKey].clicks += parseInt(row[col.base.clicks]) || 0;
         monthlyAgg[monthKey].cost += parseFloat(String(row[col.base.cost]).replace(/,/g, '')) || 0;
@@ -140,6 +168,8 @@ function processAllData(baseData, cvData, keywordData, baseHeaders, cvHeaders, k
     data.cpc = data.clicks > 0 ? (data.cost / data.clicks) : 0;
     data.cvr = data.clicks > 0 ? (data.cv / data.clicks) : 0;
     data.cpa = data.cv > 0 ? (data.cost / data.cv) : 0;
+    data.roas = data.cost > 0 ? (data.value / data.cost) : 0;
+    data.valuePerCv = data.cv > 0 ? (data.value / data.cv) : 0;
   });
 
   const { lastMonthBreakdowns, prevMonthBreakdowns } = getPeriodBreakdowns(baseData, cvData, keywordData, col, lastMonthStartDate, lastMonthEndDate, prevMonthStartDate, prevMonthEndDate);
@@ -147,19 +177,29 @@ function processAllData(baseData, cvData, keywordData, baseHeaders, cvHeaders, k
   const lastMonthKey = Utilities.formatDate(lastMonthStartDate, 'JST', 'yyyy-MM');
   const prevMonthKey = Utilities.formatDate(prevMonthStartDate, 'JST', 'yyyy-MM');
 
-  const lastMonthTotals = monthlyAgg[lastMonthKey] || { imp: 0, clicks: 0, cost: 0, cv: 0, ctr: 0, cpc: 0, cvr: 0, cpa: 0 };
-  const prevMonthTotals = monthlyAgg[prevMonthKey] || { imp: 0, clicks: 0, cost: 0, cv: 0, ctr: 0, cpc: 0, cvr: 0, cpa: 0 };
+  const emptyTotals = Object.assign(newMonthlyEntry(), { ctr: 0, cpc: 0, cvr: 0, cpa: 0, roas: 0, valuePerCv: 0 });
+  const lastMonthTotals = monthlyAgg[lastMonthKey] || emptyTotals;
+  const prevMonthTotals = monthlyAgg[prevMonthKey] || emptyTotals;
+  const emptyRoles = { primary: 0, intermediate: 0, micro: 0 };
 
   const lastMonthResult = {
     period: `${Utilities.formatDate(lastMonthStartDate, 'JST', 'yyyy/MM/dd')} - ${Utilities.formatDate(lastMonthEndDate, 'JST', 'yyyy/MM/dd')}`,
     totalCost: lastMonthTotals.cost, totalClicks: lastMonthTotals.clicks, totalImpressions: lastMonthTotals.imp, totalConversions: lastMonthTotals.cv,
     ctr: lastMonthTotals.ctr, cvr: lastMonthTotals.cvr, cpa: lastMonthTotals.cpa,
+    totalConversionValue: lastMonthTotals.value, roas: lastMonthTotals.roas, valuePerConversion: lastMonthTotals.valuePerCv,
+    conversionsByRole: roleAgg[lastMonthKey] || emptyRoles,
+    hasConversionDate: hasConversionDate,
+    conversionsByConversionDate: lastMonthTotals.cvByConversionDate, conversionValueByConversionDate: lastMonthTotals.valueByConversionDate,
     ...lastMonthBreakdowns
   };
 
   const prevMonthResult = {
     period: `${Utilities.formatDate(prevMonthStartDate, 'JST', 'yyyy/MM/dd')} - ${Utilities.formatDate(prevMonthEndDate, 'JST', 'yyyy/MM/dd')}`,
     totalCost: prevMonthTotals.cost, totalClicks: prevMonthTotals.clicks, totalImpressions: prevMonthTotals.imp, totalConversions: prevMonthTotals.cv,
+    totalConversionValue: prevMonthTotals.value, roas: prevMonthTotals.roas, valuePerConversion: prevMonthTotals.valuePerCv,
+    conversionsByRole: roleAgg[prevMonthKey] || emptyRoles,
+    hasConversionDate: hasConversionDate,
+    conversionsByConversionDate: prevMonthTotals.cvByConversionDate, conversionValueByConversionDate: prevMonthTotals.valueByConversionDate,
     ...prevMonthBreakdowns
   };
 
@@ -171,15 +211,16 @@ function getPeriodBreakdowns(baseData, cvData, keywordData, col, lastMonthStartD
     const prevMonthBreakdowns = { campaignData: {}, deviceData: {}, keywordData: {} };
 
     const cvMap = {};
+    const valueMap = {};
     cvData.forEach(row => {
         try {
             const rowDate = new Date(row[col.cv.date]);
             if (isNaN(rowDate.getTime())) return;
-            const actionName = row[col.cv.action] || '';
-            if (!actionName.includes('中間')) {
+            if (isPrimaryConversion(row[col.cv.action])) {
                 const key = `${Utilities.formatDate(rowDate, 'JST', 'yyyy-MM-dd')}|${row[col.cv.campaign]}|${row[col.cv.device]}`;
                 const cvs = parseFloat(row[col.cv.cvs]) || 0;
                 cvMap[key] = (cvMap[key] || 0) + cvs;
+                valueMap[key] = (valueMap[key] || 0) + toConversionValue(row, col.cv.value);
             }
         } catch (e) {}
     });
@@ -188,7 +229,7 @@ function getPeriodBreakdowns(baseData, cvData, keywordData, col, lastMonthStartD
         try {
             const rowDate = new Date(row[col.base.date]);
             if (isNaN(rowDate.getTime())) return;
-            if (row[col.base.channel] !== 'SEARCH') return;
+            if (!isSearchChannel(row[col.base.channel])) return;
 
             let targetBreakdown = null;
             if (rowDate >= lastMonthStartDate && rowDate <= lastMonthEndDate) {
@@ -200,14 +241,16 @@ function getPeriodBreakdowns(baseData, cvData, keywordData, col, lastMonthStartD
             if (targetBreakdown) {
                 const key = `${Utilities.formatDate(rowDate, 'JST', 'yyyy-MM-dd')}|${row[col.base.campaign]}|${row[col.base.device]}`;
                 const conversions = cvMap[key] || 0;
+                const conversionValue = valueMap[key] || 0;
                 const cost = parseFloat(String(row[col.base.cost]).replace(/,/g, '')) || 0;
                 const clicks = parseInt(row[col.base.clicks]) || 0;
 
                 const campaignName = row[col.base.campaign];
-                if (!targetBreakdown.campaignData[campaignName]) targetBreakdown.campaignData[campaignName] = { cost: 0, clicks: 0, conversions: 0 };
+                if (!targetBreakdown.campaignData[campaignName]) targetBreakdown.campaignData[campaignName] = { cost: 0, clicks: 0, conversions: 0, value: 0 };
                 targetBreakdown.campaignData[campaignName].cost += cost;
                 targetBreakdown.campaignData[campaignName].clicks += clicks;
                 targetBreakdown.campaignData[campaignName].conversions += conversions;
+                targetBreakdown.campaignData[campaignName].value += conversionValue;
 
                 const deviceName = row[col.base.device];
                 if (!targetBreakdown.deviceData[deviceName]) targetBreakdown.deviceData[deviceName] = { conversions: 0 };
@@ -233,6 +276,74 @@ function getPeriodBreakdowns(baseData, cvData, keywordData, col, lastMonthStartD
     return { lastMonthBreakdowns, prevMonthBreakdowns };
 }
 
+/**
+ * CV内訳データの行から、コンバージョン価値を数値で取り出す（価値の列がない以前のシートは0）
+ */
+function toConversionValue(row, valueIndex) {
+  if (valueIndex === -1) return 0;
+  return parseFloat(String(row[valueIndex]).replace(/,/g, '')) || 0;
+}
+
+/**
+ * P-MAXのアセットグループ別の実績を集計する関数
+ * 費用・クリック数・表示回数はアセットグループデータから、CVはCV内訳データから（役割が「主要」のアクションだけを）取り、
+ * 日付・キャンペーンID・グループ名・デバイスで突き合わせます。
+ */
+function aggregatePmaxAssetGroups(assetGroupData, assetGroupHeaders, cvData, cvHeaders, startDate, endDate) {
+  const getIndex = (headers, name) => headers.indexOf(name);
+  const col = {
+    group: { date: getIndex(assetGroupHeaders, '日付'), device: getIndex(assetGroupHeaders, 'デバイス'), campaign: getIndex(assetGroupHeaders, 'キャンペーン名'), campaignId: getIndex(assetGroupHeaders, 'キャンペーンID'), name: getIndex(assetGroupHeaders, 'グループ名'), imp: getIndex(assetGroupHeaders, '表示回数'), clicks: getIndex(assetGroupHeaders, 'クリック数'), cost: getIndex(assetGroupHeaders, '費用') },
+    cv: { date: getIndex(cvHeaders, '日付'), device: getIndex(cvHeaders, 'デバイス'), campaignId: getIndex(cvHeaders, 'キャンペーンID'), name: getIndex(cvHeaders, 'グループ名'), action: getIndex(cvHeaders, 'コンバージョンアクション名'), cvs: getIndex(cvHeaders, 'コンバージョン数') }
+  };
+  const toKey = (rowDate, campaignId, name, device) => `${Utilities.formatDate(rowDate, 'JST', 'yyyy-MM-dd')}|${campaignId}|${name}|${device}`;
+
+  const cvMap = {};
+  cvData.forEach(row => {
+    try {
+      const rowDate = new Date(row[col.cv.date]);
+      if (rowDate >= startDate && rowDate <= endDate && isPrimaryConversion(row[col.cv.action])) {
+        const key = toKey(rowDate, row[col.cv.campaignId], row[col.cv.name], row[col.cv.device]);
+        cvMap[key] = (cvMap[key] || 0) + (parseFloat(row[col.cv.cvs]) || 0);
+      }
+    } catch (e) {}
+  });
+
+  const totals = { imp: 0, clicks: 0, cost: 0, cv: 0 };
+  const groups = {};
+  assetGroupData.forEach(row => {
+    try {
+      const rowDate = new Date(row[col.group.date]);
+      if (!(rowDate >= startDate && rowDate <= endDate)) return;
+      const key = toKey(rowDate, row[col.group.campaignId], row[col.group.name], row[col.group.device]);
+      const groupKey = `${row[col.group.campaignId]}|${row[col.group.name]}`;
+      if (!groups[groupKey]) groups[groupKey] = { campaign: row[col.group.campaign], name: row[col.group.name], imp: 0, clicks: 0, cost: 0, cv: 0 };
+      const values = {
+        imp: parseInt(row[col.group.imp]) || 0,
+        clicks: parseInt(row[col.group.clicks]) || 0,
+        cost: parseFloat(String(row[col.group.cost]).replace(/,/g, '')) || 0,
+        cv: cvMap[key] || 0
+      };
+      // CV内訳データにはアセットグループIDがないため、同じ名前のアセットグループでCVを2回数えないよう、突き合わせたCVは取り除く
+      delete cvMap[key];
+      Object.keys(values).forEach(name => {
+        groups[groupKey][name] += values[name];
+        totals[name] += values[name];
+      });
+    } catch (e) {}
+  });
+
+  const withRates = data => Object.assign(data, {
+    ctr: data.imp > 0 ? data.clicks / data.imp : 0,
+    cvr: data.clicks > 0 ? data.cv / data.clicks : 0,
+    cpa: data.cv > 0 ? data.cost / data.cv : 0
+  });
+  return {
+    period: `${Utilities.formatDate(startDate, 'JST', 'yyyy/MM/dd')} - ${Utilities.formatDate(endDate, 'JST', 'yyyy/MM/dd')}`,
+    totals: withRates(totals),
+    assetGroups: Object.keys(groups).map(key => withRates(groups[key])).sort((a, b) => b.cost - a.cost)
+  };
+}
+
 
 /**
  * HTML側から呼び出され、Gemini APIで総括を生成する関数
@@ -270,7 +381,7 @@ function getGeminiSummary(lastMonth, prevMonth) {
 - コンバージョン数: ${lastMonth.totalConversions.toLocaleString()}件 (${cvChange >= 0 ? '+' : ''}${cvChange.toFixed(1)}%)
 - コンバージョン単価 (CPA): ${Math.round(lastMonth.cpa).toLocaleString()}円
 - コンバージョン率 (CVR): ${(lastMonth.cvr * 100).toFixed(2)}%
-
+${lastMonth.totalConversionValue > 0 ? `- コンバージョン価値: ${Math.round(lastMonth.totalConversionValue).toLocaleString()}円 (ROAS ${(lastMonth.roas * 100).toFixed(0)}%、1件あたり ${Math.round(lastMonth.valuePerConversion).toLocaleString()}円)\n` : ''}${lastMonth.hasConversionDate ? `- コンバージョン数（CVが発生した日で集計）: ${lastMonth.conversionsByConversionDate.toLocaleString()}件 (上記のコンバージョン数はクリックした日で集計した値)\n` : ''}
 # 指示
 - 上記の数値を分析し、良かった点、考えられる課題、そして来月に向けた具体的な改善提案（ネクストアクション）をまとめてください。
 - 箇条書きを用いて、簡潔で分かりやすく記述してください。
@@ -291,10 +402,11 @@ function getGeminiSummary(lastMonth, prevMonth) {
 `;
 
 
-    const apiUrl = `https://generativelanguage.googleapis.com/v1beta/models/gemini-2.5-flash-preview-05-20:generateContent?key=${geminiApiKey}`;
+    const config = getConfig();
+    const apiUrl = `https://generativelanguage.googleapis.com/v1beta/models/${config.GEMINI_MODEL}:generateContent?key=${config.GEMINI_API_KEY}`;
 
-    if (!geminiApiKey || geminiApiKey === "ここにGenerative Language APIキーを貼り付けてください") {
-      return "<p>（総括を自動生成するには、スクリプトにAPIキーを設定してください）</p>";
+    if (!config.GEMINI_API_KEY) {
+      return "<p>（総括を自動生成するには、スクリプトプロパティに GEMINI_API_KEY を設定してください）</p>";
     }
 
     const payload = {
@@ -1,11 +1,187 @@
 
-// ▼▼【要設定】▼▼ レポート対象のスプレッドシートのURLを貼り付けてください
-const SPREADSHEET_URL = 'スプレッドシートのURLをここに貼り付けてください';
+// ▼▼【要設定】▼▼ 設定値はソースコードに直接書かず、次のどちらかに登録してください
+//   ・スクリプトプロパティ（プロジェクトの設定 → スクリプト プロパティ）… URLとAPIキーはこちらに登録
+//   ・レポート対象スプレッドシートの「設定」シート（A列: 項目名 / B列: 値）… シート名などの変更に
+// 両方に登録されている場合は、スクリプトプロパティの値を使います。
 
-// ▼▼【要設定】▼▼ Generative Language APIキーを貼り付けてください
-const geminiApiKey = "ここにGenerative Language APIキーを貼り付けてください";
+// 設定値を記入するシート名（レポート対象のスプレッドシート内・任意）
+const CONFIG_SHEET_NAME = '設定';
 
-// ▼設定▼ 3つのシート名を指定してください
-const SHEET_NAME_BASE = '基本データ';
-const SHEET_NAME_CV = 'コンバージョンデータ';
-const SHEET_NAME_KEYWORD = 'キーワード別データ';
\ No newline at end of file
+// 設定項目（required: 必須かどうか / defaultValue: 未登録のときの値 / sheet: 読み込むデータのシート名かどうか / optional: シートがなくてもよいか）
+const REPORT_CONFIG_ITEMS = {
+  SPREADSHEET_URL: { label: 'レポート対象のスプレッドシートURL', required: true, pattern: /^https:\/\/docs\.google\.com\/spreadsheets\/d\/[\w-]+/ },
+  GEMINI_API_KEY: { label: 'Generative Language APIキー（空欄なら総括の自動生成を行いません）', defaultValue: '' },
+  GEMINI_MODEL: { label: '総括の生成に使うGeminiのモデル名', defaultValue: 'gemini-2.5-flash-preview-05-20', pattern: /^gemini-[\w.-]+$/ },
+  SHEET_NAME_BASE: { label: '基本データのシート名', defaultValue: '基本データ', sheet: true },
+  SHEET_NAME_CV: { label: 'コンバージョンデータのシート名', defaultValue: 'コンバージョンデータ', sheet: true },
+  SHEET_NAME_KEYWORD: { label: 'キーワード別データのシート名', defaultValue: 'キーワード別データ', sheet: true },
+  SHEET_NAME_CONVERSION_ACTIONS: { label: 'コンバージョンアクション一覧のシート名（シートがなければ、名前に「中間」を含むアクションを除いてCVを数えます）', defaultValue: 'コンバージョンアクション一覧', sheet: true, optional: true },
+  SHEET_NAME_HOURLY: { label: '時間帯別データのシート名（シートがなければヒートマップを表示しません）', defaultValue: '時間帯別データ', sheet: true, optional: true },
+  HEATMAP_TARGET_CPA: { label: 'ヒートマップで赤く表示するCPAの基準（円・空欄なら前月の平均CPA）', defaultValue: '', pattern: /^\d+$/ },
+  SHEET_NAME_ADS: { label: '広告データのシート名（シートがなければ広告文の比較を表示しません）', defaultValue: '広告データ', sheet: true, optional: true },
+  SHEET_NAME_ASSETS: { label: 'アセット評価のシート名（シートがなければアセットの一覧を表示しません）', defaultValue: 'アセット評価', sheet: true, optional: true },
+  CREATIVE_MIN_CLICKS: { label: '広告文の比較の対象にする、前月のクリック数の下限', defaultValue: '20', pattern: /^\d+$/ },
+  SHEET_NAME_PMAX: { label: 'アセットグループデータのシート名（シートがなければP-MAXのタブを表示しません）', defaultValue: 'アセットグループデータ', sheet: true, optional: true },
+  SHEET_NAME_COMPETITION: { label: '競合指標データのシート名（シートがなければインプレッションシェアの推移を表示しません）', defaultValue: '競合指標データ', sheet: true, optional: true },
+  COMPETITION_WEEKS: { label: 'インプレッションシェアの推移に表示する週数', defaultValue: '12', pattern: /^[1-9]\d*$/ },
+  COMPETITION_MAX_DOMAINS: { label: 'インプレッションシェアの推移に表示する競合ドメインの数（重複率の高い順）', defaultValue: '5', pattern: /^\d+$/ }
+};
+
+// アセット評価シートの「評価」のうち、一覧に表示する値（SEARCH_CHANNEL_VALUES と同じく両方の表記に対応）
+const ASSET_LABEL_VALUES = {
+  best: ['BEST', '最良'],
+  low: ['LOW', '低']
+};
+
+// 競合指標データシートの「表示URLドメイン」のうち、自社の行を表す値
+const OWN_DOMAIN_VALUES = ['（自社）'];
+
+// 検索広告として集計する「広告チャネルタイプ」の値
+// データ取得スクリプトの ENUM_OUTPUT によって、Enum値（SEARCH）と日本語の表記（検索）のどちらでも記録されるため両方を対象にする
+const SEARCH_CHANNEL_VALUES = ['SEARCH', '検索'];
+
+// コンバージョンアクション一覧シートの「役割」の値（主要のアクションだけをCVに数え、ほかは役割別のCVとして表示する）
+const CONVERSION_ROLE_VALUES = {
+  primary: '主要',
+  intermediate: '中間',
+  micro: 'マイクロ'
+};
+
+// 同じ実行の中で、設定を何度も読み込まないようにするためのキャッシュ
+let reportConfig = null;
+
+// コンバージョンアクション名 → 役割（primary / intermediate / micro）。loadConversionRoles() で読み込む
+let conversionRoles = null;
+
+/**
+ * 設定値を読み込んで検証し、レポートの作成に必要な値をまとめて返す
+ * 不備がある項目はすべて集めてから、1つのエラーとして投げます（スプレッドシートのデータは読み込みません）。
+ * @returns {Object} 項目名（SPREADSHEET_URL など）をキーとする設定値
+ */
+function getConfig() {
+  if (reportConfig) {
+    return reportConfig;
+  }
+  const properties = PropertiesService.getScriptProperties().getProperties();
+  const errors = [];
+  const config = {};
+
+  // スプレッドシートURLは「設定」シートの場所でもあるため、スクリプトプロパティからのみ読み込む
+  const url = String(properties.SPREADSHEET_URL || '').trim();
+  if (!REPORT_CONFIG_ITEMS.SPREADSHEET_URL.pattern.test(url)) {
+    throw new Error('設定に不備があります。\n- SPREADSHEET_URL（' + REPORT_CONFIG_ITEMS.SPREADSHEET_URL.label + '）: ' +
+      (url ? `「${url}」はスプレッドシートのURLではありません。` : 'スクリプトプロパティに登録されていません。'));
+  }
+  config.SPREADSHEET_URL = url;
+  const ss = SpreadsheetApp.openByUrl(url);
+
+  const sheetValues = {};
+  const configSheet = ss.getSheetByName(CONFIG_SHEET_NAME);
+  if (configSheet && configSheet.getLastRow() > 0) {
+    configSheet.getRange(1, 1, configSheet.getLastRow(), 2).getValues().forEach((row, index) => {
+      const key = String(row[0]).trim();
+      if (!key || key === '項目') {
+        return;
+      }
+      if (!REPORT_CONFIG_ITEMS[key] || key === 'SPREADSHEET_URL') {
+        errors.push(`「${CONFIG_SHEET_NAME}」シート${index + 1}行目の「${key}」は、このシートでは設定できない項目です。`);
+        return;
+      }
+      sheetValues[key] = String(row[1]).trim();
+    });
+  }
+
+  Object.keys(REPORT_CONFIG_ITEMS).forEach(key => {
+    if (key === 'SPREADSHEET_URL') {
+      return;
+    }
+    const item = REPORT_CONFIG_ITEMS[key];
+    let value = item.defaultValue;
+    if (properties[key] !== undefined && String(properties[key]).trim() !== '') {
+      value = String(properties[key]).trim();
+    } else if (sheetValues[key]) {
+      value = sheetValues[key];
+    }
+
+    if (value && item.pattern && !item.pattern.test(value)) {
+      errors.push(`${key}（${item.label}）: 「${value}」は正しい形式ではありません。`);
+    } else if (item.sheet && !item.optional && !ss.getSheetByName(value)) {
+      errors.push(`${key}（${item.label}）: スプレッドシートに「${value}」シートが見つかりません。`);
+    }
+    config[key] = value;
+  });
+
+  if (sheetValues.GEMINI_API_KEY) {
+    console.warn(`GEMINI_API_KEY が「${CONFIG_SHEET_NAME}」シートに記入されています。シートを共有している全員がAPIキーを閲覧できるため、スクリプトプロパティへの移動をおすすめします。`);
+  }
+
+  if (errors.length > 0) {
+    throw new Error(`設定に${errors.length}件の不備があります。\n- ` + errors.join('\n- '));
+  }
+  reportConfig = Object.freeze(config);
+  return reportConfig;
+}
+
+/**
+ * 広告チャネルタイプの値が検索広告かどうかを判定する
+ */
+function isSearchChannel(value) {
+  return SEARCH_CHANNEL_VALUES.indexOf(String(value).trim()) !== -1;
+}
+
+/**
+ * コンバージョンアクション一覧シートから、アクション名ごとの役割を読み込む（シートがなければ名前での判定を使う）
+ * 同じ名前のアクションが複数ある場合は、削除済みでない行の役割を優先します。
+ */
+function loadConversionRoles(ss, sheetName) {
+  conversionRoles = null;
+  const sheet = ss.getSheetByName(sheetName);
+  if (!sheet || sheet.getLastRow() <= 1) {
+    console.log(`「${sheetName}」シートがないため、名前に「中間」を含むアクションを除いてCVを数えます。`);
+    return;
+  }
+  const values = sheet.getDataRange().getValues();
+  const headers = values.shift();
+  const nameIndex = headers.indexOf('コンバージョンアクション名');
+  const roleIndex = headers.indexOf('役割');
+  const statusIndex = headers.indexOf('ステータス');
+  if (nameIndex === -1 || roleIndex === -1) {
+    throw new Error(`「${sheetName}」シートに「コンバージョンアクション名」「役割」の列が見つかりません。`);
+  }
+  const roleKeys = {};
+  Object.keys(CONVERSION_ROLE_VALUES).forEach(key => { roleKeys[CONVERSION_ROLE_VALUES[key]] = key; });
+
+  const removed = {};
+  conversionRoles = {};
+  values.forEach(row => {
+    const name = String(row[nameIndex]).trim();
+    const role = roleKeys[String(row[roleIndex]).trim()];
+    if (!name || !role) {
+      return;
+    }
+    const isRemoved = statusIndex !== -1 && ['REMOVED', '削除済み'].indexOf(String(row[statusIndex]).trim()) !== -1;
+    if (!conversionRoles[name] || (removed[name] && !isRemoved)) {
+      conversionRoles[name] = role;
+      removed[name] = isRemoved;
+    }
+  });
+}
+
+/**
+ * コンバージョンアクションの役割（primary / intermediate / micro）を返す
+ * 一覧にないアクション（一覧を更新する前に追加したものなど）は、一覧の既定と同じく名前に「中間」を含むかどうかで判定します。
+ */
+function getConversionRole(actionName) {
+  const name = String(actionName || '').trim();
+  if (conversionRoles && conversionRoles[name]) {
+    return conversionRoles[name];
+  }
+  return name.indexOf('中間') !== -1 ? 'intermediate' : 'primary';
+}
+
+/**
+ * CV・CPA・CVRに数えるコンバージョンアクションかどうかを判定する
+ */
+function isPrimaryConversion(actionName) {
+  return getConversionRole(actionName) === 'primary';
+}
@@ -4,6 +4,11 @@
  * Googleドライブのルートフォルダに保存します。
  * * 「基本データ」「コンバージョンデータ」「キーワード別データ」の3シートからデータを取得します。
  * * 検索広告のデータのみを対象とします。
+ * * 「時間帯別データ」シートがある場合は、時間帯×曜日のヒートマップ（CPAが基準を超えるセルを赤く表示）を追加します。
+ * * 「広告データ」シートがある場合は、成果の良い広告文・悪い広告文の比較を、「アセット評価」シートがある場合は評価が最良・低のアセットの一覧を追加します。
+ * * 「競合指標データ」シートがある場合は、週ごとのインプレッションシェアと競合ドメインの推移を追加します。
+ * * 「コンバージョンアクション一覧」シートがある場合は、役割が「主要」のアクションだけをCVに数えます（なければ名前に「中間」を含むアクションを除きます）。
+ * * 「アセットグループデータ」シートがある場合は、P-MAXのアセットグループ別の実績（CVはコンバージョンデータから）を別のタブに追加します。
  * * Gemini APIを使用して総括を自動生成します。
  */
 
@@ -14,14 +19,16 @@
 function createMonthlyReportFrom3Sheets() {
   try {
     // --- 1. スプレッドシートとデータの準備 ---
-    const ss = SpreadsheetApp.openByUrl(SPREADSHEET_URL);
+    const config = getConfig();
+    const ss = SpreadsheetApp.openByUrl(config.SPREADSHEET_URL);
+    loadConversionRoles(ss, config.SHEET_NAME_CONVERSION_ACTIONS);
 
-    const baseSheet = ss.getSheetByName(SHEET_NAME_BASE);
-    const cvSheet = ss.getSheetByName(SHEET_NAME_CV);
-    const keywordSheet = ss.getSheetByName(SHEET_NAME_KEYWORD);
+    const baseSheet = ss.getSheetByName(config.SHEET_NAME_BASE);
+    const cvSheet = ss.getSheetByName(config.SHEET_NAME_CV);
+    const keywordSheet = ss.getSheetByName(config.SHEET_NAME_KEYWORD);
 
     if (!baseSheet || !cvSheet || !keywordSheet) {
-      throw new Error(`必要なシート（${SHEET_NAME_BASE}, ${SHEET_NAME_CV}, ${SHEET_NAME_KEYWORD}）のいずれかが見つかりません。`);
+      throw new Error(`必要なシート（${config.SHEET_NAME_BASE}, ${config.SHEET_NAME_CV}, ${config.SHEET_NAME_KEYWORD}）のいずれかが見つかりません。`);
     }
 
     const baseData = baseSheet.getDataRange().getValues();
@@ -44,9 +51,65 @@ function createMonthlyReportFrom3Sheets() {
     const prevMonthData = aggregateData(baseData, cvData, keywordData, baseHeaders, cvHeaders, keywordHeaders, prevMonthStartDate, prevMonthEndDate);
     const monthlyData = aggregateDataForMonthlyView(baseData, cvData, baseHeaders, cvHeaders);
 
+    // 時間帯別データのシートがある場合のみ、時間帯×曜日のヒートマップを作る
+    let heatmapData = null;
+    const hourlySheet = ss.getSheetByName(config.SHEET_NAME_HOURLY);
+    if (hourlySheet) {
+      const hourlyData = hourlySheet.getDataRange().getValues();
+      const hourlyHeaders = hourlyData.shift();
+      const targetCpa = config.HEATMAP_TARGET_CPA ? Number(config.HEATMAP_TARGET_CPA) : lastMonthData.cpa;
+      heatmapData = aggregateHourlyHeatmap(hourlyData, hourlyHeaders, lastMonthStartDate, lastMonthEndDate, targetCpa);
+    } else {
+      console.log(`「${config.SHEET_NAME_HOURLY}」シートがないため、時間帯×曜日のヒートマップは作成しません。`);
+    }
+
+    // 広告データ・アセット評価のシートがある場合のみ、広告文のタブを作る
+    let creativeData = null;
+    const adSheet = ss.getSheetByName(config.SHEET_NAME_ADS);
+    const assetSheet = ss.getSheetByName(config.SHEET_NAME_ASSETS);
+    if (adSheet || assetSheet) {
+      creativeData = { ads: null, assets: null };
+      if (adSheet) {
+        const adData = adSheet.getDataRange().getValues();
+        const adHeaders = adData.shift();
+        creativeData.ads = aggregateAdCreatives(adData, adHeaders, lastMonthStartDate, lastMonthEndDate, Number(config.CREATIVE_MIN_CLICKS));
+      }
+      if (assetSheet) {
+        const assetData = assetSheet.getDataRange().getValues();
+        const assetHeaders = assetData.shift();
+        creativeData.assets = aggregateAssetLabels(assetData, assetHeaders);
+      }
+    } else {
+      console.log(`「${config.SHEET_NAME_ADS}」「${config.SHEET_NAME_ASSETS}」シートがないため、広告文の比較は作成しません。`);
+    }
+
+    // 競合指標データのシートがある場合のみ、インプレッションシェアの推移のタブを作る
+    let competitionData = null;
+    const competitionSheet = ss.getSheetByName(config.SHEET_NAME_COMPETITION);
+    if (competitionSheet) {
+      const competitionRows = competitionSheet.getDataRange().getValues();
+      const competitionHeaders = competitionRows.shift();
+      competitionData = aggregateCompetitionTrend(competitionRows, competitionHeaders, lastMonthEndDate, Number(config.COMPETITION_WEEKS), Number(config.COMPETITION_MAX_DOMAINS));
+    } else {
+      console.log(`「${config.SHEET_NAME_COMPETITION}」シートがないため、インプレッションシェアの推移は作成しません。`);
+    }
+
+    // アセットグループデータのシートがある場合のみ、P-MAXのタブを作る
+    let pmaxData = null;
+    const assetGroupSheet = ss.getSheetByName(config.SHEET_NAME_PMAX);
+    if (assetGroupSheet) {
+      const assetGroupData = assetGroupSheet.getDataRange().getValues();
+      const assetGroupHeaders = assetGroupData.shift();
+      pmaxData = {
+        lastMonth: aggregatePmaxAssetGroups(assetGroupData, assetGroupHeaders, cvData, cvHeaders, lastMonthStartDate, lastMonthEndDate),
+        prevMonth: aggregatePmaxAssetGroups(assetGroupData, assetGroupHeaders, cvData, cvHeaders, prevMonthStartDate, prevMonthEndDate)
+      };
+    } else {
+      console.log(`「${config.SHEET_NAME_PMAX}」シートがないため、P-MAXのタブは作成しません。`);
+    }
 
     // --- 4. HTMLレポートを生成 ---
-    const reportHtml = generateHtmlReport(lastMonthData, prevMonthData, monthlyData);
+    const reportHtml = generateHtmlReport(lastMonthData, prevMonthData, monthlyData, heatmapData, creativeData, competitionData, pmaxData);
 
     // --- 5. HTMLファイルをドライブに保存 ---
     const reportTitle = `【広告レポート_検索】${Utilities.formatDate(lastMonthStartDate, 'JST', 'yyyy-MM')}.html`;
@@ -69,19 +132,25 @@ function aggregateData(baseData, cvData, keywordData, baseHeaders, cvHeaders, ke
 
   const col = {
     base: { date: getIndex(baseHeaders, '日付'), device: getIndex(baseHeaders, 'デバイス'), campaign: getIndex(baseHeaders, 'キャンペーン名'), channel: getIndex(baseHeaders, '広告チャネルタイプ'), cost: getIndex(baseHeaders, 'ご利用額'), clicks: getIndex(baseHeaders, 'クリック数'), imp: getIndex(baseHeaders, '表示回数'), },
-    cv: { date: getIndex(cvHeaders, '日付'), device: getIndex(cvHeaders, 'デバイス'), campaign: getIndex(cvHeaders, 'キャンペーン名'), action: getIndex(cvHeaders, 'コンバージョンアクション名'), cvs: getIndex(cvHeaders, 'コンバージョン数'), },
+    cv: { date: getIndex(cvHeaders, '日付'), device: getIndex(cvHeaders, 'デバイス'), campaign: getIndex(cvHeaders, 'キャンペーン名'), action: getIndex(cvHeaders, 'コンバージョンアクション名'), cvs: getIndex(cvHeaders, 'コンバージョン数'), channel: getIndex(cvHeaders, '広告チャネルタイプ'), },
     kw: { date: getIndex(keywordHeaders, '日付'), keyword: getIndex(keywordHeaders, 'キーワード'), match: getIndex(keywordHeaders, 'マッチタイプ'), cost: getIndex(keywordHeaders, 'ご利用額'), clicks: getIndex(keywordHeaders, 'クリック数'), cvs: getIndex(keywordHeaders, 'コンバージョン数'), }
   };
 
   const cvMap = {};
+  const conversionsByRole = { primary: 0, intermediate: 0, micro: 0 };
   cvData.forEach(row => {
     try {
       const rowDate = new Date(row[col.cv.date]);
-      const actionName = row[col.cv.action] || '';
-      if (rowDate >= startDate && rowDate <= endDate && !actionName.includes('中間')) {
-        const key = `${Utilities.formatDate(rowDate, 'JST', 'yyyy-MM-dd')}|${row[col.cv.campaign]}|${row[col.cv.device]}`;
+      if (rowDate >= startDate && rowDate <= endDate) {
+        const role = getConversionRole(row[col.cv.action]);
         const cvs = parseFloat(row[col.cv.cvs]) || 0;
-        cvMap[key] = (cvMap[key] || 0) + cvs;
+        if (isSearchChannel(row[col.cv.channel])) {
+          conversionsByRole[role] += cvs;
+        }
+        if (role === 'primary') {
+          const key = `${Utilities.formatDate(rowDate, 'JST', 'yyyy-MM-dd')}|${row[col.cv.campaign]}|${row[col.cv.device]}`;
+          cvMap[key] = (cvMap[key] || 0) + cvs;
+        }
       }
     } catch(e) {}
   });
@@ -92,7 +161,7 @@ function aggregateData(baseData, cvData, keywordData, baseHeaders, cvHeaders, ke
   baseData.forEach(row => {
     try {
       const rowDate = new Date(row[col.base.date]);
-      if (rowDate >= startDate && rowDate <= endDate && row[col.base.channel] === 'SEARCH') {
+      if (rowDate >= startDate && rowDate <= endDate && isSearchChannel(row[col.base.channel])) {
         const key = `${Utilities.formatDate(rowDate, 'JST', 'yyyy-MM-dd')}|${row[col.base.campaign]}|${row[col.base.device]}`;
         const conversions = cvMap[key] || 0;
         const cost = parseFloat(String(row[col.base.cost]).replace(/,/g, '')) || 0;
@@ -129,6 +198,7 @@ function aggregateData(baseData, cvData, keywordData, baseHeaders, cvHeaders, ke
     ctr: totalImpressions > 0 ? (totalClicks / totalImpressions) : 0,
     cvr: totalClicks > 0 ? (totalConversions / totalClicks) : 0,
     cpa: totalConversions > 0 ? (totalCost / totalConversions) : 0,
+    conversionsByRole,
     campaignData: campaignAgg, deviceData: deviceAgg, keywordData: keywordAgg
   };
 }
@@ -149,10 +219,9 @@ function aggregateDataForMonthlyView(baseData, cvData, baseHeaders, cvHeaders) {
       try {
         const rowDate = new Date(row[col.cv.date]);
         const monthKey = Utilities.formatDate(rowDate, 'JST', 'yyyy-MM');
-        const actionName = row[col.cv.action] || '';
         const channel = row[col.cv.channel];
 
-        if (channel === 'SEARCH' && !actionName.includes('中間')) {
+        if (isSearchChannel(channel) && isPrimaryConversion(row[col.cv.action])) {
             const cvs = parseFloat(row[col.cv.cvs]) || 0;
             cvMapMonthly[monthKey] = (cvMapMonthly[monthKey] || 0) + cvs;
         }
@@ -162,7 +231,7 @@ function aggregateDataForMonthlyView(baseData, cvData, baseHeaders, cvHeaders) {
     const monthlyAgg = {};
     baseData.forEach(row => {
       try {
-        if (row[col.base.channel] === 'SEARCH') {
+        if (isSearchChannel(row[col.base.channel])) {
             const rowDate = new Date(row[col.base.date]);
             const monthKey = Utilities.formatDate(rowDate, 'JST', 'yyyy-MM');
             if (!monthlyAgg[monthKey]) {
@@ -187,6 +256,371 @@ function aggregateDataForMonthlyView(baseData, cvData, baseHeaders, cvHeaders) {
     return monthlyAgg;
 }
 
+/**
+ * 時間帯別データを、曜日（月〜日）×時間帯（0〜23時）のマスに集計する関数
+ * CPAが基準（targetCpa）を超えるマスと、CVがないまま基準以上の費用を使ったマスを「赤字」とします。
+ */
+function aggregateHourlyHeatmap(hourlyData, hourlyHeaders, startDate, endDate, targetCpa) {
+  const getIndex = name => hourlyHeaders.indexOf(name);
+  const col = { date: getIndex('日付'), hour: getIndex('時間帯'), channel: getIndex('広告チャネルタイプ'), cost: getIndex('費用'), clicks: getIndex('クリック数'), cvs: getIndex('コンバージョン数') };
+
+  // cells[曜日（0: 月曜）][時間帯]
+  const cells = [0, 1, 2, 3, 4, 5, 6].map(() => Array.from({ length: 24 }, () => ({ cost: 0, clicks: 0, cv: 0 })));
+  hourlyData.forEach(row => {
+    try {
+      const rowDate = new Date(row[col.date]);
+      const hour = parseInt(row[col.hour], 10);
+      if (rowDate >= startDate && rowDate <= endDate && isSearchChannel(row[col.channel]) && hour >= 0 && hour < 24) {
+        const cell = cells[(rowDate.getDay() + 6) % 7][hour];
+        cell.cost += parseFloat(String(row[col.cost]).replace(/,/g, '')) || 0;
+        cell.clicks += parseInt(row[col.clicks]) || 0;
+        cell.cv += parseFloat(row[col.cvs]) || 0;
+      }
+    } catch(e) {}
+  });
+
+  let unprofitableCount = 0, unprofitableCost = 0;
+  cells.forEach(hours => hours.forEach(cell => {
+    cell.cpa = cell.cv > 0 ? cell.cost / cell.cv : 0;
+    // 基準のCPAがない（前月のCVが0件）場合は判定しない
+    const overCost = cell.cv > 0 ? cell.cpa : cell.cost;
+    cell.unprofitable = targetCpa > 0 && (cell.cv > 0 ? cell.cpa > targetCpa : cell.cost >= targetCpa);
+    cell.severity = cell.unprofitable ? overCost / targetCpa : 0;
+    if (cell.unprofitable) {
+      unprofitableCount++;
+      unprofitableCost += cell.cost;
+    }
+  }));
+
+  return { targetCpa, cells, unprofitableCount, unprofitableCost };
+}
+
+/**
+ * 時間帯×曜日のヒートマップのHTMLを作る関数
+ */
+function buildHeatmapHtml(heatmap) {
+  const dayLabels = ['月', '火', '水', '木', '金', '土', '日'];
+  const hourHeaders = Array.from({ length: 24 }, (_, hour) => `<th class="px-1 py-2 text-center font-medium">${hour}</th>`).join('');
+  const rows = heatmap.cells.map((hours, dayIndex) => {
+    const cellsHtml = hours.map((cell, hour) => {
+      let className = 'bg-gray-50 text-gray-300';
+      let text = '-';
+      if (cell.cost > 0 || cell.cv > 0) {
+        text = cell.cv > 0 ? `¥${Math.round(cell.cpa).toLocaleString()}` : '0CV';
+        if (cell.unprofitable) {
+          className = cell.severity >= 2 ? 'bg-red-500 text-white font-bold' : 'bg-red-200 text-red-900';
+        } else {
+          className = cell.cv > 0 ? 'bg-green-100 text-green-900' : 'bg-white text-gray-500';
+        }
+      }
+      const title = `${dayLabels[dayIndex]}曜 ${hour}時 / 費用 ¥${Math.round(cell.cost).toLocaleString()} / クリック ${cell.clicks.toLocaleString()} / CV ${Math.round(cell.cv * 100) / 100}`;
+      return `<td class="px-1 py-2 text-center whitespace-nowrap border border-gray-100 ${className}" title="${title}">${text}</td>`;
+    }).join('');
+    return `<tr><th class="px-2 py-2 font-medium text-gray-700">${dayLabels[dayIndex]}</th>${cellsHtml}</tr>`;
+  }).join('');
+
+  const criteria = heatmap.targetCpa > 0
+    ? `基準CPA ¥${Math.round(heatmap.targetCpa).toLocaleString()} を超えたマス（CVがない場合は費用が基準以上のマス）を赤く表示しています。濃い赤は基準の2倍以上です。`
+    : '前月のCVがなく基準CPAを決められないため、赤字の判定は行っていません（設定の HEATMAP_TARGET_CPA で基準を指定できます）。';
+  return `
+                <div class="bg-white p-4 sm:p-6 rounded-lg shadow-sm overflow-x-auto">
+                    <h3 class="font-semibold text-gray-800 mb-2">時間帯×曜日のCPA</h3>
+                    <p class="text-xs text-gray-500 mb-1">${criteria}</p>
+                    <p class="text-xs text-gray-500 mb-4">赤字のマス: ${heatmap.unprofitableCount}個（費用合計 ¥${Math.round(heatmap.unprofitableCost).toLocaleString()}）。マスにカーソルを合わせると費用・クリック数・CVを表示します。</p>
+                    <table class="text-xs text-gray-700 border-collapse"><thead class="bg-gray-50"><tr><th class="px-2 py-2">曜日＼時</th>${hourHeaders}</tr></thead><tbody>${rows}</tbody></table>
+                </div>`;
+}
+
+/**
+ * 広告データを広告IDごとに集計し、成果の良い広告・悪い広告を選ぶ関数
+ * 前月のクリック数が minClicks 以上の検索広告を、CVR（同じならCTR）の高い順に並べ、
+ * CVのある上位3件を「成果の良い広告」、残りの下位3件を「成果の悪い広告」とします。
+ */
+function aggregateAdCreatives(adData, adHeaders, startDate, endDate, minClicks) {
+  const getIndex = name => adHeaders.indexOf(name);
+  const col = {
+    date: getIndex('日付'), channel: getIndex('広告チャネルタイプ'), campaign: getIndex('キャンペーン名'), adGroup: getIndex('広告グループ名'),
+    adId: getIndex('広告ID'), strength: getIndex('広告の有効性'), headlines: getIndex('広告見出し'), descriptions: getIndex('説明文'), url: getIndex('最終ページURL'),
+    imp: getIndex('表示回数'), clicks: getIndex('クリック数'), cost: getIndex('費用'), cvs: getIndex('コンバージョン数')
+  };
+
+  const ads = {};
+  adData.forEach(row => {
+    try {
+      const rowDate = new Date(row[col.date]);
+      if (rowDate >= startDate && rowDate <= endDate && isSearchChannel(row[col.channel])) {
+        const adId = String(row[col.adId]);
+        if (!ads[adId]) {
+          ads[adId] = { adId, imp: 0, clicks: 0, cost: 0, cv: 0 };
+        }
+        const ad = ads[adId];
+        // 広告文・有効性は、期間内で最も新しい行の内容を使う
+        if (!ad.latestDate || rowDate >= ad.latestDate) {
+          Object.assign(ad, {
+            latestDate: rowDate, campaign: row[col.campaign], adGroup: row[col.adGroup], strength: row[col.strength],
+            headlines: String(row[col.headlines]), descriptions: String(row[col.descriptions]), url: String(row[col.url])
+          });
+        }
+        ad.imp += parseInt(row[col.imp]) || 0;
+        ad.clicks += parseInt(row[col.clicks]) || 0;
+        ad.cost += parseFloat(String(row[col.cost]).replace(/,/g, '')) || 0;
+        ad.cv += parseFloat(row[col.cvs]) || 0;
+      }
+    } catch(e) {}
+  });
+
+  const ranked = Object.keys(ads).map(adId => {
+    const ad = ads[adId];
+    ad.ctr = ad.imp > 0 ? ad.clicks / ad.imp : 0;
+    ad.cvr = ad.clicks > 0 ? ad.cv / ad.clicks : 0;
+    ad.cpa = ad.cv > 0 ? ad.cost / ad.cv : 0;
+    return ad;
+  }).filter(ad => ad.clicks >= minClicks).sort((a, b) => b.cvr - a.cvr || b.ctr - a.ctr);
+
+  const winners = ranked.filter(ad => ad.cv > 0).slice(0, 3);
+  const losers = ranked.filter(ad => winners.indexOf(ad) === -1).slice(-3).reverse();
+  return { minClicks, comparedCount: ranked.length, winners, losers };
+}
+
+/**
+ * アセット評価シートから、評価が「最良」「低」の広告見出し・説明文を、同じテキストごとにまとめる関数
+ */
+function aggregateAssetLabels(assetData, assetHeaders) {
+  const getIndex = name => assetHeaders.indexOf(name);
+  const col = { source: getIndex('種類'), fieldType: getIndex('アセットの種類'), text: getIndex('テキスト・アセット名'), label: getIndex('評価'), imp: getIndex('表示回数') };
+
+  const groups = { best: {}, low: {} };
+  assetData.forEach(row => {
+    const label = String(row[col.label]).trim();
+    const kind = Object.keys(ASSET_LABEL_VALUES).find(key => ASSET_LABEL_VALUES[key].indexOf(label) !== -1);
+    const text = String(row[col.text]).trim();
+    if (!kind || !text) {
+      return;
+    }
+    const key = `${row[col.source]}|${row[col.fieldType]}|${text}`;
+    if (!groups[kind][key]) {
+      groups[kind][key] = { source: row[col.source], fieldType: row[col.fieldType], text, count: 0, imp: 0 };
+    }
+    groups[kind][key].count++;
+    groups[kind][key].imp += parseInt(row[col.imp]) || 0;
+  });
+
+  const toList = group => Object.keys(group).map(key => group[key]).sort((a, b) => b.count - a.count || b.imp - a.imp).slice(0, 10);
+  const recordedDate = assetData.length > 0 ? assetData[0][getIndex('記録日')] : '';
+  return { best: toList(groups.best), low: toList(groups.low), recordedDate };
+}
+
+/**
+ * 広告文タブのHTMLを作る関数
+ */
+function buildCreativeHtml(creativeData) {
+  const escapeHtml = value => String(value).replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;').replace(/"/g, '&quot;');
+  const sections = [];
+
+  if (creativeData.ads) {
+    const adRows = (ads, className) => ads.map(ad => `<tr class="border-b align-top ${className}">
+                            <td class="px-3 py-3"><p class="text-xs text-gray-500">${escapeHtml(ad.campaign)} / ${escapeHtml(ad.adGroup)}</p><p class="font-medium text-gray-900">${escapeHtml(ad.headlines.split(' / ').slice(0, 3).join(' | '))}</p><p class="text-xs text-gray-600">${escapeHtml(ad.descriptions.split(' / ').slice(0, 2).join(' '))}</p><p class="text-xs text-blue-600 break-all">${escapeHtml(ad.url)}</p></td>
+                            <td class="px-3 py-3 whitespace-nowrap">${escapeHtml(ad.strength)}</td>
+                            <td class="px-3 py-3 text-right">${ad.clicks.toLocaleString()}</td>
+                            <td class="px-3 py-3 text-right">${(ad.ctr * 100).toFixed(2)}%</td>
+                            <td class="px-3 py-3 text-right font-bold">${Math.round(ad.cv * 100) / 100}</td>
+                            <td class="px-3 py-3 text-right">${(ad.cvr * 100).toFixed(2)}%</td>
+                            <td class="px-3 py-3 text-right">${ad.cv > 0 ? `¥${Math.round(ad.cpa).toLocaleString()}` : '-'}</td>
+                        </tr>`).join('');
+    const adTable = (title, ads, className) => `
+                <div class="bg-white p-4 sm:p-6 rounded-lg shadow-sm overflow-x-auto mb-6">
+                    <h3 class="font-semibold text-gray-800 mb-4">${title}</h3>
+                    ${ads.length === 0 ? '<p class="text-sm text-gray-500">対象の広告がありません。</p>' : `<table class="w-full text-sm text-left text-gray-500"><thead class="text-xs text-gray-700 bg-gray-50"><tr><th class="px-3 py-3">広告文</th><th class="px-3 py-3">広告の有効性</th><th class="px-3 py-3 text-right">クリック数</th><th class="px-3 py-3 text-right">CTR</th><th class="px-3 py-3 text-right">CV</th><th class="px-3 py-3 text-right">CVR</th><th class="px-3 py-3 text-right">CPA</th></tr></thead><tbody>${adRows(ads, className)}</tbody></table>`}
+                </div>`;
+    sections.push(`<p class="text-xs text-gray-500 mb-4">前月のクリック数が${creativeData.ads.minClicks.toLocaleString()}回以上の広告（${creativeData.ads.comparedCount}件）を、CVRの高い順に比べています。</p>`);
+    sections.push(adTable('成果の良い広告', creativeData.ads.winners, 'bg-green-50'));
+    sections.push(adTable('成果の悪い広告', creativeData.ads.losers, 'bg-red-50'));
+  }
+
+  if (creativeData.assets) {
+    const assetList = (title, assets, className) => `
+                    <div>
+                        <h4 class="font-semibold text-gray-700 mb-2">${title}</h4>
+                        ${assets.length === 0 ? '<p class="text-sm text-gray-500">該当するアセットはありません。</p>' : `<ul class="space-y-1 text-sm">${assets.map(asset => `<li class="px-3 py-2 rounded ${className}"><span class="text-xs text-gray-500">${escapeHtml(asset.fieldType)}（${escapeHtml(asset.source)}・${asset.count}か所）</span><br>${escapeHtml(asset.text)}</li>`).join('')}</ul>`}
+                    </div>`;
+    sections.push(`
+                <div class="bg-white p-4 sm:p-6 rounded-lg shadow-sm mb-6">
+                    <h3 class="font-semibold text-gray-800 mb-2">アセットの評価</h3>
+                    <p class="text-xs text-gray-500 mb-4">Google広告の評価（${escapeHtml(creativeData.assets.recordedDate instanceof Date ? Utilities.formatDate(creativeData.assets.recordedDate, 'JST', 'yyyy-MM-dd') : creativeData.assets.recordedDate)} 時点）が「最良」「低」のアセットです。「低」のアセットは差し替えを検討してください。</p>
+                    <div class="grid grid-cols-1 md:grid-cols-2 gap-6">${assetList('最良', creativeData.assets.best, 'bg-green-50')}${assetList('低', creativeData.assets.low, 'bg-red-50')}</div>
+                </div>`);
+  }
+  return sections.join('');
+}
+
+/**
+ * 競合指標データを週ごとに集計し、自社のインプレッションシェアと競合ドメインの推移を作る関数
+ * 複数のキャンペーンの割合は、インプレッションシェアから逆算した「表示可能だった回数」で重み付けしてまとめます。
+ * 競合の指標は、同じ週・キャンペーンの自社の表示回数で重み付けします。
+ * endDate を含む週から遡って weeks 週分を対象にし、競合は期間中の重複率の高い順に maxDomains 件を選びます。
+ */
+function aggregateCompetitionTrend(competitionData, competitionHeaders, endDate, weeks, maxDomains) {
+  const getIndex = name => competitionHeaders.indexOf(name);
+  const col = {
+    week: getIndex('週'), campaignId: getIndex('キャンペーンID'), domain: getIndex('表示URLドメイン'), imp: getIndex('表示回数'),
+    is: getIndex('検索IS'), topIs: getIndex('検索TOP IS'), budgetLost: getIndex('検索IS損失率(予算)'), rankLost: getIndex('検索IS損失率(ランク)'),
+    overlap: getIndex('重複率'), positionAbove: getIndex('上位掲載率'), outranking: getIndex('優位表示シェア')
+  };
+  const toShare = value => value === '' || value === null ? NaN : parseFloat(value);
+  const toWeekKey = value => Utilities.formatDate(new Date(value), 'JST', 'yyyy-MM-dd');
+
+  const lastWeek = new Date(endDate.getFullYear(), endDate.getMonth(), endDate.getDate() - (endDate.getDay() + 6) % 7);
+  const firstWeek = new Date(lastWeek.getFullYear(), lastWeek.getMonth(), lastWeek.getDate() - 7 * (weeks - 1));
+  const weekKeys = Array.from({ length: weeks }, (_, i) => toWeekKey(new Date(firstWeek.getFullYear(), firstWeek.getMonth(), firstWeek.getDate() + 7 * i)));
+
+  // 自社の行: 週ごとの合計と、同じ週・キャンペーンの表示回数（競合の重み付けに使う）
+  const own = {};
+  weekKeys.forEach(week => { own[week] = { imp: 0, eligible: 0, topIs: 0, budgetLost: 0, rankLost: 0 }; });
+  const ownImpressions = {};
+  const competitorRows = [];
+  competitionData.forEach(row => {
+    try {
+      const week = toWeekKey(row[col.week]);
+      if (!own[week]) return;
+      if (OWN_DOMAIN_VALUES.indexOf(String(row[col.domain]).trim()) === -1) {
+        competitorRows.push({ week, row });
+        return;
+      }
+      const imp = parseInt(row[col.imp]) || 0;
+      const share = toShare(row[col.is]);
+      ownImpressions[`${week}|${row[col.campaignId]}`] = imp;
+      if (!(share > 0)) return;
+      const eligible = imp / share;
+      const total = own[week];
+      total.imp += imp;
+      total.eligible += eligible;
+      total.topIs += (toShare(row[col.topIs]) || 0) * eligible;
+      total.budgetLost += (toShare(row[col.budgetLost]) || 0) * eligible;
+      total.rankLost += (toShare(row[col.rankLost]) || 0) * eligible;
+    } catch(e) {}
+  });
+
+  const domains = {};
+  competitorRows.forEach(item => {
+    const row = item.row;
+    const weight = ownImpressions[`${item.week}|${row[col.campaignId]}`] || 0;
+    if (weight === 0) return;
+    const domain = String(row[col.domain]).trim();
+    if (!domains[domain]) {
+      domains[domain] = { domain, weight: 0, overlap: 0, byWeek: {} };
+    }
+    if (!domains[domain].byWeek[item.week]) {
+      domains[domain].byWeek[item.week] = { weight: 0, is: 0, overlap: 0, positionAbove: 0, outranking: 0 };
+    }
+    const total = domains[domain].byWeek[item.week];
+    total.weight += weight;
+    ['is', 'overlap', 'positionAbove', 'outranking'].forEach(key => { total[key] += (toShare(row[col[key]]) || 0) * weight; });
+    domains[domain].weight += weight;
+    domains[domain].overlap += (toShare(row[col.overlap]) || 0) * weight;
+  });
+
+  const ownTrend = weekKeys.map(week => {
+    const total = own[week];
+    const ratio = value => total.eligible > 0 ? value / total.eligible : null;
+    return { week, imp: total.imp, is: ratio(total.imp), topIs: ratio(total.topIs), budgetLost: ratio(total.budgetLost), rankLost: ratio(total.rankLost) };
+  });
+  const competitors = Object.keys(domains).map(key => domains[key])
+    .sort((a, b) => b.overlap / b.weight - a.overlap / a.weight)
+    .slice(0, maxDomains)
+    .map(item => ({
+      domain: item.domain,
+      weeks: weekKeys.map(week => {
+        const total = item.byWeek[week];
+        if (!total) return null;
+        return { is: total.is / total.weight, overlap: total.overlap / total.weight, positionAbove: total.positionAbove / total.weight, outranking: total.outranking / total.weight };
+      })
+    }));
+  return { weeks: weekKeys, own: ownTrend, competitors };
+}
+
+/**
+ * インプレッションシェアの推移タブのHTMLと、グラフを描くスクリプトを作る関数
+ */
+function buildCompetitionHtml(competition) {
+  const escapeHtml = value => String(value).replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;').replace(/"/g, '&quot;');
+  const percent = value => value === null || value === undefined ? '-' : `${(value * 100).toFixed(1)}%`;
+  const weekLabels = competition.weeks.map(week => week.slice(5).replace('-', '/') + '週');
+
+  const competitorHeaders = competition.competitors.map(item => `<th class="px-3 py-3 text-right border-l border-gray-300">${escapeHtml(item.domain)}<br><span class="font-normal">IS / 上位掲載率</span></th>`).join('');
+  const rows = competition.own.map((week, i) => {
+    const competitorCells = competition.competitors.map(item => {
+      const value = item.weeks[i];
+      return `<td class="px-3 py-3 text-right whitespace-nowrap border-l border-gray-300">${value ? `${percent(value.is)} / ${percent(value.positionAbove)}` : '-'}</td>`;
+    }).join('');
+    return `<tr class="border-b"><th class="px-3 py-3 font-medium text-gray-900 whitespace-nowrap">${weekLabels[i]}</th><td class="px-3 py-3 text-right font-bold">${percent(week.is)}</td><td class="px-3 py-3 text-right">${percent(week.topIs)}</td><td class="px-3 py-3 text-right">${percent(week.budgetLost)}</td><td class="px-3 py-3 text-right">${percent(week.rankLost)}</td>${competitorCells}</tr>`;
+  }).join('');
+
+  const toChartData = values => values.map(value => value === null || value === undefined ? null : Math.round(value * 1000) / 10);
+  const colors = ['#ef4444', '#f59e0b', '#10b981', '#8b5cf6', '#ec4899', '#6b7280'];
+  const datasets = [
+    { label: '自社のIS', data: toChartData(competition.own.map(week => week.is)), borderColor: '#3b82f6', backgroundColor: '#3b82f6', borderWidth: 3, tension: 0.1 },
+    { label: 'IS損失率（予算）', data: toChartData(competition.own.map(week => week.budgetLost)), borderColor: '#93c5fd', backgroundColor: '#93c5fd', borderDash: [4, 4], tension: 0.1 },
+    { label: 'IS損失率（ランク）', data: toChartData(competition.own.map(week => week.rankLost)), borderColor: '#1e3a8a', backgroundColor: '#1e3a8a', borderDash: [4, 4], tension: 0.1 }
+  ].concat(competition.competitors.map((item, i) => ({
+    label: `${item.domain} のIS`, data: toChartData(item.weeks.map(value => value ? value.is : null)), borderColor: colors[i % colors.length], backgroundColor: colors[i % colors.length], tension: 0.1
+  })));
+
+  const note = competition.competitors.length > 0
+    ? '競合ドメインは、期間中の重複率が高い順に表示しています。競合のISは、そのドメインの表示回数が表示可能だった回数に占める割合です。'
+    : 'オークション分析の指標がないため、自社のインプレッションシェアのみ表示しています。';
+  const html = `
+                <div class="bg-white p-6 rounded-lg shadow-sm mb-6">
+                    <h3 class="font-semibold text-gray-800 mb-2">週別 インプレッションシェアの推移</h3>
+                    <p class="text-xs text-gray-500 mb-4">${note}</p>
+                    <div class="relative h-80"><canvas id="competitionChart"></canvas></div>
+                </div>
+                <div class="bg-white p-4 sm:p-6 rounded-lg shadow-sm overflow-x-auto">
+                    <h3 class="font-semibold text-gray-800 mb-4">週別 インプレッションシェア・競合の指標</h3>
+                    <table class="w-full text-sm text-left text-gray-500"><thead class="text-xs text-gray-700 bg-gray-50"><tr><th class="px-3 py-3">週</th><th class="px-3 py-3 text-right">自社のIS</th><th class="px-3 py-3 text-right">上部IS</th><th class="px-3 py-3 text-right">IS損失率（予算）</th><th class="px-3 py-3 text-right">IS損失率（ランク）</th>${competitorHeaders}</tr></thead><tbody>${rows}</tbody></table>
+                </div>`;
+  const script = `
+            // Competition Trend Chart
+            new Chart(document.getElementById('competitionChart').getContext('2d'), {
+                type: 'line',
+                data: { labels: ${JSON.stringify(weekLabels)}, datasets: ${JSON.stringify(datasets)} },
+                options: { responsive: true, maintainAspectRatio: false, spanGaps: true, scales: { y: { min: 0, max: 100, title: { display: true, text: '割合 (%)' } } } }
+            });`;
+  return { html, script };
+}
+
+/**
+ * P-MAXタブのHTMLを作る関数（前月の合計と前々月比、アセットグループ別の実績）
+ */
+function buildPmaxHtml(pmaxData) {
+  const escapeHtml = value => String(value).replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;').replace(/"/g, '&quot;');
+  const last = pmaxData.lastMonth.totals;
+  const prev = pmaxData.prevMonth.totals;
+  const change = (current, previous) => previous > 0 ? `(${(((current / previous) - 1) * 100).toFixed(1)}% vs 前月)` : '';
+  const card = (title, value, note) => `<div class="bg-white p-4 rounded-lg shadow-sm text-center"><h3 class="text-sm font-medium text-gray-500">${title}</h3><p class="mt-1 text-2xl font-bold text-gray-900">${value}</p><p class="mt-1 text-xs text-gray-500">${note}</p></div>`;
+  const rows = pmaxData.lastMonth.assetGroups.map(group => `<tr class="bg-white border-b hover:bg-gray-50">
+                            <th scope="row" class="px-3 py-3 font-medium text-gray-900"><p class="text-xs font-normal text-gray-500">${escapeHtml(group.campaign)}</p>${escapeHtml(group.name)}</th>
+                            <td class="px-3 py-3 text-right">¥${Math.round(group.cost).toLocaleString()}</td>
+                            <td class="px-3 py-3 text-right">${group.imp.toLocaleString()}</td>
+                            <td class="px-3 py-3 text-right">${group.clicks.toLocaleString()}</td>
+                            <td class="px-3 py-3 text-right font-bold">${Math.round(group.cv * 100) / 100}</td>
+                            <td class="px-3 py-3 text-right">${(group.cvr * 100).toFixed(2)}%</td>
+                            <td class="px-3 py-3 text-right">${group.cv > 0 ? `¥${Math.round(group.cpa).toLocaleString()}` : '-'}</td>
+                        </tr>`).join('');
+  return `
+                <div class="grid grid-cols-2 md:grid-cols-3 lg:grid-cols-5 gap-4 mb-6">
+                    ${card('費用', `¥${Math.round(last.cost).toLocaleString()}`, change(last.cost, prev.cost))}
+                    ${card('クリック数', last.clicks.toLocaleString(), change(last.clicks, prev.clicks))}
+                    ${card('CV', (Math.round(last.cv * 100) / 100).toLocaleString(), change(last.cv, prev.cv))}
+                    ${card('CVR', `${(last.cvr * 100).toFixed(2)}%`, '')}
+                    ${card('CPA', last.cv > 0 ? `¥${Math.round(last.cpa).toLocaleString()}` : '-', '')}
+                </div>
+                <div class="bg-white p-4 sm:p-6 rounded-lg shadow-sm overflow-x-auto">
+                    <h3 class="font-semibold text-gray-800 mb-2">アセットグループ別実績</h3>
+                    <p class="text-xs text-gray-500 mb-4">期間: ${pmaxData.lastMonth.period}。P-MAXの実績は検索広告の集計（サマリー・月別データ）には含めていません。</p>
+                    ${pmaxData.lastMonth.assetGroups.length === 0 ? '<p class="text-sm text-gray-500">前月に配信されたアセットグループはありません。</p>' : `<table class="w-full text-sm text-left text-gray-500"><thead class="text-xs text-gray-700 bg-gray-50"><tr><th class="px-3 py-3">アセットグループ</th><th class="px-3 py-3 text-right">費用</th><th class="px-3 py-3 text-right">表示回数</th><th class="px-3 py-3 text-right">クリック数</th><th class="px-3 py-3 text-right">CV</th><th class="px-3 py-3 text-right">CVR</th><th class="px-3 py-3 text-right">CPA</th></tr></thead><tbody>${rows}</tbody></table>`}
+                </div>`;
+}
+
 /**
  * Gemini APIを呼び出して総括を生成する関数
  */
@@ -227,10 +661,11 @@ function generateSummaryWithGemini(lastMonth, prevMonth, costChange, clicksChang
 `;
 
 
-    const apiUrl = `https://generativelanguage.googleapis.com/v1beta/models/gemini-2.5-flash-preview-05-20:generateContent?key=${geminiApiKey}`;
+    const config = getConfig();
+    const apiUrl = `https://generativelanguage.googleapis.com/v1beta/models/${config.GEMINI_MODEL}:generateContent?key=${config.GEMINI_API_KEY}`;
 
-    if (!geminiApiKey || geminiApiKey === "ここにGenerative Language APIキーを貼り付けてください") {
-      return "<p>（総括を自動生成するには、スクリプトにAPIキーを設定してください）</p>";
+    if (!config.GEMINI_API_KEY) {
+      return "<p>（総括を自動生成するには、スクリプトプロパティに GEMINI_API_KEY を設定してください）</p>";
     }
 
     const payload = {
@@ -315,11 +750,15 @@ function formatSummaryAsHtml(text) {
 /**
  * 集計データからHTMLレポートを生成する関数
  */
-function generateHtmlReport(lastMonth, prevMonth, monthlyData) {
+function generateHtmlReport(lastMonth, prevMonth, monthlyData, heatmapData, creativeData, competitionData, pmaxData) {
   const getChange = (current, previous) => previous > 0 ? ((current / previous) - 1) * 100 : 0;
   const costChange = getChange(lastMonth.totalCost, prevMonth.totalCost);
   const clicksChange = getChange(lastMonth.totalClicks, prevMonth.totalClicks);
   const cvChange = getChange(lastMonth.totalConversions, prevMonth.totalConversions);
+  // 主要以外の役割（中間・マイクロ）のCVは、CVに数えずに件数だけを添える
+  const roles = lastMonth.conversionsByRole || { intermediate: 0, micro: 0 };
+  const roleNote = (roles.intermediate > 0 || roles.micro > 0)
+    ? `<p class="mt-1 text-xs text-gray-500">中間 ${roles.intermediate.toLocaleString()} / マイクロ ${roles.micro.toLocaleString()}</p>` : '';
 
   // Gemini APIで総括を生成し、HTMLに整形
   let summaryText = generateSummaryWithGemini(lastMonth, prevMonth, costChange, clicksChange, cvChange);
@@ -370,6 +809,26 @@ function generateHtmlReport(lastMonth, prevMonth, monthlyData) {
       return `<tr><td class="px-3 py-3 font-medium whitespace-nowrap sticky left-0 bg-white z-10 border-l-4 border-white border-r border-gray-300">${label}</td>${cells}</tr>`;
   };
 
+  // ヒートマップ・広告文・インプレッションシェア・P-MAXのタブは、元になるシートがある場合のみ表示する
+  const optionalTabs = [];
+  if (heatmapData) {
+    optionalTabs.push({ id: 'heatmap', label: '時間帯・曜日', html: buildHeatmapHtml(heatmapData) });
+  }
+  if (creativeData) {
+    optionalTabs.push({ id: 'creative', label: '広告文', html: buildCreativeHtml(creativeData) });
+  }
+  if (competitionData) {
+    const competition = buildCompetitionHtml(competitionData);
+    optionalTabs.push({ id: 'competition', label: 'インプレッションシェア', html: competition.html, script: competition.script });
+  }
+  if (pmaxData) {
+    optionalTabs.push({ id: 'pmax', label: 'P-MAX', html: buildPmaxHtml(pmaxData) });
+  }
+  const tabs = ['summary', 'monthly', 'keyword'].concat(optionalTabs.map(tab => tab.id));
+  const optionalTabButtons = optionalTabs.map(tab => `<button onclick="changeTab('${tab.id}')" id="tab-${tab.id}" class="text-gray-500 hover:text-gray-700 hover:border-gray-300 whitespace-nowrap py-3 px-1 border-b-2 font-medium text-sm">${tab.label}</button>`).join('');
+  const optionalTabContents = optionalTabs.map(tab => `<div id="content-${tab.id}" class="tab-content hidden">${tab.html}</div>`).join('');
+  const optionalTabScripts = optionalTabs.map(tab => tab.script || '').join('');
+
   const htmlTemplate = `
     <!DOCTYPE html>
     <html lang="ja">
@@ -397,6 +856,7 @@ function generateHtmlReport(lastMonth, prevMonth, monthlyData) {
                 <button onclick="changeTab('summary')" id="tab-summary" class="tab-active whitespace-nowrap py-3 px-1 border-b-2 font-medium text-sm">サマリー</button>
                 <button onclick="changeTab('monthly')" id="tab-monthly" class="text-gray-500 hover:text-gray-700 hover:border-gray-300 whitespace-nowrap py-3 px-1 border-b-2 font-medium text-sm">月別データ</button>
                 <button onclick="changeTab('keyword')" id="tab-keyword" class="text-gray-500 hover:text-gray-700 hover:border-gray-300 whitespace-nowrap py-3 px-1 border-b-2 font-medium text-sm">キーワード別実績</button>
+                ${optionalTabButtons}
             </nav></div></div>
 
             <div id="content-summary" class="tab-content">
@@ -404,7 +864,7 @@ function generateHtmlReport(lastMonth, prevMonth, monthlyData) {
                     <div class="bg-white p-4 rounded-lg shadow-sm text-center"><h3 class="text-sm font-medium text-gray-500">費用</h3><p class="mt-1 text-2xl font-bold text-gray-900">¥${Math.round(lastMonth.totalCost).toLocaleString()}</p><p class="mt-1 text-xs ${costChange >= 0 ? 'text-red-600' : 'text-green-600'}">(${costChange.toFixed(1)}% vs 前月)</p></div>
                     <div class="bg-white p-4 rounded-lg shadow-sm text-center"><h3 class="text-sm font-medium text-gray-500">クリック数</h3><p class="mt-1 text-2xl font-bold text-gray-900">${lastMonth.totalClicks.toLocaleString()}</p><p class="mt-1 text-xs ${clicksChange >= 0 ? 'text-green-600' : 'text-red-600'}">(${clicksChange.toFixed(1)}% vs 前月)</p></div>
                     <div class="bg-white p-4 rounded-lg shadow-sm text-center"><h3 class="text-sm font-medium text-gray-500">CTR</h3><p class="mt-1 text-2xl font-bold text-gray-900">${(lastMonth.ctr * 100).toFixed(2)}%</p></div>
-                    <div class="bg-white p-4 rounded-lg shadow-sm text-center"><h3 class="text-sm font-medium text-gray-500">CV</h3><p class="mt-1 text-2xl font-bold text-gray-900">${lastMonth.totalConversions.toLocaleString()}</p><p class="mt-1 text-xs ${cvChange >= 0 ? 'text-green-600' : 'text-red-600'}">(${cvChange.toFixed(1)}% vs 前月)</p></div>
+                    <div class="bg-white p-4 rounded-lg shadow-sm text-center"><h3 class="text-sm font-medium text-gray-500">CV</h3><p class="mt-1 text-2xl font-bold text-gray-900">${lastMonth.totalConversions.toLocaleString()}</p><p class="mt-1 text-xs ${cvChange >= 0 ? 'text-green-600' : 'text-red-600'}">(${cvChange.toFixed(1)}% vs 前月)</p>${roleNote}</div>
                     <div class="bg-white p-4 rounded-lg shadow-sm text-center"><h3 class="text-sm font-medium text-gray-500">CVR</h3><p class="mt-1 text-2xl font-bold text-gray-900">${(lastMonth.cvr * 100).toFixed(2)}%</p></div>
                     <div class="bg-white p-4 rounded-lg shadow-sm text-center"><h3 class="text-sm font-medium text-gray-500">CPA</h3><p class="mt-1 text-2xl font-bold text-gray-900">¥${Math.round(lastMonth.cpa).toLocaleString()}</p></div>
                 </div>
@@ -446,10 +906,11 @@ function generateHtmlReport(lastMonth, prevMonth, monthlyData) {
             <div id="content-keyword" class="tab-content hidden">
                 <div class="bg-white p-4 sm:p-6 rounded-lg shadow-sm overflow-x-auto"><h3 class="font-semibold text-gray-800 mb-4">キーワード別実績</h3><p class="text-xs text-gray-500 mb-4">※この表のコンバージョン数はキーワード別データの数値を参照しています。</p><table class="w-full text-sm text-left text-gray-500"><thead class="text-xs text-gray-700 uppercase bg-gray-50"><tr><th scope="col" class="px-6 py-3">キーワード</th><th scope="col" class="px-6 py-3">マッチタイプ</th><th scope="col" class="px-6 py-3 text-right">費用</th><th scope="col" class="px-6 py-3 text-right">クリック数</th><th scope="col" class="px-6 py-3 text-right">CV数</th></tr></thead><tbody>${Object.keys(lastMonth.keywordData).sort((a,b) => lastMonth.keywordData[b].cost - lastMonth.keywordData[a].cost).slice(0, 50).map(kw => { const k = lastMonth.keywordData[kw]; return `<tr class="bg-white border-b hover:bg-gray-50"><th scope="row" class="px-6 py-4 font-medium text-gray-900 whitespace-nowrap">${kw}</th><td class="px-6 py-4">${k.match}</td><td class="px-6 py-4 text-right">¥${Math.round(k.cost).toLocaleString()}</td><td class="px-6 py-4 text-right">${k.clicks.toLocaleString()}</td><td class="px-6 py-4 text-right font-bold">${k.cvs.toLocaleString()}</td></tr>`; }).join('')}</tbody></table></div>
             </div>
+            ${optionalTabContents}
         </div>
         <script>
             function changeTab(selectedTab) {
-                ['summary', 'monthly', 'keyword'].forEach(tab => {
+                ${JSON.stringify(tabs)}.forEach(tab => {
                     document.getElementById(\`tab-\${tab}\`).classList.toggle('tab-active', tab === selectedTab);
                     document.getElementById(\`tab-\${tab}\`).classList.toggle('text-gray-500', tab !== selectedTab);
                     document.getElementById(\`content-\${tab}\`).classList.toggle('hidden', tab !== selectedTab);
@@ -476,6 +937,7 @@ function generateHtmlReport(lastMonth, prevMonth, monthlyData) {
                 },
                 options: { responsive: true, maintainAspectRatio: false, scales: { yCpc: { type: 'linear', display: true, position: 'left', title: { display: true, text: 'CPC (円)' } }, yCvr: { type: 'linear', display: true, position: 'right', title: { display: true, text: 'CVR (%)' }, grid: { drawOnChartArea: false } } } }
             });
+            ${optionalTabScripts}
 
             // Initial scroll for monthly table if it's the default view (it's not, but good practice)
             document.addEventListener('DOMContentLoaded', (event) => {
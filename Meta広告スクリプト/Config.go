@@ -1,24 +1,136 @@
 // ================================================================
 // ▼▼▼ すべての設定をこのファイルにまとめます ▼▼▼
+// 設定値は次の順番で探し、最初に見つかった値を使います。
+//   1. スクリプトプロパティ（プロジェクトの設定 → スクリプト プロパティ）
+//   2. スプレッドシートの「設定」シート（A列: 項目名 / B列: 値）
+//   3. 下記の CONFIG_ITEMS に記載した既定値
+// アクセストークンはソースコードや「設定」シートに書かず、スクリプトプロパティに登録してください。
 // ================================================================
 
-// --- 共通設定 ---
-// 1. Meta広告のアクセストークン
-const ACCESS_TOKEN = '（ここにアクセストークンを貼り付け）';
+// 設定値を記入するシート名（任意・なくても動作します）
+const CONFIG_SHEET_NAME = '設定';
 
-// 2. Meta広告のアカウントID（"act_"から始まるもの）
-const AD_ACCOUNT_ID = 'act_（ここにアカウントIDを貼り付け）';
+// 設定項目の一覧（key が「設定」シートのA列・スクリプトプロパティのプロパティ名になります）
+const CONFIG_ITEMS = [
+  // --- 共通設定 ---
+  // 1. Meta広告のアクセストークン（スクリプトプロパティに登録）
+  { key: 'ACCESS_TOKEN', label: 'アクセストークン', type: 'secret' },
+  // 2. Meta広告のアカウントID（"act_"から始まるもの）
+  { key: 'AD_ACCOUNT_ID', label: '広告アカウントID', type: 'string', pattern: /^act_\d+$/, hint: '「act_0123456789」の形式' },
+  // 3. Graph APIのバージョン
+  { key: 'API_VERSION', label: 'APIバージョン', type: 'string', defaultValue: 'v23.0', pattern: /^v\d+\.\d+$/, hint: '「v23.0」の形式' },
+  // 4. 取得するキャンペーン（キャンペーン名にこの文字列を含むものだけを取得。空欄ならすべて）
+  { key: 'CAMPAIGN_FILTER', label: 'キャンペーンの絞り込み', type: 'string', defaultValue: '' },
 
+  // --- 日次総合レポート用設定 ---
+  { key: 'DAILY_REPORT_SHEET_NAME', label: '日次レポートのシート名', type: 'string', defaultValue: 'Meta広告レポート' },
 
-// --- 日次総合レポート用設定 ---
-const DAILY_REPORT_SHEET_NAME = 'Meta広告レポート';
+  // --- 年次総合レポート用設定 ---
+  { key: 'YEARLY_REPORT_TARGET_YEAR', label: '年次レポートの対象年', type: 'year', defaultValue: 2024 },
+  // 日次レポートと同じシートにすると、年次レポートの取得でシートが消され、日次レポートは年次レポートの期間の翌日から取り直します
+  { key: 'YEARLY_REPORT_SHEET_NAME', label: '年次レポートのシート名', type: 'string', defaultValue: 'Meta広告レポート（年次）' },
 
+  // --- 年次コンバージョンレポート用設定 ---
+  { key: 'CV_REPORT_TARGET_YEAR', label: 'コンバージョンレポートの対象年', type: 'year', defaultValue: 2024 },
+  { key: 'CV_REPORT_SHEET_NAME', label: 'コンバージョンレポートのシート名', type: 'string', defaultValue: 'Meta広告コンバージョン内訳' }
+];
 
-// --- 年次総合レポート用設定 ---
-const YEARLY_REPORT_TARGET_YEAR = 2024;
-const YEARLY_REPORT_SHEET_NAME = 'Meta広告レポート';
+// 一度読み込んだ設定（同じ実行の中では使い回す）
+let loadedConfig = null;
 
+/**
+ * 設定値を読み込み、すべての項目を検証して返す
+ * 不備がある場合は、データを取得する前に、不備のある項目をすべて並べたエラーにします。
+ * @returns {Object} - CONFIG_ITEMS の key をプロパティ名とする設定値
+ */
+function getConfig() {
+  if (loadedConfig) return loadedConfig;
 
-// --- 年次コンバージョンレポート用設定 ---
-const CV_REPORT_TARGET_YEAR = 2024;
-const CV_REPORT_SHEET_NAME = 'Meta広告コンバージョン内訳';
+  const properties = PropertiesService.getScriptProperties().getProperties();
+  const sheetValues = readConfigSheet(SpreadsheetApp.getActiveSpreadsheet());
+  const config = {};
+  const errors = [];
+
+  CONFIG_ITEMS.forEach(item => {
+    let value = item.defaultValue;
+    let source = '既定値';
+    if (properties[item.key] !== undefined && properties[item.key] !== '') {
+      value = properties[item.key];
+      source = 'スクリプトプロパティ';
+    } else if (sheetValues[item.key] !== undefined && sheetValues[item.key] !== '') {
+      value = sheetValues[item.key];
+      source = `「${CONFIG_SHEET_NAME}」シート`;
+      if (item.type === 'secret') {
+        Logger.log(`注意: ${item.label}（${item.key}）が「${CONFIG_SHEET_NAME}」シートに記入されています。シートの編集者全員が閲覧できるため、スクリプトプロパティへの移動をおすすめします。`);
+      }
+    }
+
+    const result = parseConfigValue(item, value);
+    if (result.error) {
+      errors.push(`${item.label}（${item.key}・${source}）: ${result.error}`);
+    } else {
+      config[item.key] = result.value;
+    }
+  });
+
+  Object.keys(sheetValues).forEach(key => {
+    if (!CONFIG_ITEMS.some(item => item.key === key)) {
+      errors.push(`「${CONFIG_SHEET_NAME}」シートの「${key}」は不明な項目です。項目名を確認してください。`);
+    }
+  });
+
+  if (errors.length > 0) {
+    throw new Error(`設定に${errors.length}件の不備があるため、データを取得せずに終了します。\n- ` + errors.join('\n- '));
+  }
+  loadedConfig = Object.freeze(config);
+  return loadedConfig;
+}
+
+/**
+ * 「設定」シートのA列（項目名）とB列（値）を読み込む
+ * @param {GoogleAppsScript.Spreadsheet.Spreadsheet} ss - 対象のスプレッドシート
+ * @returns {Object} - 項目名をキーとする値（シートがない場合は空のオブジェクト）
+ */
+function readConfigSheet(ss) {
+  const values = {};
+  const configSheet = ss ? ss.getSheetByName(CONFIG_SHEET_NAME) : null;
+  if (!configSheet || configSheet.getLastRow() < 1) return values;
+
+  configSheet.getRange(1, 1, configSheet.getLastRow(), 2).getValues().forEach(row => {
+    const key = String(row[0]).trim();
+    // 空行・見出し行・「#」で始まるメモ行は読み飛ばす
+    if (!key || key === '項目' || key.charAt(0) === '#') return;
+    values[key] = typeof row[1] === 'string' ? row[1].trim() : row[1];
+  });
+  return values;
+}
+
+/**
+ * 設定値を項目の型に合わせて変換し、検証する
+ * @param {Object} item - CONFIG_ITEMS の項目
+ * @param {*} value - 読み込んだ値
+ * @returns {{value: *, error: string|null}} - 変換後の値、または不備の内容
+ */
+function parseConfigValue(item, value) {
+  if (value === undefined || value === null || (value === '' && item.defaultValue === undefined)) {
+    return { value: null, error: '値が設定されていません。' };
+  }
+  const text = String(value).trim();
+  if (text.indexOf('（ここに') !== -1) {
+    return { value: null, error: '初期値（「（ここに…）」）のままです。' };
+  }
+
+  if (item.type === 'year') {
+    const year = Number(text);
+    const currentYear = new Date().getFullYear();
+    if (!/^\d{4}$/.test(text) || year < 2000 || year > currentYear) {
+      return { value: null, error: `「${text}」は正しい年ではありません（2000〜${currentYear}の西暦4桁）。` };
+    }
+    return { value: year, error: null };
+  }
+
+  if (item.pattern && !item.pattern.test(text)) {
+    return { value: null, error: `「${item.type === 'secret' ? '***' : text}」は正しい形式ではありません（${item.hint}）。` };
+  }
+  return { value: text, error: null };
+}
@@ -3,37 +3,49 @@
  * コンバージョンレポートを取得します。
  */
  function fetchMetaAdsConversions() {
+  let config = null;
   try {
     // 設定ファイル(Config.gs)から設定値を参照します
-    const targetYear = CV_REPORT_TARGET_YEAR;
-    const sheetName = CV_REPORT_SHEET_NAME;
+    config = getConfig();
+    const targetYear = config.CV_REPORT_TARGET_YEAR;
+    const sheetName = config.CV_REPORT_SHEET_NAME;
 
-    const conversionData = getConversionInsights(targetYear);
+    const dateRange = getYearDateRange(targetYear);
+    if (dateRange.startDate > dateRange.endDate) {
+      Logger.log(`${targetYear}年はまだ取得できる日がありません。`);
+      return;
+    }
+
+    const conversionData = getConversionInsights(dateRange);
     if (!conversionData || conversionData.length === 0) {
       Logger.log(`'${targetYear}'年に取得できるコンバージョンデータがありませんでした。`);
       return;
     }
     writeConversionsToSheet(conversionData, sheetName);
+    recordSyncRun(SpreadsheetApp.getActiveSpreadsheet(), { sheetName: sheetName, job: `年単位 ${targetYear}`, replacesSheet: true, startDate: dateRange.startDate, endDate: dateRange.endDate, rowCount: conversionData.length, status: RUN_STATUS.SUCCESS });
     Logger.log(`コンバージョンレポートの書き込みが完了しました。合計 ${conversionData.length} 件のデータを取得しました。`);
   } catch (e) {
     Logger.log('エラーが発生しました: ' + e.toString());
+    if (config) {
+      const failedRange = getYearDateRange(config.CV_REPORT_TARGET_YEAR);
+      recordSyncRun(SpreadsheetApp.getActiveSpreadsheet(), { sheetName: config.CV_REPORT_SHEET_NAME, job: `年単位 ${config.CV_REPORT_TARGET_YEAR}`, startDate: failedRange.startDate, endDate: failedRange.endDate, status: RUN_STATUS.ERROR, message: e.toString() });
+    }
     SpreadsheetApp.getUi().alert('エラー: ' + e.message);
   }
 }
 
 /**
  * Meta Marketing APIからコンバージョンデータを取得する
- * @param {number} targetYear - 取得対象の西暦年
+ * @param {{startDate: string, endDate: string}} dateRange - 取得期間（getYearDateRange の戻り値）
  * @return {Array} APIから取得したデータの配列
  */
-function getConversionInsights(targetYear) {
-  const startDate = `${targetYear}-01-01`;
-  const endDate = `${targetYear}-12-31`;
+function getConversionInsights(dateRange) {
+  const startDate = dateRange.startDate;
+  const endDate = dateRange.endDate;
   Logger.log(`データ取得期間: ${startDate} 〜 ${endDate}`);
 
-  // 【修正】APIバージョンを最新版(v23.0)に更新
-  const apiVersion = 'v23.0';
-  let url = `https://graph.facebook.com/${apiVersion}/${AD_ACCOUNT_ID}/insights`;
+  const config = getConfig();
+  let url = `https://graph.facebook.com/${config.API_VERSION}/${config.AD_ACCOUNT_ID}/insights`;
 
   // ★★★ コンバージョン分析に必要な項目 ★★★
   const fields = [
@@ -46,7 +58,7 @@ function getConversionInsights(targetYear) {
   ].join(',');
 
   const params = {
-    'access_token': ACCESS_TOKEN,
+    'access_token': config.ACCESS_TOKEN,
     'level': 'ad',
     'fields': fields,
     // 【修正】エラーの原因となっていたbreakdownsを削除
@@ -55,12 +67,16 @@ function getConversionInsights(targetYear) {
     'limit': 500
   };
 
+  if (config.CAMPAIGN_FILTER) {
+    params.filtering = JSON.stringify([{ field: 'campaign.name', operator: 'CONTAIN', value: config.CAMPAIGN_FILTER }]);
+  }
+
   let allData = [];
   let requestUrl = url + '?' + Object.keys(params).map(key => `${encodeURIComponent(key)}=${encodeURIComponent(params[key])}`).join('&');
 
   while (requestUrl) {
     Logger.log(`データを取得中... URL: ${requestUrl.substring(0, 150)}...`);
-    const response = UrlFetchApp.fetch(requestUrl, { 'muteHttpExceptions': true, 'headers': { 'Authorization': 'Bearer ' + ACCESS_TOKEN } });
+    const response = UrlFetchApp.fetch(requestUrl, { 'muteHttpExceptions': true, 'headers': { 'Authorization': 'Bearer ' + config.ACCESS_TOKEN } });
     const result = JSON.parse(response.getContentText());
 
     if (result.error) {
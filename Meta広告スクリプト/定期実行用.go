@@ -2,14 +2,16 @@
  * 日次トリガーで実行するメイン関数
  */
  function runDailyUpdate() {
-  // 設定ファイル(Config.gs)から設定値を参照します
-  const sheetName = DAILY_REPORT_SHEET_NAME;
+  // 設定ファイル(Config.gs)から設定値を参照します（不備があれば、ここで取得前にエラーになります）
+  const sheetName = getConfig().DAILY_REPORT_SHEET_NAME;
 
   const ss = SpreadsheetApp.getActiveSpreadsheet();
   const sheet = ss.getSheetByName(sheetName) || ss.insertSheet(sheetName);
+  let range = null;
 
   try {
-    const { startDate, endDate } = getTargetDateRange(sheet);
+    range = getTargetDateRange(sheet);
+    const { startDate, endDate } = range;
 
     if (!startDate) {
       Logger.log('データは最新の状態です。処理を終了します。');
@@ -21,45 +23,22 @@
     const reportData = getDailyInsights(startDate, endDate);
     if (!reportData || reportData.length === 0) {
       Logger.log('期間内に取得できるデータがありませんでした。');
-      return;
+    } else {
+      appendToSheet(sheet, reportData);
+      Logger.log(`レポートの書き込みが完了しました。合計 ${reportData.length} 件のデータを追記しました。`);
     }
 
-    appendToSheet(sheet, reportData);
-    Logger.log(`レポートの書き込みが完了しました。合計 ${reportData.length} 件のデータを追記しました。`);
+    // 書き込みが完了してから記録する（0件の期間も取得済みとして扱う）
+    recordSyncRun(ss, { sheetName: sheetName, startDate: startDate, endDate: endDate, rowCount: reportData ? reportData.length : 0, status: RUN_STATUS.SUCCESS });
 
   } catch (e) {
     Logger.log('エラーが発生しました: ' + e.toString());
+    if (range && range.startDate) {
+      recordSyncRun(ss, { sheetName: sheetName, startDate: range.startDate, endDate: range.endDate, status: RUN_STATUS.ERROR, message: e.toString() });
+    }
   }
 }
 
-/**
- * スプレッドシートの最終記録日から、取得すべき日付の範囲を決定する
- * @param {GoogleAppsScript.Spreadsheet.Sheet} sheet - 対象シート
- * @returns {{startDate: string|null, endDate: string|null}} - 取得開始日と終了日
- */
-function getTargetDateRange(sheet) {
-  const lastRow = sheet.getLastRow();
-  const yesterday = new Date();
-  yesterday.setDate(yesterday.getDate() - 1);
-  const endDate = formatDate(yesterday);
-
-  if (lastRow < 2) {
-    return { startDate: endDate, endDate: endDate };
-  }
-
-  const lastRecordedDateStr = sheet.getRange(lastRow, 1).getValue();
-  const lastRecordedDate = new Date(lastRecordedDateStr);
-
-  const startDate = new Date(lastRecordedDate.getTime());
-  startDate.setDate(startDate.getDate() + 1);
-
-  if (startDate > yesterday) {
-    return { startDate: null, endDate: null };
-  }
-
-  return { startDate: formatDate(startDate), endDate: endDate };
-}
-
 /**
  * Meta Marketing APIから指定期間のインサイトデータを取得する
  * @param {string} startDate - 取得開始日 (YYYY-MM-DD)
@@ -67,9 +46,8 @@ function getTargetDateRange(sheet) {
  * @returns {Array} - 取得したデータ配列
  */
 function getDailyInsights(startDate, endDate) {
-  // 【修正】APIバージョンを最新版(v23.0)に更新
-  const apiVersion = 'v23.0';
-  let url = `https://graph.facebook.com/${apiVersion}/${AD_ACCOUNT_ID}/insights`;
+  const config = getConfig();
+  let url = `https://graph.facebook.com/${config.API_VERSION}/${config.AD_ACCOUNT_ID}/insights`;
 
   const fields = [
     'campaign_name','adset_name','ad_name','spend','impressions','reach','frequency','clicks','ctr','cpc','cpm',
@@ -81,7 +59,7 @@ function getDailyInsights(startDate, endDate) {
   const breakdowns = ['publisher_platform', 'device_platform'].join(',');
 
   const params = {
-    'access_token': ACCESS_TOKEN,
+    'access_token': config.ACCESS_TOKEN,
     'level': 'ad',
     'fields': fields,
     'breakdowns': breakdowns,
@@ -90,11 +68,15 @@ function getDailyInsights(startDate, endDate) {
     'limit': 500
   };
 
+  if (config.CAMPAIGN_FILTER) {
+    params.filtering = JSON.stringify([{ field: 'campaign.name', operator: 'CONTAIN', value: config.CAMPAIGN_FILTER }]);
+  }
+
   let allData = [];
   let requestUrl = url + '?' + Object.keys(params).map(key => `${encodeURIComponent(key)}=${encodeURIComponent(params[key])}`).join('&');
 
   while (requestUrl) {
-    const response = UrlFetchApp.fetch(requestUrl, { 'muteHttpExceptions': true, 'headers': { 'Authorization': 'Bearer ' + ACCESS_TOKEN } });
+    const response = UrlFetchApp.fetch(requestUrl, { 'muteHttpExceptions': true, 'headers': { 'Authorization': 'Bearer ' + config.ACCESS_TOKEN } });
     const result = JSON.parse(response.getContentText());
 
     if (result.error) throw new Error(`APIエラー: ${result.error.message}`);
@@ -155,9 +137,3 @@ function parseActionValue(item, actionType) {
   const action = item.action_values.find(a => a.action_type === actionType);
   return action ? Number(action.value) : 0;
 }
-function formatDate(date) {
-  const y = date.getFullYear();
-  const m = ('0' + (date.getMonth() + 1)).slice(-2);
-  const d = ('0' + date.getDate()).slice(-2);
-  return `${y}-${m}-${d}`;
-}
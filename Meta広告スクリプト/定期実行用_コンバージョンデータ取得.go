@@ -3,15 +3,17 @@
  * コンバージョンレポートを更新します。
  */
  function runDailyConversionUpdate() {
-  try {
-    // 設定ファイル(Config.gs)からシート名を取得
-    const sheetName = CV_REPORT_SHEET_NAME;
+  // 設定ファイル(Config.gs)からシート名を取得
+  const sheetName = getConfig().CV_REPORT_SHEET_NAME;
 
-    const ss = SpreadsheetApp.getActiveSpreadsheet();
-    const sheet = ss.getSheetByName(sheetName) || ss.insertSheet(sheetName);
+  const ss = SpreadsheetApp.getActiveSpreadsheet();
+  const sheet = ss.getSheetByName(sheetName) || ss.insertSheet(sheetName);
+  let range = null;
 
-    // スプレッドシートの記録から、取得すべき日付の範囲を決定
-    const { startDate, endDate } = getTargetDateRange(sheet);
+  try {
+    // 実行履歴（なければスプレッドシートの記録）から、取得すべき日付の範囲を決定
+    range = getTargetDateRange(sheet);
+    const { startDate, endDate } = range;
 
     // 取得対象期間がなければ（＝昨日分まで取得済みなら）処理を終了
     if (!startDate) {
@@ -25,15 +27,20 @@
     const conversionData = getConversionInsights(startDate, endDate);
     if (!conversionData || conversionData.length === 0) {
       Logger.log('期間内に取得できるコンバージョンデータがありませんでした。');
-      return;
+    } else {
+      // スプレッドシートに追記
+      appendConversionsToSheet(sheet, conversionData);
+      Logger.log(`コンバージョンレポートの書き込みが完了しました。合計 ${conversionData.length} 件のデータを追記しました。`);
     }
 
-    // スプレッドシートに追記
-    appendConversionsToSheet(sheet, conversionData);
-    Logger.log(`コンバージョンレポートの書き込みが完了しました。合計 ${conversionData.length} 件のデータを追記しました。`);
+    // 書き込みが完了してから記録する（0件の期間も取得済みとして扱う）
+    recordSyncRun(ss, { sheetName: sheetName, startDate: startDate, endDate: endDate, rowCount: conversionData ? conversionData.length : 0, status: RUN_STATUS.SUCCESS });
 
   } catch (e) {
     Logger.log('エラーが発生しました: ' + e.toString());
+    if (range && range.startDate) {
+      recordSyncRun(ss, { sheetName: sheetName, startDate: range.startDate, endDate: range.endDate, status: RUN_STATUS.ERROR, message: e.toString() });
+    }
     SpreadsheetApp.getUi().alert('エラー: ' + e.message);
   }
 }
@@ -45,9 +52,8 @@
  * @return {Array} APIから取得したデータの配列
  */
 function getConversionInsights(startDate, endDate) {
-  // APIバージョンを最新版に設定
-  const apiVersion = 'v23.0';
-  let url = `https://graph.facebook.com/${apiVersion}/${AD_ACCOUNT_ID}/insights`;
+  const config = getConfig();
+  let url = `https://graph.facebook.com/${config.API_VERSION}/${config.AD_ACCOUNT_ID}/insights`;
 
   const fields = [
     'campaign_name',
@@ -59,7 +65,7 @@ function getConversionInsights(startDate, endDate) {
   ].join(',');
 
   const params = {
-    'access_token': ACCESS_TOKEN,
+    'access_token': config.ACCESS_TOKEN,
     'level': 'ad',
     'fields': fields,
     'time_range': JSON.stringify({'since': startDate, 'until': endDate}),
@@ -67,12 +73,16 @@ function getConversionInsights(startDate, endDate) {
     'limit': 500
   };
 
+  if (config.CAMPAIGN_FILTER) {
+    params.filtering = JSON.stringify([{ field: 'campaign.name', operator: 'CONTAIN', value: config.CAMPAIGN_FILTER }]);
+  }
+
   let allData = [];
   let requestUrl = url + '?' + Object.keys(params).map(key => `${encodeURIComponent(key)}=${encodeURIComponent(params[key])}`).join('&');
 
   while (requestUrl) {
     Logger.log(`データを取得中... URL: ${requestUrl.substring(0, 150)}...`);
-    const response = UrlFetchApp.fetch(requestUrl, { 'muteHttpExceptions': true, 'headers': { 'Authorization': 'Bearer ' + ACCESS_TOKEN } });
+    const response = UrlFetchApp.fetch(requestUrl, { 'muteHttpExceptions': true, 'headers': { 'Authorization': 'Bearer ' + config.ACCESS_TOKEN } });
     const result = JSON.parse(response.getContentText());
 
     if (result.error) {
@@ -133,45 +143,3 @@ function appendConversionsToSheet(sheet, data) {
     sheet.autoResizeColumns(1, 8);
   }
 }
-
-// --- 以下は補助的な関数 ---
-
-/**
- * スプレッドシートの最終記録日から、取得すべき日付の範囲を決定する
- * @param {GoogleAppsScript.Spreadsheet.Sheet} sheet - 対象シート
- * @returns {{startDate: string|null, endDate: string|null}} - 取得開始日と終了日
- */
-function getTargetDateRange(sheet) {
-  const lastRow = sheet.getLastRow();
-  const yesterday = new Date();
-  yesterday.setDate(yesterday.getDate() - 1);
-  const endDate = formatDate(yesterday);
-
-  if (lastRow < 2) {
-    return { startDate: endDate, endDate: endDate };
-  }
-
-  const lastRecordedDateStr = sheet.getRange(lastRow, 1).getValue();
-  const lastRecordedDate = new Date(lastRecordedDateStr);
-
-  const startDate = new Date(lastRecordedDate.getTime());
-  startDate.setDate(startDate.getDate() + 1);
-
-  if (startDate > yesterday) {
-    return { startDate: null, endDate: null };
-  }
-
-  return { startDate: formatDate(startDate), endDate: endDate };
-}
-
-/**
- * Dateオブジェクトを 'YYYY-MM-DD' 形式の文字列に変換する
- * @param {Date} date - 変換するDateオブジェクト
- * @returns {string} - フォーマットされた日付文字列
- */
-function formatDate(date) {
-  const y = date.getFullYear();
-  const m = ('0' + (date.getMonth() + 1)).slice(-2);
-  const d = ('0' + date.getDate()).slice(-2);
-  return `${y}-${m}-${d}`;
-}
@@ -0,0 +1,177 @@
+// ================================================================
+// ▼▼▼ 実行履歴（どこまで取得済みか）の記録 ▼▼▼
+// 各レポートの書き込みが成功した後に、取得期間・件数・結果を「実行履歴」シートへ記録します。
+// 日次更新は、シートの最終行ではなくこの記録から次回の取得開始日を決定します。
+// ================================================================
+
+// 実行履歴を記録するシート名（自動作成されます）
+const HISTORY_SHEET_NAME = '実行履歴';
+
+const HISTORY_HEADERS = ['記録日時', 'シート名', '開始日', '終了日', '件数', 'ステータス', 'メッセージ'];
+
+// 実行結果のステータス（取得済みとして扱うのは「成功」の行のみ）
+const RUN_STATUS = {
+  SUCCESS: '成功',
+  ERROR: 'エラー'
+};
+
+// シートを消してから書き直した取得に付ける目印（これより前の記録は、シートに残っていないため取得済みとして扱わない）
+const SHEET_REPLACED_LABEL = 'シート全体を置き換え';
+
+/**
+ * 実行履歴シートを取得し、存在しなければ作成する
+ * @param {GoogleAppsScript.Spreadsheet.Spreadsheet} ss - 対象のスプレッドシート
+ * @returns {GoogleAppsScript.Spreadsheet.Sheet} - 実行履歴シート
+ */
+function getHistorySheet(ss) {
+  let historySheet = ss.getSheetByName(HISTORY_SHEET_NAME);
+  if (!historySheet) {
+    historySheet = ss.insertSheet(HISTORY_SHEET_NAME);
+    historySheet.getRange(1, 1, 1, HISTORY_HEADERS.length).setValues([HISTORY_HEADERS]).setFontWeight('bold');
+    // 日付が自動変換されないよう、開始日・終了日は文字列として保存する
+    historySheet.getRange('C:D').setNumberFormat('@');
+  }
+  return historySheet;
+}
+
+/**
+ * 指定したシートについて、途切れずに取得済みの最後の日を返す
+ * 日次更新の成功した終了日を起点にし、そこから隙間なく続く年単位の取得だけを加える。
+ * 年単位の取得の終了日をそのまま使うと、日次更新との間の取得していない日が飛ばされるため。
+ * シートを消して書き直した取得（replacesSheet）があれば、それより前の記録は使わず、その終了日から数え直す。
+ * @param {GoogleAppsScript.Spreadsheet.Spreadsheet} ss - 対象のスプレッドシート
+ * @param {string} sheetName - データを記録しているシート名
+ * @returns {string|null} - 'YYYY-MM-DD' 形式の日付（記録がない場合は null）
+ */
+function getSyncWatermark(ss, sheetName) {
+  const historySheet = ss.getSheetByName(HISTORY_SHEET_NAME);
+  if (!historySheet || historySheet.getLastRow() < 2) {
+    return null;
+  }
+  const timezone = ss.getSpreadsheetTimeZone();
+  const values = historySheet.getRange(2, 1, historySheet.getLastRow() - 1, HISTORY_HEADERS.length).getValues();
+
+  let watermark = null;
+  const runs = [];
+  values.forEach(row => {
+    if (row[1] !== sheetName || row[5] !== RUN_STATUS.SUCCESS) return;
+    const startDate = toDateString(row[2], timezone);
+    const endDate = toDateString(row[3], timezone);
+    if (!startDate || !endDate) return;
+    if (String(row[6]).indexOf(`・${SHEET_REPLACED_LABEL}]`) !== -1) {
+      runs.length = 0;
+      watermark = endDate;
+      return;
+    }
+    runs.push({ startDate: startDate, endDate: endDate });
+    // 年単位の取得はメッセージの先頭に「[年単位 2025]」が付く（recordSyncRun の job）
+    const isDaily = String(row[6]).indexOf('[') !== 0;
+    if (isDaily && (!watermark || endDate > watermark)) watermark = endDate;
+  });
+  if (runs.length === 0) return watermark;
+
+  runs.sort((a, b) => (a.startDate < b.startDate ? -1 : a.startDate > b.startDate ? 1 : 0));
+  runs.forEach(run => {
+    // 日次更新の記録がなければ、最も古い取得から始まる連続した期間を使う
+    if (!watermark) {
+      watermark = run.endDate;
+    } else if (run.startDate <= addDays(watermark, 1) && run.endDate > watermark) {
+      watermark = run.endDate;
+    }
+  });
+  return watermark;
+}
+
+/**
+ * 'YYYY-MM-DD' 形式の日付に日数を足す
+ */
+function addDays(dateStr, days) {
+  const parts = dateStr.split('-').map(Number);
+  const date = new Date(Date.UTC(parts[0], parts[1] - 1, parts[2] + days));
+  return date.toISOString().slice(0, 10);
+}
+
+/**
+ * 年単位で取得する期間を返す（対象年が今年の場合は、取得できる前日までにする）
+ * 日次更新は終了日の翌日から取得するため、まだ来ていない日を取得済みとして記録しないようにする
+ * @param {number} targetYear - 取得対象の西暦年
+ * @returns {{startDate: string, endDate: string}} - 'YYYY-MM-DD' 形式の取得開始日と終了日
+ */
+function getYearDateRange(targetYear) {
+  const yesterday = new Date();
+  yesterday.setDate(yesterday.getDate() - 1);
+  const yesterdayStr = formatDate(yesterday);
+  const yearEnd = `${targetYear}-12-31`;
+  return { startDate: `${targetYear}-01-01`, endDate: yearEnd < yesterdayStr ? yearEnd : yesterdayStr };
+}
+
+/**
+ * 実行履歴（なければシートの最終行の日付）から、日次更新で取得する日付の範囲を決定する
+ * 履歴がなく、最終行のA列が日付として読み取れない（見出しだけ・文字列など）場合は、前日の1日分を取得する
+ * @param {GoogleAppsScript.Spreadsheet.Sheet} sheet - 対象シート
+ * @returns {{startDate: string|null, endDate: string|null}} - 取得開始日と終了日（取得済みなら両方 null）
+ */
+function getTargetDateRange(sheet) {
+  const yesterday = new Date();
+  yesterday.setDate(yesterday.getDate() - 1);
+  const endDate = formatDate(yesterday);
+
+  const lastRow = sheet.getLastRow();
+  const lastRecordedDate = getSyncWatermark(sheet.getParent(), sheet.getName()) ||
+    (lastRow >= 2 ? toDateString(sheet.getRange(lastRow, 1).getValue(), sheet.getParent().getSpreadsheetTimeZone()) : null);
+  if (!lastRecordedDate) {
+    if (lastRow >= 2) {
+      Logger.log(`「${sheet.getName()}」シートの最終行（${lastRow}行目）のA列が日付ではないため、前日分から取得します。`);
+    }
+    return { startDate: endDate, endDate: endDate };
+  }
+
+  const startDate = addDays(lastRecordedDate, 1);
+  if (startDate > endDate) {
+    return { startDate: null, endDate: null };
+  }
+  return { startDate: startDate, endDate: endDate };
+}
+
+/**
+ * Dateオブジェクトを 'YYYY-MM-DD' 形式の文字列に変換する
+ */
+function formatDate(date) {
+  const y = date.getFullYear();
+  const m = ('0' + (date.getMonth() + 1)).slice(-2);
+  const d = ('0' + date.getDate()).slice(-2);
+  return `${y}-${m}-${d}`;
+}
+
+/**
+ * 実行結果を実行履歴シートに1行追記する
+ * job を指定した場合（年単位の取得）は、メッセージの先頭に「[job]」を付けて日次更新の記録と区別する
+ * replacesSheet を指定した場合は「[job・シート全体を置き換え]」とし、それまでの取得済みの日をこの取得の期間で置き換える
+ * @param {GoogleAppsScript.Spreadsheet.Spreadsheet} ss - 対象のスプレッドシート
+ * @param {Object} entry - 記録内容（sheetName, job, replacesSheet, startDate, endDate, rowCount, status, message）
+ */
+function recordSyncRun(ss, entry) {
+  const jobPrefix = entry.job ? `[${entry.job}${entry.replacesSheet ? '・' + SHEET_REPLACED_LABEL : ''}] ` : '';
+  getHistorySheet(ss).appendRow([
+    new Date(),
+    entry.sheetName,
+    entry.startDate || '',
+    entry.endDate || '',
+    entry.rowCount || 0,
+    entry.status,
+    (jobPrefix + (entry.message || '')).trim()
+  ]);
+}
+
+/**
+ * セルの値（Date または日付文字列）を 'YYYY-MM-DD' 形式に変換する
+ * @returns {string|null} - 日付として解釈できない場合は null
+ */
+function toDateString(value, timezone) {
+  if (value instanceof Date) {
+    return isNaN(value.getTime()) ? null : Utilities.formatDate(value, timezone, 'yyyy-MM-dd');
+  }
+  const digits = String(value).replace(/[^0-9]/g, '');
+  if (digits.length !== 8) return null;
+  return `${digits.slice(0, 4)}-${digits.slice(4, 6)}-${digits.slice(6, 8)}`;
+}
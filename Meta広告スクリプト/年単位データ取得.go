@@ -3,40 +3,53 @@
  * 指定された1年分の総合レポートを取得します。
  */
  function fetchYearlyReport() {
+  let config = null;
   try {
     // 設定ファイル(Config.gs)から設定値を参照します
-    const targetYear = YEARLY_REPORT_TARGET_YEAR;
-    const sheetName = YEARLY_REPORT_SHEET_NAME;
+    config = getConfig();
+    const targetYear = config.YEARLY_REPORT_TARGET_YEAR;
+    const sheetName = config.YEARLY_REPORT_SHEET_NAME;
 
     Logger.log(`${targetYear}年の総合レポートを取得します...`);
 
-    const reportData = getYearlyInsights(targetYear);
+    const dateRange = getYearDateRange(targetYear);
+    if (dateRange.startDate > dateRange.endDate) {
+      Logger.log(`${targetYear}年はまだ取得できる日がありません。`);
+      return;
+    }
+
+    const reportData = getYearlyInsights(dateRange);
     if (!reportData || reportData.length === 0) {
       Logger.log(`'${targetYear}'年に取得できるデータがありませんでした。`);
       return;
     }
 
     writeYearlyReportToSheet(reportData, sheetName);
+    recordSyncRun(SpreadsheetApp.getActiveSpreadsheet(), { sheetName: sheetName, job: `年単位 ${targetYear}`, replacesSheet: true, startDate: dateRange.startDate, endDate: dateRange.endDate, rowCount: reportData.length, status: RUN_STATUS.SUCCESS });
     Logger.log(`年次レポートの書き込みが完了しました。合計 ${reportData.length} 件のデータを取得しました。`);
 
   } catch (e) {
     Logger.log('エラーが発生しました: ' + e.toString());
+    // 設定の不備で止まった場合は、記録先が決まらないため実行履歴には記録しない
+    if (config) {
+      const failedRange = getYearDateRange(config.YEARLY_REPORT_TARGET_YEAR);
+      recordSyncRun(SpreadsheetApp.getActiveSpreadsheet(), { sheetName: config.YEARLY_REPORT_SHEET_NAME, job: `年単位 ${config.YEARLY_REPORT_TARGET_YEAR}`, startDate: failedRange.startDate, endDate: failedRange.endDate, status: RUN_STATUS.ERROR, message: e.toString() });
+    }
     SpreadsheetApp.getUi().alert('エラー: ' + e.message);
   }
 }
 
 /**
  * Meta Marketing APIから指定した1年分のインサイトデータを取得する
- * @param {number} targetYear - 取得対象の西暦年
+ * @param {{startDate: string, endDate: string}} dateRange - 取得期間（getYearDateRange の戻り値）
  * @returns {Array} - 取得したデータ配列
  */
-function getYearlyInsights(targetYear) {
-  const startDate = `${targetYear}-01-01`;
-  const endDate = `${targetYear}-12-31`;
+function getYearlyInsights(dateRange) {
+  const startDate = dateRange.startDate;
+  const endDate = dateRange.endDate;
 
-  // 【修正】APIバージョンを最新版(v23.0)に更新
-  const apiVersion = 'v23.0';
-  let url = `https://graph.facebook.com/${apiVersion}/${AD_ACCOUNT_ID}/insights`;
+  const config = getConfig();
+  let url = `https://graph.facebook.com/${config.API_VERSION}/${config.AD_ACCOUNT_ID}/insights`;
 
   const fields = [
     'campaign_name','adset_name','ad_name','spend','impressions','reach','frequency','clicks','ctr','cpc','cpm',
@@ -48,7 +61,7 @@ function getYearlyInsights(targetYear) {
   const breakdowns = ['publisher_platform', 'device_platform'].join(',');
 
   const params = {
-    'access_token': ACCESS_TOKEN,
+    'access_token': config.ACCESS_TOKEN,
     'level': 'ad',
     'fields': fields,
     'breakdowns': breakdowns,
@@ -57,11 +70,15 @@ function getYearlyInsights(targetYear) {
     'limit': 500
   };
 
+  if (config.CAMPAIGN_FILTER) {
+    params.filtering = JSON.stringify([{ field: 'campaign.name', operator: 'CONTAIN', value: config.CAMPAIGN_FILTER }]);
+  }
+
   let allData = [];
   let requestUrl = url + '?' + Object.keys(params).map(key => `${encodeURIComponent(key)}=${encodeURIComponent(params[key])}`).join('&');
 
   while (requestUrl) {
-    const response = UrlFetchApp.fetch(requestUrl, { 'muteHttpExceptions': true, 'headers': { 'Authorization': 'Bearer ' + ACCESS_TOKEN } });
+    const response = UrlFetchApp.fetch(requestUrl, { 'muteHttpExceptions': true, 'headers': { 'Authorization': 'Bearer ' + config.ACCESS_TOKEN } });
     const result = JSON.parse(response.getContentText());
 
     if (result.error) throw new Error(`APIエラー: ${result.error.message}`);
@@ -10,6 +10,17 @@ const TARGET_YEAR = 2024;
 // 出力先のシート名
 const SHEET_NAME = '検索広告（YSA）';
 
+// 実行履歴を記録するシート名（定期実行用と共通・自動作成されます）
+const HISTORY_SHEET_NAME = '実行履歴';
+
+const HISTORY_HEADERS = ['記録日時', 'シート名', '開始日', '終了日', '件数', 'ステータス', 'メッセージ'];
+
+// 正規化した最終リンク先URLを記録する列の見出し（Google広告の「ランディングページ別データ取得」と同じ表記。シートの最後の列に追加されます）
+const LANDING_PAGE_HEADER = 'ランディングページ';
+
+// ★ランディングページの列は、Google広告スクリプトの「共通/URL正規化.go」で正規化します。
+//   このスクリプトの下に「共通/URL正規化.go」の内容をそのまま貼り付けてください（Google広告と同じ表記になります）。
+
 
 /************************************
  * メイン処理
@@ -44,8 +55,13 @@ function main() {
   // --------------------------------
 
   // --- 2. レポート取得の準備 ---
-  const startDate = TARGET_YEAR + '0101';
-  const endDate = TARGET_YEAR + '1231';
+  const dateRange = getTargetDateRange();
+  if (dateRange.startDate > dateRange.endDate) {
+    Logger.log(TARGET_YEAR + '年はまだ取得できる日がありません。');
+    return;
+  }
+  const startDate = dateRange.startDate.replace(/-/g, '');
+  const endDate = dateRange.endDate.replace(/-/g, '');
 
   // AdsUtilities を使用したセレクターを作成
   const selector = {
@@ -84,8 +100,10 @@ function main() {
         });
       }
       // ▲▲▲ 並び替えここまで ▲▲▲
+      appendLandingPageColumn(reportData, reportFields);
 
       writeDataToSheet(reportData, reportFields, headerMapping);
+      recordExecution(dateRange, reportData.length, '成功', '');
       Logger.log('スプレッドシートへの書き込みが完了しました。');
     } else {
       Logger.log('レポートデータを取得できませんでした。');
@@ -93,10 +111,12 @@ function main() {
         // エラー内容を分かりやすく整形してログに出力
         const errorMessages = report.errors.map(e => `[${e.errorCode}] ${e.message} (${JSON.stringify(e.details)})`).join('\n');
         Logger.log('エラー詳細:\n' + errorMessages);
+        recordExecution(dateRange, 0, 'エラー', errorMessages);
       }
     }
   } catch (e) {
     Logger.log('レポート取得中にエラーが発生しました: ' + e);
+    recordExecution(dateRange, 0, 'エラー', String(e));
     throw e;
   }
 }
@@ -113,7 +133,7 @@ function writeDataToSheet(reportData, reportFields, headerMapping) {
   sheet.clear();
 
   // 日本語ヘッダーを作成
-  const japaneseHeaders = reportFields.map(field => headerMapping[field] || field);
+  const japaneseHeaders = reportFields.map(field => headerMapping[field] || field).concat([LANDING_PAGE_HEADER]);
   sheet.getRange(1, 1, 1, japaneseHeaders.length).setValues([japaneseHeaders]);
 
   // データ部を書き込み
@@ -121,3 +141,47 @@ function writeDataToSheet(reportData, reportFields, headerMapping) {
     sheet.getRange(2, 1, reportData.length, reportData[0].length).setValues(reportData);
   }
 }
+
+/************************************
+ * 各行の末尾に、正規化した最終リンク先URL（ランディングページ）を追加する関数
+ ************************************/
+function appendLandingPageColumn(reportData, reportFields) {
+  if (typeof normalizeLandingPageUrl !== 'function') {
+    throw new Error('「共通/URL正規化.go」が貼り付けられていません。Google広告スクリプトの「共通/URL正規化.go」をこのスクリプトの下に貼り付けてください。');
+  }
+  const urlIndex = reportFields.indexOf('FINAL_URL');
+  reportData.forEach(row => {
+    row.push(urlIndex === -1 ? '' : normalizeLandingPageUrl(row[urlIndex]));
+  });
+}
+
+/************************************
+ * 取得する期間を返す関数
+ * 今年を指定した場合は前日までにします（定期実行用は実行履歴の終了日の翌日から取得するため、まだ来ていない日を記録しないようにします）。
+ ************************************/
+function getTargetDateRange() {
+  const yesterday = new Date();
+  yesterday.setDate(yesterday.getDate() - 1);
+  const yesterdayStr = yesterday.getFullYear() + '-' + ('0' + (yesterday.getMonth() + 1)).slice(-2) + '-' + ('0' + yesterday.getDate()).slice(-2);
+  const yearEnd = TARGET_YEAR + '-12-31';
+  return { startDate: TARGET_YEAR + '-01-01', endDate: yearEnd < yesterdayStr ? yearEnd : yesterdayStr };
+}
+
+/************************************
+ * 実行履歴シートに取得期間・件数・結果を記録する関数
+ * ※以前の形式（A1「最終データ取得日」）のシートは、定期実行用のスクリプトを一度実行すると移行されます。
+ ************************************/
+function recordExecution(dateRange, rowCount, status, message) {
+  const spreadsheet = SpreadsheetApp.openByUrl(SPREADSHEET_URL);
+  let historySheet = spreadsheet.getSheetByName(HISTORY_SHEET_NAME);
+  if (!historySheet) {
+    historySheet = spreadsheet.insertSheet(HISTORY_SHEET_NAME);
+    historySheet.getRange(1, 1, 1, HISTORY_HEADERS.length).setValues([HISTORY_HEADERS]).setFontWeight('bold');
+    historySheet.getRange('C:D').setNumberFormat('@');
+  }
+  // 定期実行用のスクリプトが年単位の取得の行と区別できるよう、「[年単位 2025・シート全体を置き換え]」を付ける
+  // （シートを消してから書き直すため、定期実行用はこれより前の記録を使わず、この終了日の翌日から取得します）
+  historySheet.appendRow([
+    new Date(), SHEET_NAME, dateRange.startDate, dateRange.endDate, rowCount, status, ('[年単位 ' + TARGET_YEAR + '・シート全体を置き換え] ' + message).trim()
+  ]);
+}
@@ -10,6 +10,21 @@ const DATA_SHEET_NAME = '検索広告（YSA）';
 // 実行履歴を記録するシート名（自動作成されます）
 const HISTORY_SHEET_NAME = '実行履歴';
 
+// 実行履歴シートの列（Google広告スクリプトの実行履歴と同じ形式）
+const HISTORY_HEADERS = ['記録日時', 'シート名', '開始日', '終了日', '件数', 'ステータス', 'メッセージ'];
+
+// 実行結果のステータス（最終データ取得日として扱うのは「成功」の行のみ）
+const RUN_STATUS = {
+  SUCCESS: '成功',
+  ERROR: 'エラー'
+};
+
+// 正規化した最終リンク先URLを記録する列の見出し（Google広告の「ランディングページ別データ取得」と同じ表記。シートの最後の列に追加されます）
+const LANDING_PAGE_HEADER = 'ランディングページ';
+
+// ★ランディングページの列は、Google広告スクリプトの「共通/URL正規化.go」で正規化します。
+//   このスクリプトの下に「共通/URL正規化.go」の内容をそのまま貼り付けてください（Google広告と同じ表記になります）。
+
 
 /************************************
  * メイン処理
@@ -42,7 +57,7 @@ function main() {
 
   // --- 2. レポート取得期間の決定 ---
   const spreadsheet = SpreadsheetApp.openByUrl(SPREADSHEET_URL);
-  const lastDate = getLastExecutionDate(spreadsheet);
+  const lastDate = getLastExecutionDate(spreadsheet, DATA_SHEET_NAME);
   const yesterday = new Date();
   yesterday.setDate(yesterday.getDate() - 1);
 
@@ -93,53 +108,141 @@ function main() {
         Logger.log('日付でデータを並び替えます...');
         reportData.sort((a, b) => (a[dayIndex] < b[dayIndex] ? -1 : 1));
       }
+      appendLandingPageColumn(reportData, reportFields);
 
       appendDataToSheet(spreadsheet, reportData, reportFields, headerMapping);
-      updateExecutionDate(spreadsheet, yesterday); // 成功したので最終実行日を更新
-      Logger.log('スプレッドシートへの書き込みと実行日の更新が完了しました。');
+      // 成功したので取得期間を記録
+      recordExecution(spreadsheet, {
+        startDate: startDate, endDate: yesterday, rowCount: reportData.length, status: RUN_STATUS.SUCCESS
+      });
+      Logger.log('スプレッドシートへの書き込みと実行履歴の記録が完了しました。');
 
     } else {
       Logger.log('レポート期間内にデータが見つかりませんでした。');
       if (report.errors) {
         const errorMessages = report.errors.map(e => `[${e.errorCode}] ${e.message} (${JSON.stringify(e.details)})`).join('\n');
         Logger.log('エラー詳細:\n' + errorMessages);
+        recordExecution(spreadsheet, {
+          startDate: startDate, endDate: yesterday, status: RUN_STATUS.ERROR, message: errorMessages
+        });
       } else {
-        // データが0件でも、期間の更新は行う
-        updateExecutionDate(spreadsheet, yesterday);
-        Logger.log('データは0件でしたが、実行日は更新しました。');
+        // データが0件でも、取得済みとして記録する
+        recordExecution(spreadsheet, {
+          startDate: startDate, endDate: yesterday, rowCount: 0, status: RUN_STATUS.SUCCESS
+        });
+        Logger.log('データは0件でしたが、実行履歴は記録しました。');
       }
     }
   } catch (e) {
     Logger.log('レポート取得中にエラーが発生しました: ' + e);
+    recordExecution(spreadsheet, {
+      startDate: startDate, endDate: yesterday, status: RUN_STATUS.ERROR, message: String(e)
+    });
     throw e;
   }
 }
 
 /************************************
- * 実行履歴シートから最終実行日を取得する関数
+ * 実行履歴シートを取得する関数
+ * 以前の形式（A1「最終データ取得日」、A2に日付）の場合は、新しい形式に移行します。
  ************************************/
-function getLastExecutionDate(spreadsheet) {
+function getHistorySheet(spreadsheet) {
   let historySheet = spreadsheet.getSheetByName(HISTORY_SHEET_NAME);
   if (!historySheet) {
     Logger.log('実行履歴シートが見つからないため、作成します。');
     historySheet = spreadsheet.insertSheet(HISTORY_SHEET_NAME);
-    historySheet.getRange('A1').setValue('最終データ取得日');
+    historySheet.getRange(1, 1, 1, HISTORY_HEADERS.length).setValues([HISTORY_HEADERS]).setFontWeight('bold');
+    historySheet.getRange('C:D').setNumberFormat('@');
+    return historySheet;
+  }
+
+  if (historySheet.getRange('A1').getValue() === '最終データ取得日') {
+    const legacyDate = historySheet.getRange('A2').getValue();
+    historySheet.clear();
+    historySheet.getRange(1, 1, 1, HISTORY_HEADERS.length).setValues([HISTORY_HEADERS]).setFontWeight('bold');
+    historySheet.getRange('C:D').setNumberFormat('@');
+    if (legacyDate instanceof Date) {
+      historySheet.appendRow([
+        new Date(), DATA_SHEET_NAME, '', formatDate(legacyDate, 'YYYY-MM-DD'), 0, RUN_STATUS.SUCCESS, '旧形式の実行履歴から移行'
+      ]);
+    }
+    Logger.log('実行履歴シートを新しい形式に移行しました。');
   }
-  const lastDate = historySheet.getRange('A2').getValue();
-  return lastDate instanceof Date ? lastDate : null;
+  return historySheet;
 }
 
 /************************************
- * 実行履歴シートに最終実行日を記録する関数
+ * 実行履歴シートから、指定シートの最終データ取得日を取得する関数
+ * 定期実行の「成功」の行の終了日を起点にし、そこから隙間なく続く年単位の取得（メッセージが「[年単位 2025]」で始まる行）だけを加えます。
+ * 年単位の取得の終了日をそのまま使うと、その間の取得していない日が飛ばされるためです。
+ * 年単位の取得はシートを消してから書き直すため（「・シート全体を置き換え」が付いた行）、それより前の記録は使わず、その終了日から数え直します。
  ************************************/
-function updateExecutionDate(spreadsheet, date) {
-  let historySheet = spreadsheet.getSheetByName(HISTORY_SHEET_NAME);
-  if (!historySheet) {
-    historySheet = spreadsheet.insertSheet(HISTORY_SHEET_NAME);
-    historySheet.getRange('A1').setValue('最終データ取得日');
+function getLastExecutionDate(spreadsheet, sheetName) {
+  const historySheet = getHistorySheet(spreadsheet);
+  if (historySheet.getLastRow() <= 1) {
+    return null;
+  }
+  const values = historySheet.getRange(2, 1, historySheet.getLastRow() - 1, HISTORY_HEADERS.length).getValues();
+
+  let lastDate = null;
+  const runs = [];
+  values.forEach(row => {
+    if (row[1] !== sheetName || row[5] !== RUN_STATUS.SUCCESS) {
+      return;
+    }
+    const endDate = parseDate(row[3]);
+    if (!endDate) {
+      return;
+    }
+    if (String(row[6]).indexOf('・シート全体を置き換え]') !== -1) {
+      runs.length = 0;
+      lastDate = endDate;
+      return;
+    }
+    // 旧形式から移行した行は開始日が空欄のため、終了日までを取得済みとして扱う
+    runs.push({ startDate: parseDate(row[2]) || endDate, endDate: endDate });
+    const isDaily = String(row[6]).indexOf('[') !== 0;
+    if (isDaily && (!lastDate || endDate > lastDate)) {
+      lastDate = endDate;
+    }
+  });
+  if (runs.length === 0) {
+    return lastDate;
+  }
+
+  runs.sort((a, b) => a.startDate - b.startDate);
+  runs.forEach(run => {
+    // 定期実行の記録がなければ、最も古い取得から始まる連続した期間を使う
+    if (!lastDate) {
+      lastDate = run.endDate;
+      return;
+    }
+    const nextDay = new Date(lastDate.getTime());
+    nextDay.setDate(nextDay.getDate() + 1);
+    if (run.startDate <= nextDay && run.endDate > lastDate) {
+      lastDate = run.endDate;
+    }
+  });
+  return lastDate;
+}
+
+/************************************
+ * 実行履歴シートに取得期間・件数・結果を記録する関数
+ ************************************/
+function recordExecution(spreadsheet, entry) {
+  const historySheet = getHistorySheet(spreadsheet);
+  historySheet.appendRow([
+    new Date(),
+    DATA_SHEET_NAME,
+    formatDate(entry.startDate, 'YYYY-MM-DD'),
+    formatDate(entry.endDate, 'YYYY-MM-DD'),
+    entry.rowCount || 0,
+    entry.status,
+    entry.message || ''
+  ]);
+  if (entry.status === RUN_STATUS.SUCCESS) {
+    Logger.log('最終データ取得日を ' + formatDate(entry.endDate, 'YYYY/MM/DD') + ' として記録しました。');
   }
-  historySheet.getRange('A2').setValue(date);
-  Logger.log('最終データ取得日を ' + formatDate(date, 'YYYY/MM/DD') + ' に更新しました。');
 }
 
 /************************************
@@ -151,10 +254,13 @@ function appendDataToSheet(spreadsheet, reportData, reportFields, headerMapping)
     dataSheet = spreadsheet.insertSheet(DATA_SHEET_NAME);
   }
 
-  // ヘッダー行がなければ書き込む
+  // ヘッダー行がなければ書き込む（ランディングページの列がない以前のシートは、見出しの末尾に追加する）
+  const japaneseHeaders = reportFields.map(field => headerMapping[field] || field).concat([LANDING_PAGE_HEADER]);
   if (dataSheet.getLastRow() === 0) {
-    const japaneseHeaders = reportFields.map(field => headerMapping[field] || field);
     dataSheet.getRange(1, 1, 1, japaneseHeaders.length).setValues([japaneseHeaders]);
+  } else if (dataSheet.getLastColumn() < japaneseHeaders.length) {
+    dataSheet.getRange(1, 1, 1, japaneseHeaders.length).setValues([japaneseHeaders]);
+    Logger.log('見出しの末尾に「' + LANDING_PAGE_HEADER + '」の列を追加しました（以前の行は空欄です）。');
   }
 
   // データ部を最終行に追記
@@ -163,6 +269,19 @@ function appendDataToSheet(spreadsheet, reportData, reportFields, headerMapping)
   }
 }
 
+/************************************
+ * 各行の末尾に、正規化した最終リンク先URL（ランディングページ）を追加する関数
+ ************************************/
+function appendLandingPageColumn(reportData, reportFields) {
+  if (typeof normalizeLandingPageUrl !== 'function') {
+    throw new Error('「共通/URL正規化.go」が貼り付けられていません。Google広告スクリプトの「共通/URL正規化.go」をこのスクリプトの下に貼り付けてください。');
+  }
+  const urlIndex = reportFields.indexOf('FINAL_URL');
+  reportData.forEach(row => {
+    row.push(urlIndex === -1 ? '' : normalizeLandingPageUrl(row[urlIndex]));
+  });
+}
+
 /************************************
  * 日付オブジェクトを文字列にフォーマットする関数
  ************************************/
@@ -173,5 +292,22 @@ function formatDate(dateObj, format) {
   if (format === 'YYYYMMDD') {
     return `${year}${month}${day}`;
   }
+  if (format === 'YYYY-MM-DD') {
+    return `${year}-${month}-${day}`;
+  }
   return `${year}/${month}/${day}`;
 }
+
+/************************************
+ * セルの値（Date または 'YYYY-MM-DD' などの文字列）を日付オブジェクトに変換する関数
+ ************************************/
+function parseDate(value) {
+  if (value instanceof Date) {
+    return isNaN(value.getTime()) ? null : value;
+  }
+  const digits = String(value).replace(/[^0-9]/g, '');
+  if (digits.length !== 8) {
+    return null;
+  }
+  return new Date(Number(digits.slice(0, 4)), Number(digits.slice(4, 6)) - 1, Number(digits.slice(6, 8)));
+}
@@ -0,0 +1,83 @@
+'use strict';
+/**
+ * 【MCC実行】各アカウントの実行結果を「MCC実行結果」シートにまとめるときのアカウント名と、親スクリプトの runSync() が返す結果を確認する
+ */
+const test = require('node:test');
+const assert = require('node:assert');
+const { loadScripts } = require('./ハーネス.js');
+
+const FILES = [
+  'Google広告スクリプト/共通/同期処理.go',
+  'Google広告スクリプト/共通/実行履歴.go',
+  'Google広告スクリプト/共通/MCC実行.go'
+];
+// runSync() から呼び出すときに必要なファイル
+const SYNC_FILES = FILES.concat([
+  'Google広告スクリプト/共通/スキーマ.go',
+  'Google広告スクリプト/共通/設定.go',
+  'Google広告スクリプト/共通/列挙値.go'
+]);
+const URL = 'https://docs.google.com/spreadsheets/d/test-mcc';
+
+test('「アカウント一覧」シートのアカウント名を記録し、空欄のアカウントだけGoogle広告から取得する', () => {
+  const fixture = { spreadsheets: {} };
+  fixture.spreadsheets[URL] = {};
+  const harness = loadScripts(FILES, { fixture: fixture });
+  const lookups = [];
+  harness.context.AdsManagerApp = {
+    accounts: () => ({
+      withIds: ids => ({
+        get: () => {
+          lookups.push(ids[0]);
+          let done = false;
+          return { hasNext: () => !done, next: () => { done = true; return { getName: () => 'API上の名前' }; } };
+        }
+      })
+    })
+  };
+  const returned = customerId => JSON.stringify({
+    status: '成功', rowCount: 1, startDate: '2025-07-14', endDate: '2025-07-14', message: '',
+    controlSpreadsheetUrl: URL, datasetName: '基本データ',
+    accountNames: { '123-456-7890': '○○株式会社', '234-567-8901': '' }
+  });
+  const result = customerId => ({ getCustomerId: () => customerId, getReturnValue: () => returned(customerId), getStatus: () => 'OK', getError: () => '' });
+  harness.call('summarizeMccResults', [result('123-456-7890'), result('234-567-8901')]);
+
+  const rows = harness.sheetValues(URL)['MCC実行結果'];
+  assert.deepStrictEqual(rows.slice(1).map(row => [row[1], row[2], row[3]]), [
+    ['123-456-7890', '○○株式会社', '基本データ'],
+    ['234-567-8901', 'API上の名前', '基本データ']
+  ]);
+  assert.deepStrictEqual(lookups, ['234-567-8901']);
+});
+
+test('MCCの親スクリプトでは、振り分けたアカウント数を実行結果として返す', () => {
+  const fixture = { spreadsheets: {} };
+  fixture.spreadsheets[URL] = {
+    'アカウント一覧': [
+      ['アカウントID', 'アカウント名', 'スプレッドシートURL', '取得するデータ'],
+      ['123-456-7890', '○○株式会社', 'https://docs.google.com/spreadsheets/d/test-a', '基本データ'],
+      ['234-567-8901', '', 'https://docs.google.com/spreadsheets/d/test-b', 'すべて'],
+      ['345-678-9012', '', 'https://docs.google.com/spreadsheets/d/test-c', '性別データ']
+    ]
+  };
+  const harness = loadScripts(SYNC_FILES, { fixture: fixture });
+  const dispatched = [];
+  harness.context.AdsManagerApp = {
+    accounts: () => ({
+      withIds: ids => ({ executeInParallel: () => dispatched.push(ids) })
+    })
+  };
+  const dataset = { columns: [{ key: 'segments.date', label: '日付', type: 'date' }], fetchRows: () => [] };
+
+  const result = harness.call('runSync', dataset, { spreadsheetUrl: URL, sheetName: '基本データ' });
+  assert.deepStrictEqual([result.status, result.message], ['成功', '2アカウントに振り分け']);
+  assert.deepStrictEqual(dispatched.map(ids => Array.from(ids)), [['123-456-7890', '234-567-8901']]);
+
+  delete fixture.spreadsheets[URL]['アカウント一覧'];
+  const missing = loadScripts(SYNC_FILES, { fixture: fixture });
+  missing.context.AdsManagerApp = harness.context.AdsManagerApp;
+  const failed = missing.call('runSync', dataset, { spreadsheetUrl: URL, sheetName: '基本データ' });
+  assert.strictEqual(failed.status, 'エラー');
+  assert.ok(failed.message.indexOf('アカウント一覧') !== -1, failed.message);
+});
@@ -0,0 +1,109 @@
+'use strict';
+/**
+ * 【Meta広告・日次レポート】Graph APIの応答（フィクスチャ・2ページ）から、シートに追記される行を確認する
+ */
+const test = require('node:test');
+const assert = require('node:assert');
+const { loadScripts, readFixture, assertGolden } = require('./ハーネス.js');
+
+const FILES = [
+  'Meta広告スクリプト/Config.go',
+  'Meta広告スクリプト/実行履歴.go',
+  'Meta広告スクリプト/定期実行用.go'
+];
+
+test('最終行の翌日から前日までを取得し、全ページの行を追記する', () => {
+  const harness = loadScripts(FILES, { fixture: 'Meta日次レポート.json' });
+  harness.call('runDailyUpdate');
+
+  assert.strictEqual(harness.requests.length, 2);
+  assert.ok(harness.requests[0].url.indexOf(encodeURIComponent(JSON.stringify({ since: '2025-07-13', until: '2025-07-14' }))) !== -1, harness.requests[0].url);
+  assertGolden('Meta日次レポート', { sheets: harness.sheetValues() });
+});
+
+test('設定に不備があるときは、APIを呼ばずにエラーにする', () => {
+  const harness = loadScripts(FILES, { fixture: 'Meta日次レポート.json' });
+  harness.context.PropertiesService.getScriptProperties().setProperty('AD_ACCOUNT_ID', '1234567890');
+
+  assert.throws(() => harness.call('runDailyUpdate'), /AD_ACCOUNT_ID/);
+  assert.strictEqual(harness.requests.length, 0);
+});
+
+test('今年の年次レポートを日次レポートと同じシートに取得した後も、日次更新は前日の翌日から続けて取得する', () => {
+  const fixture = readFixture('Meta日次レポート.json');
+  fixture.properties.YEARLY_REPORT_TARGET_YEAR = '2025';
+  fixture.properties.YEARLY_REPORT_SHEET_NAME = 'Meta広告レポート';
+  const yearly = loadScripts(FILES.concat(['Meta広告スクリプト/年単位データ取得.go']), { fixture });
+  yearly.call('fetchYearlyReport');
+
+  assert.ok(yearly.requests[0].url.indexOf(encodeURIComponent(JSON.stringify({ since: '2025-01-01', until: '2025-07-14' }))) !== -1, yearly.requests[0].url);
+  const history = yearly.sheetValues()['実行履歴'];
+  assert.deepStrictEqual(Array.from(history[1]).slice(1, 4), ['Meta広告レポート', '2025-01-01', '2025-07-14']);
+
+  // 2日後の日次更新は、年次レポートの終了日の翌日（7月15日）から取得する
+  const daily = loadScripts(FILES, {
+    fixture: Object.assign({}, fixture, { spreadsheets: { active: yearly.sheetValues() } }),
+    now: '2025-07-17T09:00:00+09:00'
+  });
+  daily.call('runDailyUpdate');
+  assert.ok(daily.requests[0].url.indexOf(encodeURIComponent(JSON.stringify({ since: '2025-07-15', until: '2025-07-16' }))) !== -1, daily.requests[0].url);
+});
+
+test('日次更新の続きから隙間なくつながる年単位の取得だけを取得済みとして扱う', () => {
+  const fixture = readFixture('Meta日次レポート.json');
+  fixture.spreadsheets.active['実行履歴'] = [
+    ['記録日時', 'シート名', '開始日', '終了日', '件数', 'ステータス', 'メッセージ'],
+    ['', 'Meta広告レポート', '2025-07-01', '2025-07-05', 10, '成功', ''],
+    ['', 'Meta広告レポート', '2025-07-10', '2025-07-14', 5, '成功', '[年単位 2025]'],
+    ['', '別のシート', '2025-01-01', '2025-03-31', 4, '成功', '[年単位 2025]'],
+    ['', '別のシート', '2025-04-01', '2025-04-30', 2, '成功', '']
+  ];
+  const harness = loadScripts(FILES, { fixture });
+  const ss = harness.context.SpreadsheetApp.getActiveSpreadsheet();
+
+  // 7月6日〜9日が取得されていないため、7月10日からの年単位の取得は含めない
+  assert.strictEqual(harness.call('getSyncWatermark', ss, 'Meta広告レポート'), '2025-07-05');
+  assert.strictEqual(harness.call('getSyncWatermark', ss, '別のシート'), '2025-04-30');
+  harness.call('runDailyUpdate');
+  assert.ok(harness.requests[0].url.indexOf(encodeURIComponent(JSON.stringify({ since: '2025-07-06', until: '2025-07-14' }))) !== -1, harness.requests[0].url);
+});
+
+test('年次レポートが日次レポートのシートを消して書き直したときは、年次の期間の翌日から日次更新で取り直す', () => {
+  const fixture = readFixture('Meta日次レポート.json');
+  fixture.properties.YEARLY_REPORT_TARGET_YEAR = '2024';
+  fixture.properties.YEARLY_REPORT_SHEET_NAME = 'Meta広告レポート';
+  fixture.spreadsheets.active['実行履歴'] = [
+    ['記録日時', 'シート名', '開始日', '終了日', '件数', 'ステータス', 'メッセージ'],
+    ['', 'Meta広告レポート', '2025-01-01', '2025-07-14', 100, '成功', '']
+  ];
+  const yearly = loadScripts(FILES.concat(['Meta広告スクリプト/年単位データ取得.go']), { fixture });
+  yearly.call('fetchYearlyReport');
+  assert.deepStrictEqual(Array.from(yearly.sheetValues()['実行履歴'][2]).slice(1, 4), ['Meta広告レポート', '2024-01-01', '2024-12-31']);
+  assert.strictEqual(Array.from(yearly.sheetValues()['実行履歴'][2])[6], '[年単位 2024・シート全体を置き換え]');
+
+  // 消された2025年の行を、日次更新で取り直す
+  const daily = loadScripts(FILES, { fixture: Object.assign({}, fixture, { spreadsheets: { active: yearly.sheetValues() } }) });
+  daily.call('runDailyUpdate');
+  assert.ok(daily.requests[0].url.indexOf(encodeURIComponent(JSON.stringify({ since: '2025-01-01', until: '2025-07-14' }))) !== -1, daily.requests[0].url);
+});
+
+test('年次レポートの既定のシート名は、日次レポートと別のシートにする', () => {
+  const fixture = readFixture('Meta日次レポート.json');
+  fixture.properties.YEARLY_REPORT_TARGET_YEAR = '2025';
+  const yearly = loadScripts(FILES.concat(['Meta広告スクリプト/年単位データ取得.go']), { fixture });
+  yearly.call('fetchYearlyReport');
+
+  const sheets = yearly.sheetValues();
+  assert.ok(sheets['Meta広告レポート（年次）']);
+  assert.strictEqual(sheets['Meta広告レポート'].length, 2);
+});
+
+test('実行履歴がなく、最終行のA列が日付でないときは、エラーにせず前日分だけを取得する', () => {
+  const fixture = readFixture('Meta日次レポート.json');
+  fixture.spreadsheets.active['Meta広告レポート'][1][0] = '合計';
+  const harness = loadScripts(FILES, { fixture });
+  harness.call('runDailyUpdate');
+
+  assert.ok(harness.requests[0].url.indexOf(encodeURIComponent(JSON.stringify({ since: '2025-07-14', until: '2025-07-14' }))) !== -1, harness.requests[0].url);
+  assert.ok(harness.logs.some(line => line.indexOf('A列が日付ではない') !== -1), harness.logs.join('\n'));
+});
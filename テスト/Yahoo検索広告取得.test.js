@@ -0,0 +1,48 @@
+'use strict';
+/**
+ * 【Yahoo!検索広告取得】定期実行用のスクリプトが実行履歴から決める、最終データ取得日を確認する
+ */
+const test = require('node:test');
+const assert = require('node:assert');
+const { loadScripts } = require('./ハーネス.js');
+
+const FILES = [
+  'Yahoo広告スクリプト/検索広告取得_定期実行用.go',
+  'Google広告スクリプト/共通/URL正規化.go'
+];
+const URL = 'https://docs.google.com/spreadsheets/d/test-yahoo';
+
+test('定期実行の続きから隙間なくつながる年単位の取得だけを取得済みとして扱う', () => {
+  const fixture = { spreadsheets: {} };
+  fixture.spreadsheets[URL] = {
+    '実行履歴': [
+      ['記録日時', 'シート名', '開始日', '終了日', '件数', 'ステータス', 'メッセージ'],
+      ['', '検索広告（YSA）', '', '2025-06-30', 0, '成功', '旧形式の実行履歴から移行'],
+      ['', '検索広告（YSA）', '2025-07-01', '2025-07-05', 10, '成功', ''],
+      ['', '検索広告（YSA）', '2025-07-06', '2025-07-08', 3, '成功', '[年単位 2025]'],
+      ['', '検索広告（YSA）', '2025-07-11', '2025-07-14', 5, '成功', '[年単位 2025]']
+    ]
+  };
+  const harness = loadScripts(FILES, { fixture: fixture, constants: { SPREADSHEET_URL: URL } });
+  const spreadsheet = harness.context.SpreadsheetApp.openByUrl(URL);
+
+  // 7月9日・10日が取得されていないため、7月11日からの年単位の取得は含めない
+  const lastDate = harness.call('getLastExecutionDate', spreadsheet, '検索広告（YSA）');
+  assert.strictEqual(harness.call('formatDate', lastDate, 'YYYY-MM-DD'), '2025-07-08');
+});
+
+test('年単位の取得がシートを消して書き直した後は、それより前の記録を使わない', () => {
+  const fixture = { spreadsheets: {} };
+  fixture.spreadsheets[URL] = {
+    '実行履歴': [
+      ['記録日時', 'シート名', '開始日', '終了日', '件数', 'ステータス', 'メッセージ'],
+      ['', '検索広告（YSA）', '2025-01-01', '2025-07-14', 100, '成功', ''],
+      ['', '検索広告（YSA）', '2024-01-01', '2024-12-31', 300, '成功', '[年単位 2024・シート全体を置き換え]']
+    ]
+  };
+  const harness = loadScripts(FILES, { fixture: fixture, constants: { SPREADSHEET_URL: URL } });
+  const spreadsheet = harness.context.SpreadsheetApp.openByUrl(URL);
+
+  const lastDate = harness.call('getLastExecutionDate', spreadsheet, '検索広告（YSA）');
+  assert.strictEqual(harness.call('formatDate', lastDate, 'YYYY-MM-DD'), '2024-12-31');
+});
@@ -0,0 +1,86 @@
+'use strict';
+/**
+ * 【インプレッションシェアの推移】競合指標データの週単位の取得と、レポートで使う週別のインプレッションシェア・競合の集計を確認する
+ */
+const test = require('node:test');
+const assert = require('node:assert');
+const { loadScripts } = require('./ハーネス.js');
+
+const FETCHER_FILES = [
+  'Google広告スクリプト/競合指標データ取得.go',
+  'Google広告スクリプト/共通/同期処理.go',
+  'Google広告スクリプト/共通/スキーマ.go',
+  'Google広告スクリプト/共通/実行履歴.go',
+  'Google広告スクリプト/共通/設定.go',
+  'Google広告スクリプト/共通/MCC実行.go',
+  'Google広告スクリプト/共通/列挙値.go',
+  'Google広告スクリプト/共通/セグメント.go'
+];
+const REPORT_FILES = [
+  'Google広告用レポート/Config.go',
+  'Google広告用レポート/HTMLレポート生成（検索広告）.go'
+];
+const URL = 'https://docs.google.com/spreadsheets/d/test-competition';
+const HEADERS = ['週', 'キャンペーンID', 'キャンペーン名', '表示URLドメイン', '表示回数', 'クリック数', '費用', '検索IS', '検索TOP IS', '検索Abs.TOP IS',
+  '検索IS損失率(予算)', '検索IS損失率(ランク)', '重複率', '上位掲載率', '優位表示シェア', 'ページ上部表示率', 'ページ最上部表示率'];
+
+test('週の途中から取得するときは月曜日から取り直し、オークション分析を取得できなければ自社の行だけを記録する', () => {
+  const fixture = {
+    reports: [{ match: ['FROM campaign', 'metrics.search_impression_share'], rows: [
+      { 'segments.week': '2025-06-30', 'campaign.id': '1', 'campaign.name': '検索_一般', 'metrics.impressions': '800', 'metrics.clicks': '40',
+        'metrics.cost_micros': '20000000000', 'metrics.search_impression_share': '0.4', 'metrics.search_top_impression_share': '0.3',
+        'metrics.search_absolute_top_impression_share': '0.1', 'metrics.search_budget_lost_impression_share': '0.2', 'metrics.search_rank_lost_impression_share': '0.4' },
+      { 'segments.week': '2025-07-07', 'campaign.id': '1', 'campaign.name': '検索_一般', 'metrics.impressions': '50', 'metrics.clicks': '2',
+        'metrics.cost_micros': '1000000000', 'metrics.search_impression_share': '', 'metrics.search_top_impression_share': '',
+        'metrics.search_absolute_top_impression_share': '', 'metrics.search_budget_lost_impression_share': '', 'metrics.search_rank_lost_impression_share': '' }
+    ] }],
+    spreadsheets: {}
+  };
+  fixture.spreadsheets[URL] = {};
+  const harness = loadScripts(FETCHER_FILES, { fixture, constants: { SPREADSHEET_URL: URL, MODE: 'range', START_DATE: '2025-07-02', END_DATE: '2025-07-13' } });
+  harness.call('main');
+
+  assert.ok(harness.queries[0].indexOf("BETWEEN '2025-06-30' AND '2025-07-13'") !== -1, harness.queries[0]);
+  assert.ok(harness.logs.join('\n').indexOf('オークション分析の指標を取得できない') !== -1);
+  const rows = harness.sheetValues(URL)['競合指標データ'].slice(1);
+  assert.deepStrictEqual(rows.map(row => [row[0], row[3], row[4], row[6], row[7], row[11], row[12]]), [
+    ['2025-06-30', '（自社）', 800, 20000, 0.4, 0.4, ''],
+    ['2025-07-07', '（自社）', 50, 1000, '', '', '']
+  ]);
+});
+
+test('自社のISは表示可能だった回数で、競合の指標は自社の表示回数で重み付けし、重複率の高い競合から表示する', () => {
+  const harness = loadScripts(REPORT_FILES, {});
+  const own = (week, campaignId, imp, share, budgetLost, rankLost) =>
+    [harness.date(week), campaignId, '検索', '（自社）', imp, 0, 0, share, share / 2, '', budgetLost, rankLost, '', '', '', '', ''];
+  const rival = (week, campaignId, domain, share, overlap, positionAbove) =>
+    [harness.date(week), campaignId, '検索', domain, '', '', '', share, '', '', '', '', overlap, positionAbove, 0.1, 0.5, 0.2];
+  const rows = [
+    own('2025-06-23', '1', 100, 0.5, 0.1, 0.4),   // 表示可能だった回数 200
+    own('2025-06-23', '2', 300, 0.25, 0.5, 0.25), // 表示可能だった回数 1200
+    rival('2025-06-23', '1', 'a.example.com', 0.6, 0.8, 0.4),
+    rival('2025-06-23', '2', 'a.example.com', 0.2, 0.4, 0.2),
+    rival('2025-06-23', '2', 'b.example.com', 0.3, 0.9, 0.5),
+    rival('2025-06-23', '2', 'c.example.com', 0.1, 0.1, 0.1),
+    own('2025-06-30', '1', 120, 0.6, 0, 0.4),
+    own('2025-06-09', '1', 999, 0.9, 0, 0.1)      // 対象の週より前
+  ];
+  const result = harness.call('aggregateCompetitionTrend', rows, HEADERS, harness.date('2025-06-30'), 2, 2);
+
+  assert.deepStrictEqual(Array.from(result.weeks), ['2025-06-23', '2025-06-30']);
+  const week = result.own[0];
+  assert.strictEqual(week.imp, 400);
+  assert.strictEqual(Math.round(week.is * 10000) / 10000, Math.round(400 / 1400 * 10000) / 10000);
+  assert.strictEqual(Math.round(week.budgetLost * 10000) / 10000, Math.round((0.1 * 200 + 0.5 * 1200) / 1400 * 10000) / 10000);
+  assert.strictEqual(Math.round(result.own[1].is * 100) / 100, 0.6);
+
+  assert.deepStrictEqual(Array.from(result.competitors, item => item.domain), ['b.example.com', 'a.example.com']);
+  const a = result.competitors[1].weeks[0];
+  assert.strictEqual(Math.round(a.is * 100) / 100, 0.3);          // (0.6×100 + 0.2×300) / 400
+  assert.strictEqual(Math.round(a.positionAbove * 100) / 100, 0.25);
+  assert.strictEqual(result.competitors[1].weeks[1], null);
+
+  const html = harness.call('buildCompetitionHtml', result);
+  assert.ok(html.html.indexOf('b.example.com') !== -1);
+  assert.ok(html.script.indexOf('competitionChart') !== -1);
+});
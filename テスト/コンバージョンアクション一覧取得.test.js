@@ -0,0 +1,85 @@
+'use strict';
+/**
+ * 【コンバージョンアクション一覧取得】シートに記入した「役割」が残ることと、レポートが役割でCVを数えることを確認する
+ */
+const test = require('node:test');
+const assert = require('node:assert');
+const { loadScripts, readFixture } = require('./ハーネス.js');
+
+const FILES = [
+  'Google広告スクリプト/コンバージョン名一覧取得.go',
+  'Google広告スクリプト/共通/同期処理.go',
+  'Google広告スクリプト/共通/スキーマ.go',
+  'Google広告スクリプト/共通/実行履歴.go',
+  'Google広告スクリプト/共通/設定.go',
+  'Google広告スクリプト/共通/MCC実行.go',
+  'Google広告スクリプト/共通/列挙値.go'
+];
+const REPORT_FILES = [
+  'Google広告用レポート/Config.go',
+  'Google広告用レポート/Code.go'
+];
+const URL = 'https://docs.google.com/spreadsheets/d/test-conversion-actions';
+const REPORT_URL = 'https://docs.google.com/spreadsheets/d/test-report';
+const HEADERS = ['コンバージョンアクションID', 'コンバージョンアクション名', 'ステータス', '種類', 'カテゴリ', '目標の主要アクション',
+  'カウント方法', 'アトリビューション モデル', '既定の値', '常に既定の値を使用', '役割'];
+
+function action(id, name, category, primaryForGoal) {
+  return {
+    'conversion_action.id': id, 'conversion_action.name': name, 'conversion_action.status': 'ENABLED',
+    'conversion_action.type': 'WEBPAGE', 'conversion_action.category': category,
+    'conversion_action.primary_for_goal': primaryForGoal, 'conversion_action.counting_type': 'ONE_PER_CLICK',
+    'conversion_action.attribution_model_settings.attribution_model': 'GOOGLE_SEARCH_ATTRIBUTION_DATA_DRIVEN',
+    'conversion_action.value_settings.default_value': '1', 'conversion_action.value_settings.always_use_default_value': 'false'
+  };
+}
+
+test('記入済みの役割は残し、新しいアクションには名前と目標の主要アクションから役割を付ける', () => {
+  const fixture = {
+    reports: [{ match: 'FROM conversion_action', rows: [
+      action('1', '購入', 'PURCHASE', 'true'),
+      action('2', '中間_カート追加', 'ADD_TO_CART', 'true'),
+      action('3', 'ページ閲覧', 'PAGE_VIEW', 'false'),
+      action('4', '資料請求', 'SUBMIT_LEAD_FORM', 'true')
+    ] }],
+    spreadsheets: {}
+  };
+  fixture.spreadsheets[URL] = { 'コンバージョンアクション一覧': [HEADERS,
+    ['1', '購入', '有効', 'WEBPAGE', '購入', 'はい', '1回', 'ラストクリック', 1, 'いいえ', 'マイクロ'],
+    ['4', '資料請求', '有効', 'WEBPAGE', 'その他', 'はい', '1回', 'データドリブン', 1, 'いいえ', '主要です']
+  ] };
+  const harness = loadScripts(FILES, { fixture, constants: { SPREADSHEET_URL: URL } });
+  harness.call('main');
+
+  const rows = harness.sheetValues(URL)['コンバージョンアクション一覧'].slice(1);
+  assert.deepStrictEqual(rows.map(row => [row[0], row[4], row[5], row[7], row[9], row[10]]).sort(), [
+    ['1', '購入', 'はい', 'データドリブン', 'いいえ', 'マイクロ'],
+    ['2', 'カートに追加', 'はい', 'データドリブン', 'いいえ', '中間'],
+    ['3', 'ページビュー', 'いいえ', 'データドリブン', 'いいえ', 'マイクロ'],
+    ['4', '見込み顧客フォームの送信', 'はい', 'データドリブン', 'いいえ', '主要']
+  ]);
+  const logs = harness.logs.join('\n');
+  assert.ok(logs.indexOf('「主要です」は記入できない値') !== -1, logs);
+  assert.ok(logs.indexOf('新しいアクションを3件追加しました') !== -1, logs);
+});
+
+test('一覧がある場合は役割でCVを数え、主要以外のCVは役割別に集計する', () => {
+  const withoutCatalog = loadScripts(REPORT_FILES, { fixture: 'レポート集計.json' }).call('getReportData', false);
+  assert.strictEqual(withoutCatalog.lastMonthData.totalConversions, 4.5);
+  assert.deepStrictEqual(JSON.parse(JSON.stringify(withoutCatalog.lastMonthData.conversionsByRole)), { primary: 4.5, intermediate: 9, micro: 0 });
+
+  const fixture = readFixture('レポート集計.json');
+  fixture.spreadsheets[REPORT_URL]['コンバージョンアクション一覧'] = [HEADERS,
+    ['1', '購入', '有効', 'WEBPAGE', '購入', 'はい', '1回', 'データドリブン', 1, 'いいえ', '主要'],
+    ['2', '中間_カート追加', '有効', 'WEBPAGE', 'カートに追加', 'はい', '1回', 'データドリブン', 1, 'いいえ', '主要'],
+    ['3', '資料請求', '削除済み', 'WEBPAGE', 'その他', 'はい', '1回', 'データドリブン', 1, 'いいえ', '主要'],
+    ['4', '資料請求', '有効', 'WEBPAGE', 'その他', 'いいえ', '1回', 'データドリブン', 1, 'いいえ', 'マイクロ']
+  ];
+  const reportData = loadScripts(REPORT_FILES, { fixture }).call('getReportData', false);
+
+  const lastMonth = reportData.lastMonthData;
+  assert.strictEqual(lastMonth.totalConversions, 12);
+  assert.deepStrictEqual(JSON.parse(JSON.stringify(lastMonth.conversionsByRole)), { primary: 12, intermediate: 0, micro: 1.5 });
+  assert.strictEqual(lastMonth.deviceData.DESKTOP.conversions, 0);
+  assert.strictEqual(reportData.pmaxData.lastMonth.assetGroups[0].cv, 7);
+});
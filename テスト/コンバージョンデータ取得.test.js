@@ -0,0 +1,137 @@
+'use strict';
+/**
+ * 【コンバージョン内訳】CV発生日の指標を記録する設定と、レポートでクリック日・CV発生日のCVを並べて集計することを確認する
+ */
+const test = require('node:test');
+const assert = require('node:assert');
+const { loadScripts, readFixture } = require('./ハーネス.js');
+
+const FILES = [
+  'Google広告スクリプト/コンバージョンデータ取得.go',
+  'Google広告スクリプト/共通/同期処理.go',
+  'Google広告スクリプト/共通/スキーマ.go',
+  'Google広告スクリプト/共通/実行履歴.go',
+  'Google広告スクリプト/共通/設定.go',
+  'Google広告スクリプト/共通/CV発生日.go',
+  'Google広告スクリプト/共通/MCC実行.go',
+  'Google広告スクリプト/共通/列挙値.go'
+];
+const REPORT_FILES = [
+  'Google広告用レポート/Config.go',
+  'Google広告用レポート/Code.go'
+];
+const URL = 'https://docs.google.com/spreadsheets/d/test-conversions';
+const REPORT_URL = 'https://docs.google.com/spreadsheets/d/test-report';
+const CONSTANTS = { SPREADSHEET_URL: URL, MODE: 'range', START_DATE: '2025-07-10', END_DATE: '2025-07-10' };
+
+function adGroupRow(action, conversions, conversionsByConversionDate) {
+  return {
+    'segments.date': '2025-07-10', 'segments.device': 'MOBILE', 'campaign.name': '検索_一般', 'campaign.id': '1',
+    'ad_group.name': '一般', 'ad_group.id': '10', 'ad_group.status': 'ENABLED', 'ad_group.type': 'SEARCH_STANDARD',
+    'segments.conversion_action_name': action, 'metrics.conversions': conversions, 'metrics.conversions_value': '0',
+    'metrics.all_conversions_value': '0', 'metrics.conversions_by_conversion_date': conversionsByConversionDate,
+    'metrics.conversions_value_by_conversion_date': '0', 'campaign.advertising_channel_type': 'SEARCH'
+  };
+}
+
+function fetchFixture() {
+  const fixture = {
+    reports: [{ match: 'FROM ad_group', rows: [
+      adGroupRow('購入', '2', '1'),
+      adGroupRow('資料請求', '0', '3'),
+      adGroupRow('電話', '0', '0')
+    ] }, { match: 'FROM asset_group', rows: [] }],
+    spreadsheets: {}
+  };
+  fixture.spreadsheets[URL] = {};
+  return fixture;
+}
+
+test('既定では CV発生日の列を追加せず、クリックした日のコンバージョンがある行だけを記録する', () => {
+  const harness = loadScripts(FILES, { fixture: fetchFixture(), constants: CONSTANTS });
+  harness.call('main');
+
+  const values = harness.sheetValues(URL)['CV内訳データ'];
+  assert.strictEqual(values[0].indexOf('コンバージョン数（CV発生日）'), -1);
+  assert.strictEqual(values.length, 2);
+  const query = harness.queries.find(text => text.indexOf('FROM ad_group') !== -1);
+  assert.ok(query.indexOf('metrics.conversions > 0') !== -1, query);
+  assert.ok(query.indexOf('conversions_by_conversion_date') === -1, query);
+});
+
+test('INCLUDE_CONVERSION_DATE が true のときは、CV発生日にだけコンバージョンがある行も記録する', () => {
+  const harness = loadScripts(FILES, { fixture: fetchFixture(), constants: Object.assign({ INCLUDE_CONVERSION_DATE: true }, CONSTANTS) });
+  harness.call('main');
+
+  const values = harness.sheetValues(URL)['CV内訳データ'];
+  const headers = Array.from(values[0]);
+  assert.deepStrictEqual(headers.slice(-3), ['コンバージョン数（CV発生日）', 'コンバージョン価値（CV発生日）', '広告チャネルタイプ']);
+  const actionIndex = headers.indexOf('コンバージョンアクション名');
+  const cvIndex = headers.indexOf('コンバージョン数');
+  const cvByDateIndex = headers.indexOf('コンバージョン数（CV発生日）');
+  assert.deepStrictEqual(Array.from(values.slice(1), row => [row[actionIndex], row[cvIndex], row[cvByDateIndex]]).sort(), [
+    ['資料請求', '0', '3'],
+    ['購入', '2', '1']
+  ]);
+  const query = harness.queries.find(text => text.indexOf('FROM ad_group') !== -1);
+  assert.ok(query.indexOf('metrics.conversions > 0') === -1, query);
+});
+
+test('CV発生日の列があるシートでは、レポートがクリック日・CV発生日のCVを並べて集計する', () => {
+  const withoutColumns = loadScripts(REPORT_FILES, { fixture: 'レポート集計.json' }).call('getReportData', false);
+  assert.strictEqual(withoutColumns.lastMonthData.hasConversionDate, false);
+
+  const fixture = readFixture('レポート集計.json');
+  const cvSheet = fixture.spreadsheets[REPORT_URL]['コンバージョンデータ'];
+  cvSheet[0] = cvSheet[0].concat(['コンバージョン数（CV発生日）', 'コンバージョン価値（CV発生日）']);
+  // 5月20日のクリックから6月に発生したCV（購入1件）を、6月の行に計上する
+  cvSheet.slice(1).forEach(row => row.push(row[5], row[8]));
+  cvSheet[1][9] = 1;
+  cvSheet[2][9] = 4;
+  cvSheet[2][10] = 46000;
+  const reportData = loadScripts(REPORT_FILES, { fixture }).call('getReportData', false);
+
+  const lastMonth = reportData.lastMonthData;
+  assert.strictEqual(lastMonth.hasConversionDate, true);
+  assert.strictEqual(lastMonth.totalConversions, 4.5);
+  assert.strictEqual(lastMonth.conversionsByConversionDate, 5.5);
+  assert.strictEqual(lastMonth.conversionValueByConversionDate, 46000);
+  assert.strictEqual(reportData.prevMonthData.conversionsByConversionDate, 1);
+  assert.strictEqual(reportData.monthlyData['2025-06'].cvByConversionDate, 5.5);
+});
+
+test('「設定」シートの INCLUDE_CONVERSION_DATE で、ほかのCVアクション別データにもCV発生日の列を追加する', () => {
+  const ageFiles = ['Google広告スクリプト/年齢別CVアクション取得.go'].concat(FILES.slice(1), ['Google広告スクリプト/共通/セグメント.go']);
+  const fixture = {
+    reports: [{ match: 'FROM age_range_view', rows: [
+      { 'segments.date': '2025-07-10', 'campaign.name': '検索_一般', 'campaign.advertising_channel_type': 'SEARCH', 'ad_group.name': '一般',
+        'ad_group_criterion.age_range.type': 'AGE_RANGE_25_34', 'segments.conversion_action_name': '資料請求', 'metrics.conversions': '0',
+        'metrics.conversions_value': '0', 'metrics.all_conversions_value': '0', 'metrics.conversions_by_conversion_date': '2',
+        'metrics.conversions_value_by_conversion_date': '0' }
+    ] }],
+    spreadsheets: {}
+  };
+  fixture.spreadsheets[URL] = {
+    '設定': [['データ', '項目', '値', 'メモ'], ['', 'INCLUDE_CONVERSION_DATE', true, 'チェックボックス']]
+  };
+  const harness = loadScripts(ageFiles, { fixture, constants: CONSTANTS });
+  harness.call('main');
+
+  const headers = Array.from(harness.sheetValues(URL)['年齢別CVアクションデータ'][0]);
+  assert.deepStrictEqual(headers.slice(-3), ['すべてのコンバージョン価値', 'コンバージョン数（CV発生日）', 'コンバージョン価値（CV発生日）']);
+  assert.strictEqual(harness.sheetValues(URL)['年齢別CVアクションデータ'].length, 2);
+  assert.ok(harness.queries[0].indexOf('metrics.conversions > 0') === -1, harness.queries[0]);
+
+  // キーワード別はGAQL（keyword_view）で取得し、既定ではクリックした日のコンバージョンがある行に絞る
+  const keywordFiles = ['Google広告スクリプト/キーワード別CVアクション取得.go'].concat(FILES.slice(1));
+  const keyword = loadScripts(keywordFiles, { fixture: { reports: [{ match: 'FROM keyword_view', rows: [] }], spreadsheets: {} }, constants: CONSTANTS });
+  keyword.call('main');
+  assert.ok(keyword.queries[0].indexOf('metrics.conversions > 0') !== -1, keyword.queries[0]);
+
+  // true / false 以外の値は、取得せずに終了する
+  fixture.spreadsheets[URL]['設定'][1][2] = 'はい';
+  const invalid = loadScripts(ageFiles, { fixture, constants: CONSTANTS });
+  invalid.call('main');
+  assert.strictEqual(invalid.queries.length, 0);
+  assert.ok(invalid.logs.some(line => line.indexOf('INCLUDE_CONVERSION_DATE') !== -1 && line.indexOf('true / false') !== -1), invalid.logs.join('\n'));
+});
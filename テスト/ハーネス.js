@@ -0,0 +1,810 @@
+'use strict';
+/**
+ * 【オフラインテスト用ハーネス】
+ * リポジトリ内の .go ファイル（中身は Google広告スクリプト / Apps Script の JavaScript）を Node の vm に読み込み、
+ * AdsApp・SpreadsheetApp・Utilities・CacheService・PropertiesService・UrlFetchApp をメモリ上の偽物（フェイク）に差し替えて実行します。
+ * APIの応答は「フィクスチャ」（記録済みのJSON）から返し、各シートに書き込まれた行を「ゴールデンファイル」と比較します。
+ * 実際のアカウント・スプレッドシートには一切アクセスしません。
+ *
+ * 使い方は テスト/README.md を参照してください。
+ */
+
+// スクリプト内の new Date(年, 月, 日) が日本時間で解釈されるように、テスト全体を日本時間で実行する
+process.env.TZ = 'Asia/Tokyo';
+
+const assert = require('node:assert');
+const fs = require('node:fs');
+const path = require('node:path');
+const vm = require('node:vm');
+
+// リポジトリのルート（読み込むファイルはここからの相対パスで指定する）
+const ROOT = path.resolve(__dirname, '..');
+const FIXTURE_DIR = path.join(__dirname, 'フィクスチャ');
+const GOLDEN_DIR = path.join(__dirname, 'ゴールデン');
+
+// 既定の「現在時刻」（スクリプトの new Date() はこの時刻を返す）
+const DEFAULT_NOW = '2025-07-15T09:00:00+09:00';
+const DEFAULT_TIMEZONE = 'Asia/Tokyo';
+
+// Apps Script のタイムゾーン表記のうち、Intl がそのままでは解釈できないもの
+const TIMEZONE_ALIASES = { JST: 'Asia/Tokyo', 'GMT+9': 'Etc/GMT-9', 'GMT+09:00': 'Etc/GMT-9' };
+
+/**
+ * スクリプトを読み込んだ実行環境を作る
+ * @param {Array<string>} files - 読み込む .go ファイル（ルートからの相対パス。Apps Script と同じく指定順に読み込む）
+ * @param {Object} [options]
+ *   fixture    … フィクスチャ（オブジェクト、または テスト/フィクスチャ/ 内のファイル名）
+ *   constants  … スクリプト冒頭の定数を書き換える値（例: { SPREADSHEET_URL: 'https://…' }）
+ *   now        … 現在時刻（ISO形式の文字列）
+ * @returns {Object} 実行環境（call・sheetValues などを持つ）
+ */
+function loadScripts(files, options) {
+  const settings = Object.assign({ fixture: {}, constants: {}, now: DEFAULT_NOW }, options);
+  const fixture = typeof settings.fixture === 'string' ? readFixture(settings.fixture) : settings.fixture;
+  const harness = {
+    logs: [],
+    queries: [],
+    requests: [],
+    mails: [],
+    mutations: [],
+    spreadsheets: new Map()
+  };
+
+  const context = vm.createContext({});
+  installFixedClock(context, Date.parse(settings.now));
+  harness.context = context;
+  harness.date = value => toContextDate(context, value);
+
+  const log = level => function () {
+    const message = Array.prototype.map.call(arguments, value => typeof value === 'string' ? value : String(value)).join(' ');
+    harness.logs.push(level ? `[${level}] ${message}` : message);
+    if (process.env.HARNESS_VERBOSE) {
+      console.log(message);
+    }
+  };
+  Object.assign(context, {
+    console: { log: log(''), info: log(''), warn: log('warn'), error: log('error') },
+    Logger: { log: log('') },
+    Utilities: createUtilities(),
+    Session: { getScriptTimeZone: () => DEFAULT_TIMEZONE },
+    SpreadsheetApp: createSpreadsheetApp(harness, fixture.spreadsheets || {}),
+    CacheService: createCacheService(fixture.cache || {}),
+    PropertiesService: createPropertiesService(fixture.properties || {}),
+    UrlFetchApp: createUrlFetchApp(harness, fixture.fetches || []),
+    AdsApp: createAdsApp(harness, fixture),
+    MailApp: { sendEmail: function () { harness.mails.push(Array.from(arguments)); } }
+  });
+
+  const overridden = new Set();
+  files.forEach(file => {
+    const source = overrideConstants(fs.readFileSync(path.join(ROOT, file), 'utf8'), settings.constants, overridden);
+    vm.runInContext(source, context, { filename: file });
+  });
+  const missing = Object.keys(settings.constants).filter(name => !overridden.has(name));
+  if (missing.length > 0) {
+    throw new Error(`読み込んだファイルに定数 ${missing.join(', ')} が見つかりません。`);
+  }
+
+  /**
+   * スクリプトのグローバル関数を呼び出す（例: harness.call('main')）
+   */
+  harness.call = function (name) {
+    const fn = vm.runInContext(name, context);
+    return fn.apply(null, Array.prototype.slice.call(arguments, 1));
+  };
+
+  /**
+   * スプレッドシートの全シートの値を、ゴールデンファイルと比べられる形で返す
+   * @param {string} [url] - 対象のスプレッドシート（省略時はアクティブなスプレッドシート）
+   */
+  harness.sheetValues = function (url) {
+    const spreadsheet = harness.spreadsheets.get(url || ACTIVE_SPREADSHEET);
+    const result = {};
+    if (spreadsheet) {
+      spreadsheet.getSheets().forEach(sheet => {
+        result[sheet.getName()] = sheet.rows.slice(0, sheet.getLastRow()).map(row => row.map(serializeValue));
+      });
+    }
+    return result;
+  };
+
+  return harness;
+}
+
+// --------------------------------------------------------------------------------
+// 時刻・日付
+// --------------------------------------------------------------------------------
+
+/**
+ * 実行環境の Date を、引数なしの new Date() と Date.now() が固定の時刻を返すものに差し替える
+ */
+function installFixedClock(context, now) {
+  if (isNaN(now)) {
+    throw new Error(`now の時刻を読み取れません: ${now}`);
+  }
+  vm.runInContext(`(function () {
+    const RealDate = Date;
+    class FixedDate extends RealDate {
+      constructor(...args) {
+        if (args.length === 0) {
+          super(${now});
+        } else {
+          super(...args);
+        }
+      }
+      static now() {
+        return ${now};
+      }
+    }
+    globalThis.Date = FixedDate;
+  })();`, context);
+}
+
+/**
+ * 実行環境の Date を作る（スクリプト内の instanceof Date が true になるように）
+ * 'yyyy-MM-dd' の文字列は、日本時間のその日の0時として扱います。
+ */
+function toContextDate(context, value) {
+  const ContextDate = vm.runInContext('Date', context);
+  if (typeof value === 'string' && /^\d{4}-\d{2}-\d{2}$/.test(value)) {
+    return new ContextDate(`${value}T00:00:00+09:00`);
+  }
+  return new ContextDate(value);
+}
+
+/**
+ * Utilities.formatDate と同じ書式（yyyy・MM・dd・HH・mm・ss・E など）で日付を文字列にする
+ */
+function formatDate(date, timezone, pattern) {
+  const zone = TIMEZONE_ALIASES[timezone] || timezone;
+  const parts = {};
+  new Intl.DateTimeFormat('en-US', {
+    timeZone: zone, hourCycle: 'h23', weekday: 'short',
+    year: 'numeric', month: '2-digit', day: '2-digit', hour: '2-digit', minute: '2-digit', second: '2-digit'
+  }).formatToParts(new Date(date.getTime())).forEach(part => {
+    parts[part.type] = part.value;
+  });
+  const tokens = {
+    yyyy: parts.year, yy: parts.year.slice(-2), MM: parts.month, M: String(Number(parts.month)),
+    dd: parts.day, d: String(Number(parts.day)), HH: parts.hour, H: String(Number(parts.hour)),
+    mm: parts.minute, ss: parts.second, E: parts.weekday
+  };
+  return pattern.replace(/'([^']*)'|yyyy|yy|MM|M|dd|d|HH|H|mm|ss|E/g, (token, quoted) => quoted !== undefined ? quoted : tokens[token]);
+}
+
+function createUtilities() {
+  return {
+    formatDate: formatDate,
+    sleep: () => {}
+  };
+}
+
+// --------------------------------------------------------------------------------
+// SpreadsheetApp
+// --------------------------------------------------------------------------------
+
+// getActiveSpreadsheet() が返すスプレッドシートのキー
+const ACTIVE_SPREADSHEET = 'active';
+
+/**
+ * SpreadsheetApp のフェイクを作る
+ * フィクスチャの spreadsheets は { URL（または 'active'）: { シート名: [[セルの値, …], …] } } の形式です。
+ * 日付のセルは { "$date": "2025-06-01" } と書くと、スプレッドシートと同じく Date として読み込まれます。
+ */
+function createSpreadsheetApp(harness, initial) {
+  const open = key => {
+    if (!harness.spreadsheets.has(key)) {
+      harness.spreadsheets.set(key, new FakeSpreadsheet(key));
+    }
+    return harness.spreadsheets.get(key);
+  };
+  Object.keys(initial).forEach(key => {
+    const spreadsheet = open(key);
+    Object.keys(initial[key]).forEach(sheetName => {
+      spreadsheet.insertSheet(sheetName).rows = initial[key][sheetName].map(row => row.map(value => deserializeValue(harness, value)));
+    });
+  });
+
+  return {
+    getActiveSpreadsheet: () => open(ACTIVE_SPREADSHEET),
+    openByUrl: url => {
+      if (!/^https:\/\/docs\.google\.com\/spreadsheets\/d\//.test(url)) {
+        throw new Error(`スプレッドシートのURLではありません: ${url}`);
+      }
+      // URL ごとのシートがフィクスチャにない場合は、アクティブなスプレッドシートと同じものとして扱う
+      return harness.spreadsheets.has(url) ? open(url) : open(ACTIVE_SPREADSHEET);
+    },
+    openById: id => open(id),
+    flush: () => {}
+  };
+}
+
+class FakeSpreadsheet {
+  constructor(key) {
+    this.key = key;
+    this.sheets = [];
+  }
+
+  getSheetByName(name) {
+    return this.sheets.find(sheet => sheet.name === name) || null;
+  }
+
+  getSheets() {
+    return this.sheets.slice();
+  }
+
+  insertSheet(name) {
+    if (this.getSheetByName(name)) {
+      throw new Error(`「${name}」という名前のシートはすでに存在します。`);
+    }
+    const sheet = chainable(new FakeSheet(this, name));
+    this.sheets.push(sheet);
+    return sheet;
+  }
+
+  deleteSheet(sheet) {
+    this.sheets = this.sheets.filter(item => item !== sheet);
+  }
+
+  getSpreadsheetTimeZone() {
+    return DEFAULT_TIMEZONE;
+  }
+
+  getUrl() {
+    return this.key.indexOf('https://') === 0 ? this.key : `https://docs.google.com/spreadsheets/d/${this.key}/edit`;
+  }
+
+  getName() {
+    return this.key;
+  }
+}
+
+class FakeSheet {
+  constructor(spreadsheet, name) {
+    this.spreadsheet = spreadsheet;
+    this.name = name;
+    this.rows = []; // 値だけを持つ（空のセルは ''）
+  }
+
+  getName() {
+    return this.name;
+  }
+
+  setName(name) {
+    this.name = name;
+    return this;
+  }
+
+  getParent() {
+    return this.spreadsheet;
+  }
+
+  getLastRow() {
+    for (let index = this.rows.length - 1; index >= 0; index--) {
+      if (this.rows[index].some(value => !isEmptyCell(value))) {
+        return index + 1;
+      }
+    }
+    return 0;
+  }
+
+  getLastColumn() {
+    let last = 0;
+    this.rows.forEach(row => {
+      for (let index = row.length - 1; index >= last; index--) {
+        if (!isEmptyCell(row[index])) {
+          last = index + 1;
+          break;
+        }
+      }
+    });
+    return last;
+  }
+
+  getMaxRows() {
+    return Math.max(1000, this.rows.length);
+  }
+
+  getMaxColumns() {
+    return Math.max(26, this.getLastColumn());
+  }
+
+  getRange(row, column, numRows, numColumns) {
+    if (typeof row === 'string') {
+      const a1 = parseA1Notation(row, this);
+      return chainable(new FakeRange(this, a1.row, a1.column, a1.numRows, a1.numColumns));
+    }
+    return chainable(new FakeRange(this, row, column, numRows || 1, numColumns || 1));
+  }
+
+  getDataRange() {
+    return this.getRange(1, 1, Math.max(this.getLastRow(), 1), Math.max(this.getLastColumn(), 1));
+  }
+
+  appendRow(values) {
+    this.rows.splice(this.getLastRow(), 0, values.slice());
+    return this;
+  }
+
+  clear() {
+    this.rows = [];
+    return this;
+  }
+
+  clearContents() {
+    return this.clear();
+  }
+
+  insertColumnBefore(column) {
+    this.rows.forEach(row => {
+      while (row.length < column - 1) {
+        row.push('');
+      }
+      row.splice(column - 1, 0, '');
+    });
+    return this;
+  }
+
+  insertColumnAfter(column) {
+    return this.insertColumnBefore(column + 1);
+  }
+
+  deleteRows(row, numRows) {
+    this.rows.splice(row - 1, numRows);
+    return this;
+  }
+
+  deleteRow(row) {
+    return this.deleteRows(row, 1);
+  }
+
+  cell(row, column) {
+    const values = this.rows[row - 1];
+    return values && values[column - 1] !== undefined ? values[column - 1] : '';
+  }
+
+  setCell(row, column, value) {
+    while (this.rows.length < row) {
+      this.rows.push([]);
+    }
+    const values = this.rows[row - 1];
+    while (values.length < column - 1) {
+      values.push('');
+    }
+    values[column - 1] = value;
+  }
+}
+
+class FakeRange {
+  constructor(sheet, row, column, numRows, numColumns) {
+    if (row < 1 || column < 1 || numRows < 1 || numColumns < 1) {
+      throw new Error(`範囲の指定が正しくありません（行 ${row} / 列 ${column} / 行数 ${numRows} / 列数 ${numColumns}）。`);
+    }
+    this.sheet = sheet;
+    this.row = row;
+    this.column = column;
+    this.numRows = numRows;
+    this.numColumns = numColumns;
+  }
+
+  getValues() {
+    const values = [];
+    for (let i = 0; i < this.numRows; i++) {
+      const row = [];
+      for (let j = 0; j < this.numColumns; j++) {
+        row.push(this.sheet.cell(this.row + i, this.column + j));
+      }
+      values.push(row);
+    }
+    return values;
+  }
+
+  getValue() {
+    return this.sheet.cell(this.row, this.column);
+  }
+
+  getDisplayValues() {
+    return this.getValues().map(row => row.map(value => String(value)));
+  }
+
+  // 実際のスプレッドシートと同じく、範囲と配列の大きさが違う場合はエラーにする
+  setValues(values) {
+    if (values.length !== this.numRows) {
+      throw new Error(`データの行数（${values.length}）が範囲の行数（${this.numRows}）と一致しません。`);
+    }
+    values.forEach((row, i) => {
+      if (row.length !== this.numColumns) {
+        throw new Error(`データの列数（${row.length}）が範囲の列数（${this.numColumns}）と一致しません。`);
+      }
+      row.forEach((value, j) => this.sheet.setCell(this.row + i, this.column + j, value));
+    });
+    return this;
+  }
+
+  setValue(value) {
+    for (let i = 0; i < this.numRows; i++) {
+      for (let j = 0; j < this.numColumns; j++) {
+        this.sheet.setCell(this.row + i, this.column + j, value);
+      }
+    }
+    return this;
+  }
+
+  clearContent() {
+    for (let i = 0; i < this.numRows; i++) {
+      const values = this.sheet.rows[this.row - 1 + i];
+      for (let j = 0; values && j < this.numColumns; j++) {
+        if (this.column - 1 + j < values.length) {
+          values[this.column - 1 + j] = '';
+        }
+      }
+    }
+    return this;
+  }
+
+  clear() {
+    return this.clearContent();
+  }
+
+  /**
+   * 範囲内の行を並べ替える（sort(列番号) / sort({column, ascending}) / sort([{column, ascending}, …]) に対応）
+   */
+  sort(spec) {
+    const specs = (Array.isArray(spec) ? spec : [spec]).map(item => typeof item === 'number' ? { column: item, ascending: true } : item);
+    const rows = this.getValues();
+    const sorted = rows.map((row, index) => ({ row, index })).sort((a, b) => {
+      for (const item of specs) {
+        const offset = item.column - this.column;
+        const order = compareCells(a.row[offset], b.row[offset]);
+        if (order !== 0) {
+          return item.ascending === false ? -order : order;
+        }
+      }
+      return a.index - b.index;
+    });
+    this.setValues(sorted.map(item => item.row));
+    return this;
+  }
+
+  getRow() {
+    return this.row;
+  }
+
+  getColumn() {
+    return this.column;
+  }
+
+  getNumRows() {
+    return this.numRows;
+  }
+
+  getNumColumns() {
+    return this.numColumns;
+  }
+
+  getSheet() {
+    return this.sheet;
+  }
+}
+
+/**
+ * 書式・列幅などの値に関係しない操作（set… / auto… など）を、何もせず自分自身を返すメソッドとして受け付ける
+ */
+function chainable(target) {
+  const proxy = new Proxy(target, {
+    get(object, property) {
+      if (property in object) {
+        const value = object[property];
+        return typeof value === 'function' ? value.bind(proxy) : value;
+      }
+      if (typeof property === 'string' && /^(set|auto|hide|show|protect|merge|freeze|activate)/.test(property)) {
+        return () => proxy;
+      }
+      return undefined;
+    }
+  });
+  return proxy;
+}
+
+/**
+ * 'A1'・'A1:C3'・'C:D' 形式の範囲を、行・列の番号に変換する
+ */
+function parseA1Notation(notation, sheet) {
+  const match = notation.match(/^([A-Z]+)(\d*)(?::([A-Z]+)(\d*))?$/);
+  if (!match) {
+    throw new Error(`範囲「${notation}」を読み取れません。`);
+  }
+  const toColumn = letters => letters.split('').reduce((total, letter) => total * 26 + letter.charCodeAt(0) - 64, 0);
+  const startColumn = toColumn(match[1]);
+  const endColumn = match[3] ? toColumn(match[3]) : startColumn;
+  const startRow = match[2] ? Number(match[2]) : 1;
+  const endRow = match[4] ? Number(match[4]) : (match[2] && !match[3] ? startRow : sheet.getMaxRows());
+  return { row: startRow, column: startColumn, numRows: endRow - startRow + 1, numColumns: endColumn - startColumn + 1 };
+}
+
+function isEmptyCell(value) {
+  return value === '' || value === null || value === undefined;
+}
+
+/**
+ * 並べ替えの比較（空欄は最後・数値と日付は値の大小・それ以外は文字列として比べる）
+ */
+function compareCells(a, b) {
+  if (isEmptyCell(a) || isEmptyCell(b)) {
+    return isEmptyCell(a) === isEmptyCell(b) ? 0 : (isEmptyCell(a) ? 1 : -1);
+  }
+  const toComparable = value => (value && typeof value.getTime === 'function') ? value.getTime() : value;
+  const left = toComparable(a);
+  const right = toComparable(b);
+  if (typeof left === 'number' && typeof right === 'number') {
+    return left - right;
+  }
+  return String(left) < String(right) ? -1 : (String(left) > String(right) ? 1 : 0);
+}
+
+// --------------------------------------------------------------------------------
+// CacheService / PropertiesService
+// --------------------------------------------------------------------------------
+
+function createCacheService(initial) {
+  const entries = new Map(Object.keys(initial).map(key => [key, initial[key]]));
+  const cache = {
+    get: key => entries.has(key) ? entries.get(key) : null,
+    getAll: keys => keys.reduce((result, key) => {
+      if (entries.has(key)) {
+        result[key] = entries.get(key);
+      }
+      return result;
+    }, {}),
+    put: (key, value) => {
+      entries.set(key, String(value));
+    },
+    putAll: values => Object.keys(values).forEach(key => entries.set(key, String(values[key]))),
+    remove: key => {
+      entries.delete(key);
+    },
+    removeAll: keys => keys.forEach(key => entries.delete(key)),
+    entries: entries
+  };
+  return {
+    getScriptCache: () => cache,
+    getUserCache: () => cache,
+    getDocumentCache: () => cache
+  };
+}
+
+function createPropertiesService(initial) {
+  const values = Object.assign({}, initial);
+  const properties = {
+    getProperty: key => Object.prototype.hasOwnProperty.call(values, key) ? values[key] : null,
+    getProperties: () => Object.assign({}, values),
+    getKeys: () => Object.keys(values),
+    setProperty: (key, value) => {
+      values[key] = String(value);
+      return properties;
+    },
+    setProperties: (items, deleteAllOthers) => {
+      if (deleteAllOthers) {
+        Object.keys(values).forEach(key => delete values[key]);
+      }
+      Object.keys(items).forEach(key => {
+        values[key] = String(items[key]);
+      });
+      return properties;
+    },
+    deleteProperty: key => {
+      delete values[key];
+      return properties;
+    },
+    deleteAllProperties: () => {
+      Object.keys(values).forEach(key => delete values[key]);
+      return properties;
+    }
+  };
+  return {
+    getScriptProperties: () => properties,
+    getUserProperties: () => properties,
+    getDocumentProperties: () => properties
+  };
+}
+
+// --------------------------------------------------------------------------------
+// UrlFetchApp
+// --------------------------------------------------------------------------------
+
+/**
+ * UrlFetchApp のフェイクを作る
+ * フィクスチャの fetches は [{ match: 'URLに含まれる文字列', status: 200, body: 応答（オブジェクトならJSONにする） }] の形式です。
+ * 最初に一致したものを返し、どれにも一致しないリクエストはエラーにします（意図しない通信を見逃さないため）。
+ */
+function createUrlFetchApp(harness, responses) {
+  const fetch = (url, options) => {
+    const request = Object.assign({ url: url }, options || {});
+    harness.requests.push({ url: url, method: request.method || 'get', payload: request.payload === undefined ? null : request.payload });
+    const response = responses.find(item => url.indexOf(item.match) !== -1);
+    if (!response) {
+      throw new Error(`フィクスチャにないリクエストです: ${url}`);
+    }
+    const status = response.status || 200;
+    const body = typeof response.body === 'string' ? response.body : JSON.stringify(response.body);
+    if (status >= 400 && !request.muteHttpExceptions) {
+      throw new Error(`Request failed for ${url} returned code ${status}. Truncated server response: ${body.slice(0, 100)}`);
+    }
+    return {
+      getResponseCode: () => status,
+      getContentText: () => body,
+      getHeaders: () => Object.assign({}, response.headers || {})
+    };
+  };
+  return {
+    fetch: fetch,
+    fetchAll: requests => requests.map(request => typeof request === 'string' ? fetch(request) : fetch(request.url, request))
+  };
+}
+
+// --------------------------------------------------------------------------------
+// AdsApp
+// --------------------------------------------------------------------------------
+
+/**
+ * AdsApp のフェイクを作る
+ * フィクスチャの reports / searches は [{ match: 'クエリに含まれる文字列', rows: [行, …] }] の形式です。
+ *   reports  … AdsApp.report() の行（{ 'metrics.clicks': '12' } のようなフィールド名 → 値）
+ *   searches … AdsApp.search() の行（{ campaign: { name: '…' } } のような入れ子のオブジェクト）
+ * match を配列にすると、すべての文字列を含むクエリに一致します。どれにも一致しないクエリはエラーにします。
+ */
+function createAdsApp(harness, fixture) {
+  const account = Object.assign({ customerId: '123-456-7890', name: 'テストアカウント', timeZone: DEFAULT_TIMEZONE, currencyCode: 'JPY' }, fixture.account || {});
+  const findRows = (entries, kind, query) => {
+    harness.queries.push(query.replace(/\s+/g, ' ').trim());
+    const entry = (entries || []).find(item => [].concat(item.match).every(text => query.indexOf(text) !== -1));
+    if (!entry) {
+      throw new Error(`フィクスチャにない${kind}のクエリです: ${query}`);
+    }
+    return entry.rows.map(row => JSON.parse(JSON.stringify(row)));
+  };
+  const iterator = rows => {
+    let index = 0;
+    return {
+      hasNext: () => index < rows.length,
+      next: () => rows[index++],
+      totalNumEntities: () => rows.length,
+      [Symbol.iterator]: function* () {
+        yield* rows;
+      }
+    };
+  };
+
+  return {
+    report: query => {
+      const rows = findRows(fixture.reports, 'レポート', query);
+      return { rows: () => iterator(rows) };
+    },
+    search: query => iterator(findRows(fixture.searches, '検索', query)),
+    currentAccount: () => ({
+      getCustomerId: () => account.customerId,
+      getName: () => account.name,
+      getTimeZone: () => account.timeZone,
+      getCurrencyCode: () => account.currencyCode
+    }),
+    campaigns: () => entitySelector(harness, fixture.campaigns, 'campaign'),
+    negativeKeywordLists: () => entitySelector(harness, fixture.negativeKeywordLists, 'negativeKeywordList'),
+    getExecutionInfo: () => ({
+      getRemainingTime: () => fixture.remainingSeconds === undefined ? 1800 : fixture.remainingSeconds,
+      isPreview: () => false
+    })
+  };
+}
+
+/**
+ * AdsApp.campaigns() / AdsApp.negativeKeywordLists() のセレクターを作る
+ * フィクスチャの campaigns / negativeKeywordLists は [{ id, name, negativeKeywords: ['[語句]', …] }] の形式です。
+ * 絞り込みは withIds() と、名前の一致（withCondition("….name = '名前'")）だけに対応します。
+ * 除外キーワードの追加は、フィクスチャの negativeKeywords に加えたうえで harness.mutations に記録します。
+ */
+function entitySelector(harness, entities, type) {
+  let selected = entities || [];
+  const entityIterator = items => {
+    let index = 0;
+    return { hasNext: () => index < items.length, next: () => items[index++], totalNumEntities: () => items.length };
+  };
+  const wrap = entity => {
+    entity.negativeKeywords = entity.negativeKeywords || [];
+    const addNegative = text => {
+      entity.negativeKeywords.push(text);
+      harness.mutations.push({ type: type, name: entity.name, negativeKeyword: text });
+    };
+    return {
+      getId: () => entity.id,
+      getName: () => entity.name,
+      negativeKeywords: () => ({ get: () => entityIterator(entity.negativeKeywords.map(text => ({ getText: () => text }))) }),
+      createNegativeKeyword: addNegative,
+      addNegativeKeyword: addNegative
+    };
+  };
+  const selector = {
+    withIds: ids => {
+      const wanted = ids.map(String);
+      selected = selected.filter(entity => wanted.indexOf(String(entity.id)) !== -1);
+      return selector;
+    },
+    withCondition: condition => {
+      const match = condition.match(/name\s*=\s*'((?:[^'\\]|\\.)*)'/);
+      if (match) {
+        const name = match[1].replace(/\\'/g, "'");
+        selected = selected.filter(entity => entity.name === name);
+      }
+      return selector;
+    },
+    get: () => entityIterator(selected.map(wrap))
+  };
+  return selector;
+}
+
+// --------------------------------------------------------------------------------
+// フィクスチャとゴールデンファイル
+// --------------------------------------------------------------------------------
+
+function readFixture(name) {
+  return JSON.parse(fs.readFileSync(path.join(FIXTURE_DIR, name), 'utf8'));
+}
+
+/**
+ * スクリプト冒頭の定数（const NAME = …;）の値を書き換える
+ * @param {Set<string>} overridden - 書き換えた定数名を追加する（どのファイルにもない定数はエラーにするため）
+ */
+function overrideConstants(source, constants, overridden) {
+  return Object.keys(constants).reduce((text, name) => {
+    const pattern = new RegExp(`^(\\s*(?:const|let|var)\\s+${name}\\s*=\\s*)[^;\\n]*;`, 'm');
+    if (!pattern.test(text)) {
+      return text;
+    }
+    overridden.add(name);
+    return text.replace(pattern, (all, declaration) => declaration + JSON.stringify(constants[name]) + ';');
+  }, source);
+}
+
+/**
+ * セルの値をゴールデンファイルに書ける形にする（Date は日本時間の日時の文字列にする）
+ */
+function serializeValue(value) {
+  if (value && typeof value.getTime === 'function') {
+    return { $date: formatDate(value, DEFAULT_TIMEZONE, 'yyyy-MM-dd HH:mm:ss') };
+  }
+  if (typeof value === 'number' && !isFinite(value)) {
+    return String(value);
+  }
+  return value === undefined ? null : value;
+}
+
+function deserializeValue(harness, value) {
+  if (value && typeof value === 'object' && value.$date) {
+    return harness.date(value.$date.length === 10 ? value.$date : value.$date.replace(' ', 'T') + '+09:00');
+  }
+  return value;
+}
+
+/**
+ * 結果をゴールデンファイル（テスト/ゴールデン/<name>.json）と比較する
+ * 環境変数 UPDATE_GOLDEN=1 を付けて実行すると、比較せずにゴールデンファイルを書き換えます。
+ */
+function assertGolden(name, actual) {
+  const file = path.join(GOLDEN_DIR, `${name}.json`);
+  const text = JSON.stringify(actual, null, 2) + '\n';
+  if (process.env.UPDATE_GOLDEN) {
+    fs.mkdirSync(GOLDEN_DIR, { recursive: true });
+    fs.writeFileSync(file, text);
+    return;
+  }
+  if (!fs.existsSync(file)) {
+    assert.fail(`ゴールデンファイル ${path.relative(ROOT, file)} がありません。UPDATE_GOLDEN=1 を付けて実行し、内容を確認してからコミットしてください。`);
+  }
+  assert.deepStrictEqual(JSON.parse(text), JSON.parse(fs.readFileSync(file, 'utf8')));
+}
+
+module.exports = {
+  loadScripts: loadScripts,
+  assertGolden: assertGolden,
+  readFixture: readFixture,
+  formatDate: formatDate
+};
@@ -0,0 +1,103 @@
+'use strict';
+/**
+ * 【ランディングページ別データ取得】URLの正規化（Google・Yahoo!で同じ表記になること）と、同じページの行がまとまることを確認する
+ */
+const test = require('node:test');
+const assert = require('node:assert');
+const { loadScripts } = require('./ハーネス.js');
+
+const FILES = [
+  'Google広告スクリプト/ランディングページ別データ取得.go',
+  'Google広告スクリプト/共通/同期処理.go',
+  'Google広告スクリプト/共通/スキーマ.go',
+  'Google広告スクリプト/共通/実行履歴.go',
+  'Google広告スクリプト/共通/設定.go',
+  'Google広告スクリプト/共通/MCC実行.go',
+  'Google広告スクリプト/共通/列挙値.go',
+  'Google広告スクリプト/共通/URL正規化.go'
+];
+const URL = 'https://docs.google.com/spreadsheets/d/test-landing-page';
+
+const CASES = [
+  ['https://Example.COM/lp/?gclid=abc&utm_source=google&UTM_MEDIUM=cpc', 'https://example.com/lp'],
+  ['https://example.com/lp?yclid=YSS.1&item=12#form', 'https://example.com/lp?item=12'],
+  ['https://example.com/', 'https://example.com'],
+  ['https://example.com?fbclid=1', 'https://example.com'],
+  ['HTTP://example.com/A/B//', 'http://example.com/A/B'],
+  ['  --  ', '--'],
+  ['', '']
+];
+
+// Yahoo!のスクリプトは、Google広告スクリプトの「共通/URL正規化.go」を貼り付けて使う
+const YAHOO_SCRIPTS = ['Yahoo広告スクリプト/検索広告取得.go', 'Yahoo広告スクリプト/検索広告取得_定期実行用.go'];
+const NORMALIZER = 'Google広告スクリプト/共通/URL正規化.go';
+
+test('計測用のパラメータ・#以降・末尾のスラッシュを取り除き、ホスト名を小文字にする', () => {
+  const google = loadScripts([NORMALIZER], {});
+  CASES.forEach(([url, expected]) => {
+    assert.strictEqual(google.call('normalizeLandingPageUrl', url), expected, url);
+  });
+});
+
+test('Google・Yahoo!のレポート行を共通の正規化に通すと、同じランディングページになる', () => {
+  const urls = CASES.map(([url]) => url).filter(url => url.trim() !== '');
+  const fixture = {
+    reports: [{ match: 'FROM expanded_landing_page_view', rows: urls.map(url => ({
+      'segments.date': '2025-07-10', 'segments.device': 'MOBILE', 'campaign.id': '1', 'campaign.name': '検索_一般',
+      'expanded_landing_page_view.expanded_final_url': url, 'metrics.impressions': '10', 'metrics.clicks': '1',
+      'metrics.cost_micros': '100000000', 'metrics.conversions': '0', 'metrics.conversions_value': '0'
+    })) }],
+    spreadsheets: {}
+  };
+  fixture.spreadsheets[URL] = {};
+  const google = loadScripts(FILES, { fixture, constants: { SPREADSHEET_URL: URL, MODE: 'range', START_DATE: '2025-07-10', END_DATE: '2025-07-10' } });
+  google.call('main');
+  const googlePages = google.sheetValues(URL)['ランディングページデータ'].slice(1).map(row => row[4]).sort();
+
+  YAHOO_SCRIPTS.forEach(file => {
+    const yahoo = loadScripts([file, NORMALIZER], {});
+    const reportFields = ['DAY', 'FINAL_URL', 'CLICKS'];
+    const reportData = urls.map(url => ['20250710', url, 1]);
+    yahoo.call('appendLandingPageColumn', reportData, reportFields);
+    const yahooPages = Array.from(new Set(reportData.map(row => row[row.length - 1]))).sort();
+    assert.deepStrictEqual(yahooPages, Array.from(googlePages), file);
+  });
+});
+
+test('Yahoo!のスクリプトに「共通/URL正規化.go」を貼り付けていないときは、その旨のエラーにする', () => {
+  YAHOO_SCRIPTS.forEach(file => {
+    const yahoo = loadScripts([file], {});
+    assert.throws(() => yahoo.call('appendLandingPageColumn', [['20250710', 'https://example.com/lp', 1]], ['DAY', 'FINAL_URL', 'CLICKS']),
+      /共通\/URL正規化\.go/, file);
+  });
+});
+
+test('パラメータだけが違うURLは、同じランディングページとして1行に合算する', () => {
+  const row = (device, url, imp, clicks, cost, cv) => ({
+    'segments.date': '2025-07-10', 'segments.device': device, 'campaign.id': '1', 'campaign.name': '検索_一般',
+    'expanded_landing_page_view.expanded_final_url': url, 'metrics.impressions': imp, 'metrics.clicks': clicks,
+    'metrics.cost_micros': cost, 'metrics.conversions': cv, 'metrics.conversions_value': '0'
+  });
+  const fixture = {
+    reports: [{ match: 'FROM expanded_landing_page_view', rows: [
+      row('MOBILE', 'https://example.com/lp/?gclid=a1', '100', '10', '1500000000', '1'),
+      row('MOBILE', 'https://example.com/lp?gclid=b2&utm_source=google', '50', '5', '500000000', '0.5'),
+      row('MOBILE', 'https://example.com/other', '20', '1', '100000000', '0'),
+      row('DESKTOP', 'https://example.com/lp', '30', '3', '300000000', '0')
+    ] }],
+    spreadsheets: {}
+  };
+  fixture.spreadsheets[URL] = {};
+  const harness = loadScripts(FILES, { fixture, constants: { SPREADSHEET_URL: URL, MODE: 'range', START_DATE: '2025-07-10', END_DATE: '2025-07-10' } });
+  harness.call('main');
+
+  const values = harness.sheetValues(URL)['ランディングページデータ'];
+  assert.deepStrictEqual(Array.from(values[0]), ['日付', 'デバイス', 'キャンペーンID', 'キャンペーン名', 'ランディングページ',
+    '表示回数', 'クリック数', '費用', 'コンバージョン数', 'コンバージョン価値']);
+  assert.deepStrictEqual(values.slice(1).map(row => [row[1], row[4], row[5], row[6], row[7], row[8]]), [
+    ['スマートフォン', 'https://example.com/lp', 150, 15, 2000, 1.5],
+    ['スマートフォン', 'https://example.com/other', 20, 1, 100, 0],
+    ['コンピュータ', 'https://example.com/lp', 30, 3, 300, 0]
+  ]);
+  assert.ok(harness.logs.join('\n').indexOf('4件 を 3件 にまとめました') !== -1);
+});
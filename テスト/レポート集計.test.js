@@ -0,0 +1,32 @@
+'use strict';
+/**
+ * 【Google広告用レポート】3つのシート（フィクスチャ）から、Webアプリに返す集計結果を確認する
+ */
+const test = require('node:test');
+const assert = require('node:assert');
+const { loadScripts, assertGolden } = require('./ハーネス.js');
+
+const FILES = [
+  'Google広告用レポート/Config.go',
+  'Google広告用レポート/Code.go'
+];
+
+test('前月・前々月の合計と内訳を集計する', () => {
+  const harness = loadScripts(FILES, { fixture: 'レポート集計.json' });
+  const reportData = harness.call('getReportData', false);
+
+  assertGolden('レポート集計', reportData);
+});
+
+test('2回目はキャッシュから返し、refresh で作り直す', () => {
+  const harness = loadScripts(FILES, { fixture: 'レポート集計.json' });
+  const first = harness.call('getReportData', false);
+  assert.ok(harness.logs.indexOf('新しいレポートデータを生成し、キャッシュに保存しました。') !== -1);
+
+  const cached = harness.call('getReportData', false);
+  assert.ok(harness.logs.indexOf('キャッシュからレポートデータを返します。') !== -1);
+  assert.deepStrictEqual(JSON.parse(JSON.stringify(cached)), JSON.parse(JSON.stringify(first)));
+
+  harness.call('getReportData', true);
+  assert.ok(harness.logs.indexOf('キャッシュをクリアしました。') !== -1);
+});
@@ -0,0 +1,102 @@
+'use strict';
+/**
+ * 【予算ペース監視】曜日ごとの費用の傾向と休日から予測した月末の費用、判定、通知メールを確認する
+ */
+const test = require('node:test');
+const assert = require('node:assert');
+const { loadScripts } = require('./ハーネス.js');
+
+const FILES = [
+  'Google広告スクリプト/予算ペース監視.go',
+  'Google広告スクリプト/共通/同期処理.go',
+  'Google広告スクリプト/共通/スキーマ.go',
+  'Google広告スクリプト/共通/実行履歴.go',
+  'Google広告スクリプト/共通/列挙値.go'
+];
+const URL = 'https://docs.google.com/spreadsheets/d/test-budget-pacing';
+const BUDGET_HEADERS = ['月', 'アカウント名', 'キャンペーン名', '月予算', 'メモ'];
+
+/**
+ * 6月1日から昨日（7月14日）までの「基本データ」
+ *   A … 毎日1,000円（デバイス別の2行に分けて記録）
+ *   B … 平日だけ2,000円（土日は配信なし）
+ */
+function baseDataRows() {
+  const rows = [['日付', 'デバイス', 'アカウント名', 'キャンペーン名', 'ご利用額']];
+  for (let time = Date.UTC(2025, 5, 1); time <= Date.UTC(2025, 6, 14); time += 24 * 60 * 60 * 1000) {
+    const date = new Date(time).toISOString().slice(0, 10);
+    rows.push([{ $date: date }, 'スマートフォン', 'テスト株式会社', 'A', 600]);
+    rows.push([{ $date: date }, 'コンピュータ', 'テスト株式会社', 'A', 400]);
+    const weekday = new Date(time).getUTCDay();
+    if (weekday !== 0 && weekday !== 6) {
+      rows.push([{ $date: date }, 'スマートフォン', 'テスト株式会社', 'B', '2,000']);
+    }
+  }
+  return rows;
+}
+
+function pacingFixture(budgetRows) {
+  const fixture = { spreadsheets: {} };
+  fixture.spreadsheets[URL] = {
+    '基本データ': baseDataRows(),
+    '祝日データ': [['日付', '祝日名'], [{ $date: '2025-07-21' }, '海の日']]
+  };
+  if (budgetRows) {
+    fixture.spreadsheets[URL]['予算'] = [BUDGET_HEADERS].concat(budgetRows);
+  }
+  return fixture;
+}
+
+test('曜日ごとの費用と休日から月末の費用を予測し、予算からずれた行をメールで知らせる', () => {
+  const fixture = pacingFixture([
+    ['', '', '', 100000, 'すべてのキャンペーンの合計'],
+    ['', '', 'A', 50000, '毎月の予算（7月は下の行を使う）'],
+    ['2025-07', '', 'A', 30000, ''],
+    ['2025-07', '', 'B', 36000, ''],
+    ['2025-06', '', 'B', 1, '対象月ではないため使わない'],
+    ['', 'テスト株式会社', 'B', 15000, '']
+  ]);
+  const harness = loadScripts(FILES, { fixture, constants: { SPREADSHEET_URL: URL, NOTIFY_EMAIL: 'ads@example.com' } });
+  harness.call('main');
+
+  const values = harness.sheetValues(URL)['予算ペース'];
+  assert.deepStrictEqual(Array.from(values[0]), ['基準日', '月', 'アカウント名', 'キャンペーン名', '月予算', '当月の費用', '消化率', '月末の予測',
+    '予測の消化率', '残りの日数', '残りの配信日数', '1日あたりの残り予算', '判定']);
+  // 7月15日〜31日の17日のうち、7月21日（休日）を除く16日に配信し、B は残りの平日12日分だけを見込む
+  const summary = Array.from(values.slice(1), row => [row[2], row[3], row[5], row[7], row[9], row[10], row[11], row[12]]);
+  assert.deepStrictEqual(summary.sort(), [
+    ['テスト株式会社', 'B', 20000, 44000, 17, 16, 0, '予算超過'],
+    ['（すべて）', 'A', 14000, 30000, 17, 16, 1000, '順調'],
+    ['（すべて）', 'B', 20000, 44000, 17, 16, 1000, '超過ペース'],
+    ['（すべて）', '（アカウント全体）', 34000, 74000, 17, 16, 4125, '消化不足']
+  ]);
+
+  assert.strictEqual(harness.mails.length, 1);
+  const [to, subject, body] = harness.mails[0];
+  assert.strictEqual(to, 'ads@example.com');
+  assert.ok(subject.indexOf('2025-07-14 時点') !== -1 && subject.indexOf('3件') !== -1, subject);
+  assert.ok(body.indexOf('【超過ペース】（すべて） / B') !== -1, body);
+  assert.ok(body.indexOf('順調') === -1, body);
+
+  // 同じ日に再実行すると、その日の記録を置き換える
+  harness.call('main');
+  assert.strictEqual(harness.sheetValues(URL)['予算ペース'].length, 5);
+});
+
+test('「予算」シートがなければ作成し、誤りのある行があれば記録せずに終了する', () => {
+  const created = loadScripts(FILES, { fixture: pacingFixture(null), constants: { SPREADSHEET_URL: URL } });
+  created.call('main');
+  assert.deepStrictEqual(Array.from(created.sheetValues(URL)['予算'][0]), BUDGET_HEADERS);
+  assert.strictEqual(created.sheetValues(URL)['予算ペース'], undefined);
+
+  const invalid = loadScripts(FILES, {
+    fixture: pacingFixture([['2025年7月', '', '', 100000, ''], ['7月', '', 'A', 30000, ''], ['', '', 'B', 0, '']]),
+    constants: { SPREADSHEET_URL: URL }
+  });
+  invalid.call('main');
+  const logs = invalid.logs.join('\n');
+  assert.ok(logs.indexOf('3行目: 月「7月」は 2025-07 の形式で入力してください') !== -1, logs);
+  assert.ok(logs.indexOf('4行目: 月予算「0」は0より大きい数値で入力してください') !== -1, logs);
+  assert.ok(logs.indexOf('2行目') === -1, logs);
+  assert.strictEqual(invalid.sheetValues(URL)['予算ペース'], undefined);
+});
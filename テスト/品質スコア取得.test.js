@@ -0,0 +1,85 @@
+'use strict';
+/**
+ * 【品質スコア取得】前回の記録との比較で、上がった・下がった項目だけが変更履歴に残ることを確認する
+ */
+const test = require('node:test');
+const assert = require('node:assert');
+const { loadScripts } = require('./ハーネス.js');
+
+const FILES = [
+  'Google広告スクリプト/品質スコア取得.go',
+  'Google広告スクリプト/共通/同期処理.go',
+  'Google広告スクリプト/共通/スキーマ.go',
+  'Google広告スクリプト/共通/実行履歴.go',
+  'Google広告スクリプト/共通/設定.go',
+  'Google広告スクリプト/共通/MCC実行.go',
+  'Google広告スクリプト/共通/列挙値.go',
+  'Google広告スクリプト/共通/セグメント.go'
+];
+const URL = 'https://docs.google.com/spreadsheets/d/test-quality-score';
+const HEADERS = ['記録日', 'キャンペーンID', 'キャンペーン名', '広告グループID', '広告グループ名', 'キーワードID', 'キーワード', 'マッチタイプ',
+  '品質スコア', '推定クリック率', '広告の関連性', 'ランディングページの利便性'];
+
+function keyword(criterionId, text, score, ctr, relevance, landingPage) {
+  return {
+    'campaign.id': '1', 'campaign.name': '検索_式場', 'ad_group.id': '10', 'ad_group.name': '広島',
+    'ad_group_criterion.criterion_id': criterionId, 'ad_group_criterion.keyword.text': text,
+    'ad_group_criterion.keyword.match_type': 'PHRASE',
+    'ad_group_criterion.quality_info.quality_score': score,
+    'ad_group_criterion.quality_info.search_predicted_ctr': ctr,
+    'ad_group_criterion.quality_info.creative_quality_score': relevance,
+    'ad_group_criterion.quality_info.post_click_quality_score': landingPage
+  };
+}
+
+function storedRow(date, criterionId, text, score, ctr, relevance, landingPage) {
+  return [{ $date: date }, '1', '検索_式場', '10', '広島', criterionId, text, 'フレーズ一致', score, ctr, relevance, landingPage];
+}
+
+function buildFixture(storedRows) {
+  const fixture = {
+    reports: [{ match: 'FROM ad_group_criterion', rows: [
+      keyword('101', '広島 市 内 結婚 式場', '3', 'AVERAGE', 'BELOW_AVERAGE', 'ABOVE_AVERAGE'),
+      keyword('102', 'ホテル 結婚 式 広島', '4', 'BELOW_AVERAGE', 'AVERAGE', 'AVERAGE'),
+      keyword('103', '広島 チャペル', '', '', '', '')
+    ] }],
+    spreadsheets: {}
+  };
+  fixture.spreadsheets[URL] = { '品質スコア': [HEADERS].concat(storedRows) };
+  return fixture;
+}
+
+test('前回の記録がない日は品質スコアだけを追記し、変更履歴は記録しない', () => {
+  const harness = loadScripts(FILES, { fixture: buildFixture([]), constants: { SPREADSHEET_URL: URL } });
+  harness.call('main');
+
+  const sheets = harness.sheetValues(URL);
+  assert.deepStrictEqual(sheets['品質スコア'].slice(1).map(row => row.slice(5)), [
+    ['101', '広島 市 内 結婚 式場', 'フレーズ一致', 3, '平均的', '平均より下', '平均より上'],
+    ['102', 'ホテル 結婚 式 広島', 'フレーズ一致', 4, '平均より下', '平均的', '平均的'],
+    ['103', '広島 チャペル', 'フレーズ一致', '', '', '', '']
+  ]);
+  assert.strictEqual(sheets['品質スコアの変更履歴'].length, 1);
+  assert.ok(harness.logs.join('\n').indexOf('次回の実行から') !== -1);
+});
+
+test('前回の記録から上がった・下がった項目を変更履歴に記録し、同じ日の再実行では置き換える', () => {
+  const stored = [
+    storedRow('2025-07-13', '101', '広島 市 内 結婚 式場', 1, '平均より下', '平均より下', '平均的'),
+    storedRow('2025-07-14', '101', '広島 市 内 結婚 式場', 1, '平均的', '平均より下', '平均的'),
+    storedRow('2025-07-14', '102', 'ホテル 結婚 式 広島', 4, '平均的', '平均的', '平均的'),
+    storedRow('2025-07-14', '103', '広島 チャペル', 5, '平均的', '平均的', '平均的')
+  ];
+  const harness = loadScripts(FILES, { fixture: buildFixture(stored), constants: { SPREADSHEET_URL: URL } });
+  harness.call('main');
+  harness.call('main');
+
+  const sheets = harness.sheetValues(URL);
+  assert.strictEqual(sheets['品質スコア'].length, 1 + stored.length + 3);
+  assert.deepStrictEqual(sheets['品質スコアの変更履歴'].slice(1).map(row => [row[6], row[8], row[9], row[10], row[11]]), [
+    ['広島 市 内 結婚 式場', '品質スコア', 1, 3, '上昇'],
+    ['広島 市 内 結婚 式場', 'ランディングページの利便性', '平均的', '平均より上', '上昇'],
+    ['ホテル 結婚 式 広島', '推定クリック率', '平均的', '平均より下', '低下']
+  ]);
+  assert.strictEqual(sheets['品質スコアの変更履歴'][1][1], '2025-07-14');
+});
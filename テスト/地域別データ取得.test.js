@@ -0,0 +1,120 @@
+'use strict';
+/**
+ * 【地域別データ取得】地域IDをキーにした行の置き換えと、キャンペーンのステータスで絞り込まないクエリを確認する
+ */
+const test = require('node:test');
+const assert = require('node:assert');
+const { loadScripts } = require('./ハーネス.js');
+
+const FILES = [
+  'Google広告スクリプト/地域別データ取得.go',
+  'Google広告スクリプト/共通/同期処理.go',
+  'Google広告スクリプト/共通/スキーマ.go',
+  'Google広告スクリプト/共通/実行履歴.go',
+  'Google広告スクリプト/共通/設定.go',
+  'Google広告スクリプト/共通/MCC実行.go',
+  'Google広告スクリプト/共通/列挙値.go',
+  'Google広告スクリプト/共通/セグメント.go'
+];
+const URL = 'https://docs.google.com/spreadsheets/d/test-region';
+const HEADERS = ['日付', '地域ID', 'ターゲット地域', 'クリック数', '表示回数', '費用', 'コンバージョン数'];
+// 地域IDの列を追加する前の見出し
+const LEGACY_HEADERS = HEADERS.filter(header => header !== '地域ID');
+const toDay = value => typeof value === 'string' ? value : value.$date.slice(0, 10);
+
+function regionRow(date, criterionId, clicks, conversions) {
+  return {
+    'segments.date': date, 'campaign_criterion.criterion_id': criterionId, 'metrics.clicks': clicks,
+    'metrics.impressions': clicks * 10, 'metrics.cost_micros': String(clicks * 100000000), 'metrics.conversions': conversions
+  };
+}
+
+function buildFixture(mode) {
+  const fixture = {
+    reports: [
+      { match: 'location_view', rows: [regionRow('2025-07-12', '2001', 5, 2), regionRow('2025-07-14', '2001', 3, 0)] },
+      { match: 'campaign_criterion.type', rows: [
+        { 'campaign_criterion.criterion_id': '2001', 'campaign_criterion.type': 'LOCATION', 'campaign_criterion.location.geo_target_constant': 'geoTargetConstants/1009310' },
+        { 'campaign_criterion.criterion_id': '3001', 'campaign_criterion.type': 'LOCATION', 'campaign_criterion.location.geo_target_constant': 'geoTargetConstants/1009311' }
+      ] },
+      { match: 'FROM geo_target_constant', rows: [
+        { 'geo_target_constant.resource_name': 'geoTargetConstants/1009310', 'geo_target_constant.name': 'Tokyo' },
+        { 'geo_target_constant.resource_name': 'geoTargetConstants/1009311', 'geo_target_constant.name': 'Tokyo' }
+      ] }
+    ],
+    spreadsheets: {}
+  };
+  fixture.spreadsheets[URL] = {
+    '実行履歴': [
+      ['記録日時', 'シート名', '開始日', '終了日', '件数', 'ステータス', 'メッセージ'],
+      ['', '地域別データ', '2025-07-01', '2025-07-13', 2, '成功', '']
+    ],
+    '地域別データ': [
+      LEGACY_HEADERS,
+      [{ $date: '2025-07-05' }, 'Tokyo', 4, 40, 400, 1],
+      [{ $date: '2025-07-12' }, 'Tokyo', 4, 40, 400, 1],
+      [{ $date: '2025-07-12' }, 'Osaka', 2, 20, 200, 1]
+    ]
+  };
+  if (mode) {
+    fixture.spreadsheets[URL]['設定'] = [
+      ['データ', '項目', '値', 'メモ'],
+      ['', 'MODE', mode, ''],
+      ['', 'START_DATE', '2025-07-05', ''],
+      ['', 'END_DATE', '2025-07-05', '']
+    ];
+  }
+  return fixture;
+}
+
+test('キャンペーンのステータスで絞り込まずに取得し、再取得の期間を置き換える', () => {
+  const harness = loadScripts(FILES, { fixture: buildFixture(), constants: { SPREADSHEET_URL: URL } });
+  harness.call('main');
+
+  const query = harness.queries.find(text => text.indexOf('FROM location_view') !== -1);
+  assert.ok(query.indexOf('campaign.status') === -1, query);
+  const rows = harness.sheetValues(URL)['地域別データ'];
+  assert.deepStrictEqual(Array.from(rows[0]), HEADERS);
+  assert.deepStrictEqual(rows.slice(1).map(row => [toDay(row[0]), row[1], row[2], row[3], row[6]]), [
+    ['2025-07-05', '', 'Tokyo', 4, 1],
+    ['2025-07-12', '2001', 'Tokyo', 5, 2],
+    ['2025-07-14', '2001', 'Tokyo', 3, 0]
+  ]);
+});
+
+test('filtersCurrentStatus を指定したデータセットは、再取得で返らなかった行を残す', () => {
+  const harness = loadScripts(FILES, { fixture: buildFixture(), constants: { SPREADSHEET_URL: URL } });
+  harness.call('main');
+  const sheet = harness.context.SpreadsheetApp.openByUrl(URL).getSheetByName('地域別データ');
+  const dataset = {
+    headers: HEADERS,
+    columns: HEADERS.map(label => ({ label: label })),
+    keyHeaders: ['日付', '地域ID'],
+    filtersCurrentStatus: true
+  };
+  const result = harness.call('upsertRows', sheet, dataset, [['2025-07-14', '2001', 'Tokyo', 4, 40, 400, 1]],
+    { startDate: '2025-07-12', endDate: '2025-07-14' });
+
+  assert.strictEqual(result.replacedCount, 1);
+  assert.strictEqual(result.conversionsBefore, 0);
+  assert.deepStrictEqual(harness.sheetValues(URL)['地域別データ'].slice(1).map(row => [toDay(row[0]), row[1], row[6]]), [
+    ['2025-07-05', '', 1],
+    ['2025-07-12', '2001', 2],
+    ['2025-07-14', '2001', 1]
+  ]);
+});
+
+test('名前が同じ地域も地域IDで分けて記録し、地域IDの列を追加する前の行を置き換える', () => {
+  const fixture = buildFixture('range');
+  fixture.reports[0].rows = [regionRow('2025-07-05', '2001', 6, 1), regionRow('2025-07-05', '3001', 2, 0), regionRow('2025-07-05', '4001', 1, 0)];
+  const harness = loadScripts(FILES, { fixture: fixture, constants: { SPREADSHEET_URL: URL } });
+  harness.call('main');
+
+  // 名前を特定できなかった地域（4001）は、地域IDをそのまま表示する
+  const rows = harness.sheetValues(URL)['地域別データ'];
+  assert.deepStrictEqual(rows.slice(1).filter(row => toDay(row[0]) === '2025-07-05').map(row => [row[1], row[2], row[3]]), [
+    ['2001', 'Tokyo', 6],
+    ['3001', 'Tokyo', 2],
+    ['4001', '4001', 1]
+  ]);
+});
@@ -0,0 +1,134 @@
+'use strict';
+/**
+ * 【基本データ取得】GAQLの応答（フィクスチャ）から「基本データ」シートと実行履歴に書き込まれる行を確認する
+ */
+const test = require('node:test');
+const assert = require('node:assert');
+const { loadScripts, assertGolden, readFixture } = require('./ハーネス.js');
+
+const FILES = [
+  'Google広告スクリプト/基本データ取得.go',
+  'Google広告スクリプト/共通/同期処理.go',
+  'Google広告スクリプト/共通/スキーマ.go',
+  'Google広告スクリプト/共通/実行履歴.go',
+  'Google広告スクリプト/共通/設定.go',
+  'Google広告スクリプト/共通/MCC実行.go',
+  'Google広告スクリプト/共通/移行チェック.go',
+  'Google広告スクリプト/共通/列挙値.go'
+];
+const CONSTANTS = { SPREADSHEET_URL: 'https://docs.google.com/spreadsheets/d/test-base' };
+
+test('空のスプレッドシートに見出し行とデータを書き込む', () => {
+  const harness = loadScripts(FILES, { fixture: '基本データ取得.json', constants: CONSTANTS });
+  harness.call('main');
+
+  assert.ok(!harness.logs.some(line => line.indexOf('[error]') === 0), harness.logs.join('\n'));
+  assertGolden('基本データ取得', { queries: harness.queries, sheets: harness.sheetValues() });
+});
+
+test('取得済みの期間は再取得しない', () => {
+  const harness = loadScripts(FILES, { fixture: '基本データ取得.json', constants: CONSTANTS });
+  harness.call('main');
+  const rowsAfterFirstRun = harness.sheetValues()['基本データ'].length;
+
+  harness.call('main');
+  assert.strictEqual(harness.sheetValues()['基本データ'].length, rowsAfterFirstRun);
+});
+
+test('ENUM_OUTPUT が both のときは日本語の表記とコードを並べて記録し、未登録の値を一覧にする', () => {
+  const fixture = readFixture('基本データ取得.json');
+  fixture.reports[0].rows[1]['campaign.bidding_strategy_type'] = 'NEW_STRATEGY';
+  fixture.spreadsheets = {};
+  fixture.spreadsheets[CONSTANTS.SPREADSHEET_URL] = {
+    '設定': [['データ', '項目', '値', 'メモ'], ['基本データ', 'ENUM_OUTPUT', 'both', '']]
+  };
+  const harness = loadScripts(FILES, { fixture: fixture, constants: CONSTANTS });
+  harness.call('main');
+  harness.call('main');
+
+  const sheets = harness.sheetValues(CONSTANTS.SPREADSHEET_URL);
+  const headers = sheets['基本データ'][0];
+  const deviceIndex = headers.indexOf('デバイス');
+  assert.strictEqual(headers[deviceIndex + 1], 'デバイス（コード）');
+  assert.deepStrictEqual(sheets['基本データ'].slice(1).map(row => row.slice(deviceIndex, deviceIndex + 2)),
+    [['スマートフォン', 'MOBILE'], ['テレビ画面', 'CONNECTED_TV']]);
+  assert.deepStrictEqual(sheets['未登録の値'].slice(1).map(row => row.slice(1, 4)),
+    [['基本データ', '入札戦略タイプ', 'NEW_STRATEGY']]);
+});
+
+test('日次更新の続きから隙間なくつながる期間だけを取得済みとして扱う', () => {
+  const fixture = readFixture('基本データ取得.json');
+  fixture.spreadsheets = {};
+  fixture.spreadsheets[CONSTANTS.SPREADSHEET_URL] = {
+    '実行履歴': [
+      ['記録日時', 'シート名', '開始日', '終了日', '件数', 'ステータス', 'メッセージ'],
+      ['', '基本データ', '2025-07-01', '2025-07-08', 10, '成功', ''],
+      ['', '基本データ', '2025-07-09', '2025-07-10', 2, '成功', '[期間指定 2025-07-09〜2025-07-10]'],
+      ['', '基本データ', '2025-07-13', '2025-07-14', 2, '成功', '[年指定 2025]'],
+      ['', '基本データ', '2025-07-11', '2025-07-12', 0, 'エラー', ''],
+      ['', '別のシート', '2025-07-11', '2025-07-14', 4, '成功', '']
+    ]
+  };
+  const harness = loadScripts(FILES, { fixture: fixture, constants: CONSTANTS });
+  const spreadsheet = harness.context.SpreadsheetApp.openByUrl(CONSTANTS.SPREADSHEET_URL);
+
+  // 7月11日・12日が取得されていないため、7月13日からの年指定の取得は含めない
+  assert.strictEqual(harness.call('getSyncWatermark', spreadsheet, '基本データ'), '2025-07-10');
+  assert.strictEqual(harness.call('getSyncWatermark', spreadsheet, '別のシート'), '2025-07-14');
+  assert.strictEqual(harness.call('getSyncWatermark', spreadsheet, '未取得のシート'), null);
+});
+
+test('シートにはAWQLで記録していた頃と同じ値を書き込み、移行チェックで新旧が一致する', () => {
+  const fixture = readFixture('基本データ取得.json');
+  const written = loadScripts(FILES, { fixture: fixture, constants: CONSTANTS });
+  written.call('main');
+  const rows = written.sheetValues()['基本データ'].slice(1);
+  assert.deepStrictEqual(rows.map(row => [row[1], row[5], row[7], row[11], row[30]]), [
+    ['MOBILE', 'enabled', 'Target CPA', '7.00%', '< 10%'],
+    ['STREAMING_TV', 'paused', 'TARGET_CPM', '0.06%', ' --']
+  ]);
+
+  // 旧AWQLの応答（区分値は表示名、金額はカンマ区切り）を、書き込んだ行から組み立てる
+  const legacyFields = [
+    'Date', 'Device', 'AccountDescriptiveName', 'CampaignId', 'CampaignName', 'CampaignStatus',
+    'AdvertisingChannelType', 'BiddingStrategyType', 'Impressions', 'Clicks', 'Cost', 'Ctr', 'AverageCpc',
+    'Conversions', 'ConversionRate', 'CostPerConversion', 'ConversionValue', 'AllConversions', 'AllConversionRate',
+    'CostPerAllConversion', 'AllConversionValue', 'ViewThroughConversions', 'Interactions', 'InteractionRate',
+    'AverageCost', 'AverageCpm', 'AverageCpv', 'SearchImpressionShare', 'SearchTopImpressionShare',
+    'SearchAbsoluteTopImpressionShare', 'SearchBudgetLostImpressionShare', 'SearchRankLostImpressionShare',
+    'ContentImpressionShare', 'ContentBudgetLostImpressionShare', 'ContentRankLostImpressionShare',
+    'VideoViews', 'VideoViewRate', 'VideoQuartile25Rate', 'VideoQuartile50Rate', 'VideoQuartile75Rate', 'VideoQuartile100Rate'
+  ];
+  const legacyRows = rows.map(row => {
+    const legacyRow = {};
+    legacyFields.forEach((field, index) => {
+      legacyRow[field] = typeof row[index] === 'number' ? row[index].toLocaleString('en-US', { maximumFractionDigits: 6 }) : row[index];
+    });
+    return legacyRow;
+  });
+  legacyRows[0].Device = 'Mobile devices with full browsers';
+  legacyRows[1].Device = 'Devices streaming video content to TV screens';
+  legacyRows[0].AdvertisingChannelType = 'Search';
+  legacyRows[1].AdvertisingChannelType = 'Video';
+  legacyRows[1].BiddingStrategyType = 'Target CPM';
+  fixture.reports.push({ match: 'CAMPAIGN_PERFORMANCE_REPORT', rows: legacyRows });
+
+  const harness = loadScripts(FILES, { fixture: fixture, constants: CONSTANTS });
+  harness.call('checkGaqlMigration');
+  assert.ok(harness.logs.some(line => line.indexOf('結果: 新旧の結果はすべて一致しました。') !== -1), harness.logs.join('\n'));
+});
+
+test('複数のEnum値で同じ日本語の表記は、Enum値を決めずにそのまま記録して未登録の値に残す', () => {
+  const harness = loadScripts(FILES, { fixture: '基本データ取得.json', constants: CONSTANTS });
+  assert.strictEqual(harness.call('toEnumCode', 'adGroupType', 'インストリーム'), 'インストリーム');
+  assert.strictEqual(harness.call('toEnumCode', 'adGroupType', 'インフィード動画'), 'インフィード動画');
+  assert.strictEqual(harness.call('toEnumCode', 'adGroupType', 'ディスプレイ'), 'DISPLAY_STANDARD');
+
+  const dataset = { columns: [{ key: 'ad_group.type', label: '広告グループの種類', type: 'text', enum: 'adGroupType' }] };
+  harness.call('registerSchema', dataset);
+  harness.call('applyEnumOutput', dataset, 'code');
+  assert.deepStrictEqual(Array.from(harness.call('translateEnumRows', dataset, [['インストリーム']]), row => Array.from(row)), [['インストリーム']]);
+  harness.call('reportUnmappedEnums', harness.context.SpreadsheetApp.openByUrl(CONSTANTS.SPREADSHEET_URL), 'グループデータ');
+  assert.deepStrictEqual(harness.sheetValues()['未登録の値'].slice(1).map(row => row.slice(1, 4)),
+    [['グループデータ', '広告グループの種類', 'インストリーム']]);
+});
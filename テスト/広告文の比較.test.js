@@ -0,0 +1,56 @@
+'use strict';
+/**
+ * 【広告文の比較】広告データ・アセット評価のシートから、成果の良い広告・悪い広告と、評価が最良・低のアセットを選ぶ処理を確認する
+ */
+const test = require('node:test');
+const assert = require('node:assert');
+const { loadScripts } = require('./ハーネス.js');
+
+const FILES = [
+  'Google広告用レポート/Config.go',
+  'Google広告用レポート/HTMLレポート生成（検索広告）.go'
+];
+const AD_HEADERS = ['日付', 'キャンペーンID', 'キャンペーン名', '広告チャネルタイプ', '広告グループID', '広告グループ名', '広告ID', '広告タイプ', '広告ステータス', '広告の有効性',
+  '広告見出し', '説明文', '最終ページURL', '表示回数', 'クリック数', '費用', 'コンバージョン数', 'コンバージョン価値'];
+const ASSET_HEADERS = ['記録日', '種類', 'キャンペーン名', '広告グループ・アセットグループID', '広告グループ・アセットグループ名', '広告ID', 'アセットID', 'アセットの種類',
+  'テキスト・アセット名', '固定表示', '評価', '表示回数', 'クリック数', '費用', 'コンバージョン数'];
+
+test('前月のクリック数が下限以上の検索広告を、CVRの高い順に良い広告・悪い広告に分ける', () => {
+  const harness = loadScripts(FILES, {});
+  const ad = (date, channel, adId, headlines, clicks, cost, cv) =>
+    [harness.date(date), '1', '検索_一般', channel, '10', '一般', adId, 'RESPONSIVE_SEARCH_AD', 'ENABLED', 'GOOD', headlines, '説明', 'https://example.com/', clicks * 10, clicks, cost, cv];
+  const rows = [
+    ad('2025-06-01', 'SEARCH', '101', '旧見出し', 20, 10000, 1),
+    ad('2025-06-20', 'SEARCH', '101', '[1]新見出し / 見出し2', 30, 15000, 4),   // 101: 50クリック・5CV（CVR 10%）
+    ad('2025-06-10', '検索', '102', 'B', 40, 20000, 2),                           // 102: CVR 5%
+    ad('2025-06-10', 'SEARCH', '103', 'C', 50, 30000, 0),                         // 103: CVR 0%
+    ad('2025-06-10', 'SEARCH', '104', 'D', 5, 3000, 1),                           // クリック数が下限未満
+    ad('2025-06-10', 'DISPLAY', '105', 'E', 90, 9000, 9),                         // 検索広告以外
+    ad('2025-07-01', 'SEARCH', '103', 'C', 90, 9000, 9)                           // 期間外
+  ];
+  const result = harness.call('aggregateAdCreatives', rows, AD_HEADERS, harness.date('2025-06-01'), harness.date('2025-06-30'), 20);
+
+  assert.strictEqual(result.comparedCount, 3);
+  assert.deepStrictEqual(Array.from(result.winners, a => [a.adId, a.clicks, a.cv, a.headlines]), [['101', 50, 5, '[1]新見出し / 見出し2'], ['102', 40, 2, 'B']]);
+  assert.deepStrictEqual(Array.from(result.losers, a => a.adId), ['103']);
+});
+
+test('アセットの評価が最良・低のものを、同じテキストごとにまとめて一覧にする', () => {
+  const harness = loadScripts(FILES, {});
+  const asset = (source, fieldType, text, label, imp) => [harness.date('2025-07-14'), source, '検索_一般', '10', '一般', '101', '1', fieldType, text, '', label, imp, 0, 0, 0];
+  const rows = [
+    asset('レスポンシブ検索広告', '広告見出し', '送料無料', '最良', 100),
+    asset('レスポンシブ検索広告', '広告見出し', '送料無料', 'BEST', 50),
+    asset('レスポンシブ検索広告', '説明文', '<今すぐ>', '低', 10),
+    asset('P-MAX', '長い広告見出し', '公式サイト', 'LOW', ''),
+    asset('レスポンシブ検索広告', '広告見出し', '人気', '良', 500)
+  ];
+  const result = harness.call('aggregateAssetLabels', rows, ASSET_HEADERS);
+
+  assert.deepStrictEqual(Array.from(result.best, a => [a.text, a.count, a.imp]), [['送料無料', 2, 150]]);
+  assert.deepStrictEqual(Array.from(result.low, a => [a.source, a.text]), [['レスポンシブ検索広告', '<今すぐ>'], ['P-MAX', '公式サイト']]);
+
+  const html = harness.call('buildCreativeHtml', { ads: null, assets: result });
+  assert.ok(html.indexOf('&lt;今すぐ&gt;') !== -1);
+  assert.ok(html.indexOf('2025-07-14 時点') !== -1);
+});
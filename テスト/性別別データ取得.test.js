@@ -0,0 +1,106 @@
+'use strict';
+/**
+ * 【性別データ取得】SEGMENTS でデバイスの列を追加したときの、見出し行・クエリ・既存行の置き換えと、既存の行に合わせる区分値の表記を確認する
+ */
+const test = require('node:test');
+const assert = require('node:assert');
+const { loadScripts } = require('./ハーネス.js');
+
+const FILES = [
+  'Google広告スクリプト/性別別データ取得.go',
+  'Google広告スクリプト/共通/同期処理.go',
+  'Google広告スクリプト/共通/スキーマ.go',
+  'Google広告スクリプト/共通/実行履歴.go',
+  'Google広告スクリプト/共通/設定.go',
+  'Google広告スクリプト/共通/MCC実行.go',
+  'Google広告スクリプト/共通/列挙値.go',
+  'Google広告スクリプト/共通/セグメント.go'
+];
+const URL = 'https://docs.google.com/spreadsheets/d/test-gender';
+// シートの日付は、既存の行は { $date }・書き込んだ行は文字列のため、yyyy-MM-dd に揃えて比べる
+const toDay = value => typeof value === 'string' ? value : value.$date.slice(0, 10);
+const HEADERS = ['日付', 'キャンペーン名', '広告グループ名', '性別', '表示回数', 'クリック数', '費用', 'コンバージョン数'];
+
+function buildFixture(segments) {
+  const spreadsheet = {
+    '設定': [
+      ['データ', '項目', '値', 'メモ'],
+      ['', 'MODE', 'range', ''],
+      ['', 'START_DATE', '2025-07-13', ''],
+      ['', 'END_DATE', '2025-07-13', ''],
+      ['', 'SEGMENTS', segments, '']
+    ],
+    // 分割を追加する前に記録した行（デバイスの列がない）
+    '性別データ': [
+      HEADERS,
+      [{ $date: '2025-07-12' }, '検索_ブランド', '指名', '女性', 30, 3, 300, 0],
+      [{ $date: '2025-07-13' }, '検索_ブランド', '指名', '女性', 100, 10, 1000, 1]
+    ]
+  };
+  const fixture = { reports: [{
+    match: 'FROM gender_view',
+    rows: [
+      { 'segments.date': '2025-07-13', 'segments.device': 'MOBILE', 'campaign.name': '検索_ブランド', 'ad_group.name': '指名',
+        'ad_group_criterion.gender.type': 'FEMALE', 'metrics.impressions': 70, 'metrics.clicks': 7, 'metrics.cost_micros': '700000000', 'metrics.conversions': 1 },
+      { 'segments.date': '2025-07-13', 'segments.device': 'DESKTOP', 'campaign.name': '検索_ブランド', 'ad_group.name': '指名',
+        'ad_group_criterion.gender.type': 'FEMALE', 'metrics.impressions': 30, 'metrics.clicks': 3, 'metrics.cost_micros': '300000000', 'metrics.conversions': 0 }
+    ]
+  }], spreadsheets: {} };
+  fixture.spreadsheets[URL] = spreadsheet;
+  return fixture;
+}
+
+test('SEGMENTS に device を指定すると、日付の右隣にデバイスの列を追加し、分割前の行を置き換える', () => {
+  const harness = loadScripts(FILES, { fixture: buildFixture('device'), constants: { SPREADSHEET_URL: URL } });
+  harness.call('main');
+
+  assert.ok(harness.queries[0].indexOf('segments.device') !== -1, harness.queries[0]);
+  const rows = harness.sheetValues(URL)['性別データ'];
+  assert.deepStrictEqual(rows[0], ['日付', 'デバイス'].concat(HEADERS.slice(1)));
+  assert.deepStrictEqual(rows.slice(1).map(row => [toDay(row[0]), row[1], row[4], row[5]]), [
+    ['2025-07-12', '', '女性', 30],
+    ['2025-07-13', 'スマートフォン', '女性', 70],
+    ['2025-07-13', 'コンピュータ', '女性', 30]
+  ]);
+});
+
+test('対応していない分割を指定すると、取得せずに終了する', () => {
+  const harness = loadScripts(FILES, { fixture: buildFixture('hour'), constants: { SPREADSHEET_URL: URL } });
+  harness.call('main');
+
+  assert.strictEqual(harness.queries.length, 0);
+  assert.ok(harness.logs.some(line => line.indexOf('SEGMENTS: このデータでは「hour」で分割できません') !== -1), harness.logs.join('\n'));
+});
+
+test('ENUM_OUTPUT を指定しないときは、既存の行と同じ表記（Enum値）で書き足す', () => {
+  const fixture = buildFixture('');
+  fixture.spreadsheets[URL]['性別データ'] = [
+    HEADERS,
+    [{ $date: '2025-07-12' }, '検索_ブランド', '指名', 'FEMALE', 30, 3, 300, 0]
+  ];
+  const harness = loadScripts(FILES, { fixture: fixture, constants: { SPREADSHEET_URL: URL } });
+  harness.call('main');
+
+  const rows = harness.sheetValues(URL)['性別データ'];
+  assert.deepStrictEqual(rows.slice(1).map(row => [toDay(row[0]), row[3]]), [
+    ['2025-07-12', 'FEMALE'],
+    ['2025-07-13', 'FEMALE'],
+    ['2025-07-13', 'FEMALE']
+  ]);
+  assert.ok(!harness.logs.some(line => line.indexOf('区分値の表記が混在しています') !== -1), harness.logs.join('\n'));
+});
+
+test('既存の行と違う表記を指定して書き足すと、混在している列を警告する', () => {
+  const fixture = buildFixture('');
+  fixture.spreadsheets[URL]['設定'].push(['', 'ENUM_OUTPUT', 'label', '']);
+  fixture.spreadsheets[URL]['性別データ'] = [
+    HEADERS,
+    [{ $date: '2025-07-12' }, '検索_ブランド', '指名', 'FEMALE', 30, 3, 300, 0]
+  ];
+  const harness = loadScripts(FILES, { fixture: fixture, constants: { SPREADSHEET_URL: URL } });
+  harness.call('main');
+
+  assert.deepStrictEqual(harness.sheetValues(URL)['性別データ'].slice(1).map(row => row[3]), ['FEMALE', '女性', '女性']);
+  assert.ok(harness.logs.some(line => line.indexOf('「性別」列（日本語の表記 2件・Enum値 1件・その他の表記 0件）') !== -1),
+    harness.logs.join('\n'));
+});
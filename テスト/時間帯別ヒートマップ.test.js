@@ -0,0 +1,46 @@
+'use strict';
+/**
+ * 【時間帯×曜日のヒートマップ】時間帯別データから、曜日×時間帯のマスと赤字の判定を作る処理を確認する
+ */
+const test = require('node:test');
+const assert = require('node:assert');
+const { loadScripts } = require('./ハーネス.js');
+
+const FILES = [
+  'Google広告用レポート/Config.go',
+  'Google広告用レポート/HTMLレポート生成（検索広告）.go'
+];
+const HEADERS = ['日付', '時間帯', '曜日', 'キャンペーンID', 'キャンペーン名', '広告チャネルタイプ', 'デバイス', '表示回数', 'クリック数', '費用', 'コンバージョン数'];
+
+test('検索広告の前月分を曜日×時間帯に集計し、基準CPAを超えたマスを赤字にする', () => {
+  const harness = loadScripts(FILES, {});
+  const rows = [
+    [harness.date('2025-06-02'), 9, '月', '1', '検索_ブランド', 'SEARCH', 'MOBILE', 10, 5, 5000, 1],
+    [harness.date('2025-06-02'), 10, '月', '1', '検索_ブランド', '検索', 'MOBILE', 10, 5, 25000, 0],
+    [harness.date('2025-06-08'), 23, '日', '1', '検索_ブランド', 'SEARCH', 'DESKTOP', 10, 5, 3000, 0],
+    [harness.date('2025-06-03'), 9, '火', '2', 'ディスプレイ', 'DISPLAY', 'MOBILE', 10, 5, 99999, 0],
+    [harness.date('2025-07-01'), 9, '火', '1', '検索_ブランド', 'SEARCH', 'MOBILE', 10, 5, 99999, 0]
+  ];
+  const heatmap = harness.call('aggregateHourlyHeatmap', rows, HEADERS, harness.date('2025-06-01'), harness.date('2025-06-30'), 10000);
+
+  const pick = cell => [cell.cost, cell.cv, cell.unprofitable];
+  assert.deepStrictEqual(pick(heatmap.cells[0][9]), [5000, 1, false]);   // 月 9時: CPA 5,000円
+  assert.deepStrictEqual(pick(heatmap.cells[0][10]), [25000, 0, true]);  // 月 10時: CVなしで基準以上の費用
+  assert.deepStrictEqual(pick(heatmap.cells[6][23]), [3000, 0, false]);  // 日 23時: CVなしだが基準未満
+  assert.deepStrictEqual(pick(heatmap.cells[1][9]), [0, 0, false]);      // 火 9時: ディスプレイと期間外は対象外
+  assert.strictEqual(heatmap.unprofitableCount, 1);
+  assert.strictEqual(heatmap.unprofitableCost, 25000);
+
+  const html = harness.call('buildHeatmapHtml', heatmap);
+  assert.ok(html.indexOf('title="月曜 10時 / 費用 ¥25,000 / クリック 5 / CV 0">0CV</td>') !== -1);
+  assert.ok(html.indexOf('bg-red-500') !== -1);
+});
+
+test('基準CPAがない（前月のCVが0件）ときは赤字の判定をしない', () => {
+  const harness = loadScripts(FILES, {});
+  const rows = [[harness.date('2025-06-02'), 10, '月', '1', '検索_ブランド', 'SEARCH', 'MOBILE', 10, 5, 25000, 0]];
+  const heatmap = harness.call('aggregateHourlyHeatmap', rows, HEADERS, harness.date('2025-06-01'), harness.date('2025-06-30'), 0);
+
+  assert.strictEqual(heatmap.unprofitableCount, 0);
+  assert.ok(harness.call('buildHeatmapHtml', heatmap).indexOf('赤字の判定は行っていません') !== -1);
+});
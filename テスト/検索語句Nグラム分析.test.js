@@ -0,0 +1,69 @@
+'use strict';
+/**
+ * 【検索語句Nグラム分析】日本語の検索語句の単語分け、Nグラムごとの無駄な費用、除外キーワード候補の選び方を確認する
+ */
+const test = require('node:test');
+const assert = require('node:assert');
+const { loadScripts } = require('./ハーネス.js');
+
+const FILES = [
+  'Google広告スクリプト/検索語句Nグラム分析.go',
+  'Google広告スクリプト/共通/同期処理.go',
+  'Google広告スクリプト/共通/スキーマ.go',
+  'Google広告スクリプト/共通/実行履歴.go',
+  'Google広告スクリプト/共通/設定.go',
+  'Google広告スクリプト/共通/MCC実行.go',
+  'Google広告スクリプト/共通/列挙値.go',
+  'Google広告スクリプト/共通/セグメント.go'
+];
+const URL = 'https://docs.google.com/spreadsheets/d/test-search-terms';
+const HEADERS = ['日付', '取得元', 'キャンペーンID', 'キャンペーン名', '広告グループID', '広告グループ名', '検索語句', 'マッチタイプ', '追加・除外',
+  '表示回数', 'クリック数', '費用', 'コンバージョン数', 'コンバージョン価値'];
+
+function searchTermRow(date, campaign, term, clicks, cost, conversions, status) {
+  return [{ $date: date }, '検索語句', '1', campaign, '10', '一般', term, '部分一致', status || 'なし', clicks * 10, clicks, cost, conversions, 0];
+}
+
+test('日本語の検索語句を、文字の種類の切れ目と助詞で単語に分ける', () => {
+  const harness = loadScripts(FILES, {});
+  const tokenize = text => Array.from(harness.call('tokenizeSearchTerm', harness.call('normalizeSearchTerm', text)));
+
+  assert.deepStrictEqual(tokenize('東京の脱毛サロン'), ['東京', '脱毛', 'サロン']);
+  assert.deepStrictEqual(tokenize('脱毛　安い　ＩＰＨＯＮＥ１５'), ['脱毛', '安い', 'iphone15']);
+  assert.deepStrictEqual(tokenize('ﾀﾞｲｴｯﾄ・サプリ 口コミ'), ['ダイエット', 'サプリ', '口コミ']);
+  assert.deepStrictEqual(tokenize('おすすめ 求人'), ['おすすめ', '求人']);
+});
+
+test('コンバージョンのない組み合わせを無駄な費用の順に並べ、除外キーワード候補を書き出す', () => {
+  const fixture = { spreadsheets: {} };
+  fixture.spreadsheets[URL] = {
+    '検索語句データ': [
+      HEADERS,
+      searchTermRow('2025-07-01', '検索_一般', '脱毛 求人', 6, 4000, 0),
+      searchTermRow('2025-07-02', '検索_一般', '脱毛サロン 求人 東京', 3, 2500, 0),
+      searchTermRow('2025-07-03', '検索_一般', '脱毛サロン 東京', 10, 9000, 2),
+      searchTermRow('2025-07-03', '検索_一般', '脱毛 無料', 8, 6000, 0),
+      searchTermRow('2025-07-04', '検索_ブランド', 'ブランド 無料', 8, 5000, 0),
+      searchTermRow('2025-07-05', '検索_一般', '脱毛 痛い', 20, 8000, 0, '除外済み'),
+      searchTermRow('2025-05-01', '検索_一般', '脱毛 格安', 20, 8000, 0) // 集計期間外
+    ]
+  };
+  const harness = loadScripts(FILES, { fixture: fixture, constants: { SPREADSHEET_URL: URL, PROTECTED_WORDS: ['ブランド'] } });
+  harness.call('main');
+
+  const sheets = harness.sheetValues(URL);
+  const ngrams = sheets['Nグラム集計'];
+  assert.deepStrictEqual(ngrams[0].slice(0, 3), ['Nグラム', '語数', '検索語句数']);
+  assert.deepStrictEqual(ngrams[1], ['脱毛', 1, 4, 270, 27, 21500, 2, 10750, 12500, '脱毛サロン 東京、脱毛 無料、脱毛 求人']);
+  assert.ok(!ngrams.some(row => row[0] === '痛い' || row[0] === '格安'));
+
+  const candidates = sheets['除外キーワード候補'];
+  assert.deepStrictEqual(candidates.map(row => row.slice(0, 6)), [
+    ['除外キーワード', '推奨マッチタイプ', '語数', '無駄な費用', 'クリック数', '検索語句数'],
+    // 「無料」は保護した「ブランド 無料」を含めて集計し、候補の「求人」を含む「脱毛 求人」などは除く
+    ['無料', '部分一致', 1, 11000, 16, 2],
+    ['求人', '部分一致', 1, 6500, 9, 2]
+  ]);
+  assert.strictEqual(candidates[1][6], '検索_一般、検索_ブランド');
+  assert.strictEqual(candidates[1][8], '2025-06-15〜2025-07-14');
+});
@@ -0,0 +1,162 @@
+'use strict';
+/**
+ * 【除外キーワード自動追加】ルールの当てはめと、preview（追加案の書き出しのみ）・apply（追加と変更履歴）の違い、
+ * 検索語句の読み込み元（検索語句データのシート・検索語句レポート）を確認する
+ */
+const test = require('node:test');
+const assert = require('node:assert');
+const { loadScripts } = require('./ハーネス.js');
+
+const FILES = [
+  'Google広告スクリプト/除外キーワード自動追加.go',
+  'Google広告スクリプト/共通/同期処理.go',
+  'Google広告スクリプト/共通/スキーマ.go',
+  'Google広告スクリプト/共通/実行履歴.go',
+  'Google広告スクリプト/共通/設定.go',
+  'Google広告スクリプト/共通/MCC実行.go',
+  'Google広告スクリプト/共通/列挙値.go',
+  'Google広告スクリプト/共通/セグメント.go'
+];
+const URL = 'https://docs.google.com/spreadsheets/d/test-negatives';
+const RULE_HEADERS = ['有効', 'ルール名', '対象キャンペーン', '期間（日）', '検索語句に含む', '費用（円）以上', 'クリック数以上', 'コンバージョン数以下', '除外する語句', 'マッチタイプ', '追加先', 'メモ'];
+
+function term(campaignId, campaignName, text, clicks, cost, conversions, status, date) {
+  return { 'segments.date': date || '2025-07-10', 'campaign.id': campaignId, 'campaign.name': campaignName, 'search_term_view.search_term': text, 'search_term_view.status': status || 'NONE',
+    'metrics.clicks': clicks, 'metrics.cost_micros': String(cost * 1000000), 'metrics.conversions': conversions };
+}
+
+function buildFixture(rules) {
+  const fixture = {
+    reports: [{ match: 'FROM search_term_view', rows: [
+      term('1', '検索_一般', '脱毛 安い', 20, 6000, 0),
+      term('1', '検索_一般', '脱毛 安い', 5, 1000, 0),       // 別の広告グループの同じ検索語句
+      term('1', '検索_一般', '脱毛 人気', 30, 9000, 2),
+      term('1', '検索_一般', '脱毛 求人', 3, 800, 0),
+      term('2', '検索_ブランド', 'ブランド アルバイト', 2, 500, 0),
+      term('1', '検索_一般', '脱毛 痛い', 40, 12000, 0, 'EXCLUDED')
+    ] }],
+    campaigns: [
+      { id: '1', name: '検索_一般', negativeKeywords: [] },
+      { id: '2', name: '検索_ブランド', negativeKeywords: [] }
+    ],
+    negativeKeywordLists: [{ id: '9', name: '共通除外', negativeKeywords: ['"求人"'] }],
+    spreadsheets: {}
+  };
+  fixture.spreadsheets[URL] = { '除外ルール': [RULE_HEADERS].concat(rules) };
+  return fixture;
+}
+
+const RULES = [
+  [true, '費用がかさんだ語句', '', 30, '', 5000, '', 0, '', '', '', ''],
+  [true, '求人', '', '', '求人、バイト', '', '', '', '', '', '共通除外', ''],
+  [false, '無効なルール', '', '', 'ブランド', '', '', '', '', '', '', '']
+];
+
+test('preview では追加案を書き出すだけで、除外キーワードは追加しない', () => {
+  const harness = loadScripts(FILES, { fixture: buildFixture(RULES), constants: { SPREADSHEET_URL: URL } });
+  harness.call('main');
+
+  assert.ok(harness.queries[0].indexOf("BETWEEN '2025-06-15' AND '2025-07-14'") !== -1, harness.queries[0]);
+  const sheets = harness.sheetValues(URL);
+  assert.deepStrictEqual(sheets['除外キーワードの追加案'].slice(1).map(row => [row[1], row[2], row[3], row[5], row[7], row[8], row[11]]), [
+    ['費用がかさんだ語句', '検索_一般', '[脱毛 安い]', 'キャンペーン', 1, 7000, '追加予定'],
+    ['求人', '検索_一般', '"求人"', '共通除外', 1, 800, '登録済み'],
+    ['求人', '検索_ブランド', '"バイト"', '共通除外', 1, 500, '追加予定']
+  ]);
+  assert.strictEqual(harness.mutations.length, 0);
+  assert.ok(!sheets['除外キーワードの変更履歴']);
+});
+
+test('apply では除外キーワードを追加し、変更履歴に1件ずつ記録する', () => {
+  const harness = loadScripts(FILES, { fixture: buildFixture(RULES), constants: { SPREADSHEET_URL: URL, RUN_MODE: 'apply' } });
+  harness.call('main');
+
+  assert.deepStrictEqual(harness.mutations.map(mutation => [mutation.type, mutation.name, mutation.negativeKeyword]), [
+    ['campaign', '検索_一般', '[脱毛 安い]'],
+    ['negativeKeywordList', '共通除外', '"バイト"']
+  ]);
+  const log = harness.sheetValues(URL)['除外キーワードの変更履歴'];
+  assert.strictEqual(log.length, 3);
+  assert.deepStrictEqual(log.slice(1).map(row => [row[3], row[11]]), [['[脱毛 安い]', '追加済み'], ['"バイト"', '追加済み']]);
+});
+
+test('ルールに誤りがあれば、どのルールも当てはめずに終了する', () => {
+  const rules = RULES.concat([[true, 'CVだけ', '', '', '', '', '', 0, '', '', '', ''], [true, '不明な一致', '', '', '無料', '', '', '', '', '完全', '', '']]);
+  const harness = loadScripts(FILES, { fixture: buildFixture(rules), constants: { SPREADSHEET_URL: URL, RUN_MODE: 'apply' } });
+  harness.call('main');
+
+  assert.strictEqual(harness.queries.length, 0);
+  assert.strictEqual(harness.mutations.length, 0);
+  const error = harness.logs.join('\n');
+  assert.ok(error.indexOf('5行目（CVだけ）') !== -1, error);
+  assert.ok(error.indexOf('6行目（不明な一致）: 「マッチタイプ」') !== -1, error);
+});
+
+test('「除外ルール」シートがなければ、記入例を書き込んで終了する', () => {
+  const fixture = buildFixture([]);
+  delete fixture.spreadsheets[URL]['除外ルール'];
+  const harness = loadScripts(FILES, { fixture: fixture, constants: { SPREADSHEET_URL: URL } });
+  harness.call('main');
+
+  const rules = harness.sheetValues(URL)['除外ルール'];
+  assert.deepStrictEqual(rules[0], RULE_HEADERS);
+  assert.deepStrictEqual(rules.slice(1).map(row => row[0]), [false, false]);
+  assert.strictEqual(harness.queries.length, 0);
+});
+
+test('期間の違うルールがあっても検索語句レポートは1回だけ取得し、使えない記号を含む語句は preview でもエラーにする', () => {
+  const fixture = buildFixture([
+    [true, '費用がかさんだ語句', '', 30, '', 5000, '', 0, '', '', '', ''],
+    [true, '直近7日', '', 7, '', 2000, '', 0, '', '', '', '']
+  ]);
+  fixture.reports[0].rows.push(term('1', '検索_一般', '脱毛 50%off', 10, 8000, 0, 'NONE', '2025-07-12'));
+  fixture.reports[0].rows.push(term('1', '検索_一般', '脱毛 口コミ', 10, 3000, 0, 'NONE', '2025-07-01'));
+  const harness = loadScripts(FILES, { fixture: fixture, constants: { SPREADSHEET_URL: URL } });
+  harness.call('main');
+
+  assert.strictEqual(harness.queries.length, 1);
+  assert.ok(harness.queries[0].indexOf("BETWEEN '2025-06-15' AND '2025-07-14'") !== -1, harness.queries[0]);
+  // 7月1日の「脱毛 口コミ」は、7日間のルールの期間外
+  assert.deepStrictEqual(harness.sheetValues(URL)['除外キーワードの追加案'].slice(1).map(row => [row[1], row[3], row[11]]), [
+    ['費用がかさんだ語句', '[脱毛 50%off]', 'エラー: 除外キーワードに使えない記号（%）を含むため追加できません'],
+    ['費用がかさんだ語句', '[脱毛 安い]', '追加予定']
+  ]);
+});
+
+test('「検索語句データ」シートに期間内のすべての日が記録されていれば、検索語句レポートを取得せずにシートを使う', () => {
+  const fixture = buildFixture(RULES);
+  const headers = ['日付', '取得元', 'キャンペーンID', 'キャンペーン名', '広告グループID', '広告グループ名', '検索語句', 'マッチタイプ', '追加・除外',
+    '表示回数', 'クリック数', '費用', 'コンバージョン数', 'コンバージョン価値'];
+  fixture.spreadsheets[URL]['検索語句データ'] = [
+    headers,
+    [{ $date: '2025-06-01' }, '検索語句', '1', '検索_一般', '11', '一般', '脱毛 安い', '完全一致', 'なし', 100, 20, 6000, 0, 0],
+    [{ $date: '2025-07-10' }, '検索語句', '1', '検索_一般', '11', '一般', '脱毛 安い', '完全一致', 'なし', 100, 20, 6000, 0, 0],
+    [{ $date: '2025-07-11' }, '検索語句', '2', '検索_ブランド', '21', 'ブランド', 'ブランド バイト', 'フレーズ一致', 'なし', 10, 2, 500, 0, 0],
+    [{ $date: '2025-07-12' }, 'P-MAX', '3', 'PMAX_全体', '', '', '求人', '', '', 50, 5, '', 0, 0]
+  ];
+  fixture.spreadsheets[URL]['実行履歴'] = [
+    ['記録日時', 'シート名', '開始日', '終了日', '件数', 'ステータス', 'メッセージ'],
+    ['', '検索語句データ', '2025-06-01', '2025-07-14', 4, '成功', '']
+  ];
+  const harness = loadScripts(FILES, { fixture: fixture, constants: { SPREADSHEET_URL: URL } });
+  harness.call('main');
+
+  assert.strictEqual(harness.queries.length, 0);
+  assert.deepStrictEqual(harness.sheetValues(URL)['除外キーワードの追加案'].slice(1).map(row => [row[1], row[2], row[3], row[8], row[11]]), [
+    ['費用がかさんだ語句', '検索_一般', '[脱毛 安い]', 6000, '追加予定'],
+    ['求人', '検索_ブランド', '"バイト"', 500, '追加予定']
+  ]);
+});
+
+test('「検索語句データ」シートに最新の日が記録されていなければ、検索語句レポートから取得する', () => {
+  const fixture = buildFixture(RULES);
+  fixture.spreadsheets[URL]['検索語句データ'] = [
+    ['日付', '取得元', 'キャンペーンID', 'キャンペーン名', '検索語句', '追加・除外', 'クリック数', '費用', 'コンバージョン数'],
+    [{ $date: '2025-06-01' }, '検索語句', '1', '検索_一般', '脱毛 安い', 'なし', 20, 6000, 0]
+  ];
+  const harness = loadScripts(FILES, { fixture: fixture, constants: { SPREADSHEET_URL: URL } });
+  harness.call('main');
+
+  assert.strictEqual(harness.queries.length, 1);
+  assert.ok(harness.logs.some(line => line.indexOf('2025-07-14 までの検索語句が記録されていない') !== -1), harness.logs.join('\n'));
+});